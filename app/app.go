@@ -2,9 +2,10 @@ package app
 
 import (
 	"context"
+	"database/sql"
 	"flag"
-	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/ungame/command-time-track/app/broker"
 	"github.com/ungame/command-time-track/app/cors"
 	"github.com/ungame/command-time-track/app/exit"
 	"github.com/ungame/command-time-track/app/handlers"
@@ -13,47 +14,283 @@ import (
 	"github.com/ungame/command-time-track/app/middlewares"
 	"github.com/ungame/command-time-track/app/observer"
 	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/rpc"
 	"github.com/ungame/command-time-track/app/service"
+	"github.com/ungame/command-time-track/app/webhook"
 	"github.com/ungame/command-time-track/db"
+	"google.golang.org/grpc"
 	"log"
+	"net"
 	"net/http"
+	"strings"
 	"time"
 )
 
-var port int
+const (
+	StoreMySQL  = "mysql"
+	StoreSQLite = "sqlite"
+	StoreMemory = "memory"
+
+	// purgeInterval is how often the activities in the trash for longer than the retention are purged.
+	purgeInterval = time.Hour
+	// deliverInterval is how often the activity events queued are dispatched to the webhooks and the pending
+	// deliveries due attempted.
+	deliverInterval = time.Second
+)
+
+var (
+	port           int
+	grpcPort       int
+	store          string
+	sqliteFile     string
+	migrate        bool
+	timeZone       string
+	tagLabel       bool
+	auth           bool
+	authOpen       string
+	origins        string
+	retention      time.Duration
+	bufferSize     int
+	heartbeat      time.Duration
+	webhookTimeout time.Duration
+	webhookPrivate bool
+)
 
 func init() {
 	flag.IntVar(&port, "p", 15555, "set port")
+	flag.IntVar(&grpcPort, "grpc-port", 15556, "set port of the gRPC API, 0 disables it")
+	flag.StringVar(&store, "store", StoreMySQL, "set storage backend: mysql, sqlite or memory")
+	flag.StringVar(&sqliteFile, "sqlite-file", db.DefaultSQLiteFile, "set sqlite database file")
+	flag.BoolVar(&migrate, "migrate", true, "apply pending database migrations on startup")
+	flag.StringVar(&timeZone, "tz", "UTC", "set default time zone for reports and imports")
+	flag.BoolVar(&tagLabel, "metrics-tag-label", false, "add the tag label to the activities counter metric")
+	flag.BoolVar(&auth, "auth", false, "require a personal api token on every request, without it requests act as the default user without admin role")
+	flag.StringVar(&authOpen, "auth-open", "/metrics,/health,/openapi.json,/docs,/docs/", "comma separated paths left open when -auth is set, the ones ending with a slash leave the paths under them open")
+	flag.StringVar(&origins, "cors-origins", "", "comma separated origins allowed to make cross origin requests, * allows every origin, none by default")
+	flag.IntVar(&bufferSize, "stream-buffer", broker.DefaultBufferSize, "set number of activity events kept to resume streams")
+	flag.DurationVar(&heartbeat, "stream-heartbeat", handlers.DefaultHeartbeat, "set interval of heartbeats on idle streams")
+	flag.DurationVar(&webhookTimeout, "webhook-timeout", webhook.DefaultTimeout, "set time webhooks have to answer a delivery")
+	flag.BoolVar(&webhookPrivate, "webhook-private", false, "allow webhooks to loopback, link-local and private addresses")
+	flag.DurationVar(&retention, "trash-retention", time.Hour*24*30, "purge deleted activities after this long in the trash, 0 keeps them")
 	flag.Parse()
 }
 
-func Run() {
-	conn := db.New()
+// splitList splits a comma separated flag, dropping empty values.
+func splitList(s string) []string {
+	values := make([]string, 0)
+	for _, value := range strings.Split(s, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
 
-	closerGroup := ioext.NewCloserGroup(func() { ioext.Close(conn) })
+func openStore() *sql.DB {
+	switch store {
+	case StoreMySQL:
+		return db.New()
+	case StoreSQLite:
+		return db.NewSQLite(sqliteFile)
+	default:
+		log.Panicln("unknown storage backend:", store)
+		return nil
+	}
+}
 
-	exit.OnExit(closerGroup.Close)
+func observerOptions() []observer.Option {
+	if tagLabel {
+		return []observer.Option{observer.WithTagLabel()}
+	}
+	return nil
+}
+
+func webhookClient() *webhook.Client {
+	if webhookPrivate {
+		return webhook.NewClient(webhookTimeout, webhook.AllowPrivate())
+	}
+	return webhook.NewClient(webhookTimeout)
+}
+
+type repositories struct {
+	transactor repository.Transactor
+	activities repository.ActivitiesRepository
+	clients    repository.ClientsRepository
+	projects   repository.ProjectsRepository
+	invoices   repository.InvoicesRepository
+	users      repository.UsersRepository
+	tokens     repository.TokensRepository
+	teams      repository.TeamsRepository
+	events     repository.ActivityEventsRepository
+	webhooks   repository.WebhooksRepository
+	health     handlers.HealthCheck
+}
+
+func openRepositories(closerGroup *ioext.CloserGroup) *repositories {
+	if store == StoreMemory {
+		log.Println("Using in-memory storage, activities will be lost on exit")
+		return &repositories{
+			transactor: repository.NewMemoryTransactor(),
+			activities: repository.NewMemoryActivitiesRepository(),
+			clients:    repository.NewMemoryClientsRepository(),
+			projects:   repository.NewMemoryProjectsRepository(),
+			invoices:   repository.NewMemoryInvoicesRepository(),
+			users:      repository.NewMemoryUsersRepository(),
+			tokens:     repository.NewMemoryTokensRepository(),
+			teams:      repository.NewMemoryTeamsRepository(),
+			events:     repository.NewMemoryActivityEventsRepository(),
+			webhooks:   repository.NewMemoryWebhooksRepository(),
+		}
+	}
+
+	conn := openStore()
+	closerGroup.Add(func() { ioext.Close(conn) })
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
-	closerGroup.Add(cancel)
+	defer cancel()
 
 	if err := conn.PingContext(ctx); err != nil {
 		log.Panicln("unable to ping database:", err.Error())
 	}
 
+	if migrate {
+		db.MustMigrate(conn, store)
+	}
+
+	return &repositories{
+		transactor: repository.NewTransactor(conn),
+		activities: repository.NewActivitiesRepository(context.Background(), conn),
+		clients:    repository.NewClientsRepository(conn),
+		projects:   repository.NewProjectsRepository(conn),
+		invoices:   repository.NewInvoicesRepository(conn),
+		users:      repository.NewUsersRepository(conn),
+		tokens:     repository.NewTokensRepository(conn),
+		teams:      repository.NewTeamsRepository(conn),
+		events:     repository.NewActivityEventsRepository(conn),
+		webhooks:   repository.NewWebhooksRepository(conn),
+		health:     conn.PingContext,
+	}
+}
+
+// purgeTrash purges the activities in the trash for longer than the retention, once on start and then every interval,
+// until the context is done.
+func purgeTrash(ctx context.Context, activitiesService service.ActivitiesService, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		purged, err := activitiesService.PurgeTrash(ctx, time.Now().UTC().Add(-retention))
+		if err != nil {
+			log.Println("Unable to purge trash:", err.Error())
+		} else if purged > 0 {
+			log.Printf("Trash purged: activities=%d\n", purged)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliverWebhooks dispatches the activity events queued to the webhooks and attempts the deliveries due every
+// interval, until the context is done.
+func deliverWebhooks(ctx context.Context, webhooksService service.WebhooksService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := webhooksService.Dispatch(ctx); err != nil {
+			log.Println("Unable to dispatch webhooks:", err.Error())
+		}
+		if _, err := webhooksService.DeliverDue(ctx, time.Now().UTC()); err != nil {
+			log.Println("Unable to deliver webhooks:", err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// serveGRPC serves the gRPC API on the port, until the server is stopped.
+func serveGRPC(server *grpc.Server, port int) {
+	listener, err := net.Listen("tcp", httpext.Port(port).Addr())
+	if err != nil {
+		log.Fatalln("unable to listen gRPC:", err.Error())
+	}
+	log.Printf("Listening gRPC localhost:%d\n", port)
+	if err = server.Serve(listener); err != nil {
+		log.Println("gRPC server stopped:", err.Error())
+	}
+}
+
+func Run() {
+	closerGroup := ioext.NewCloserGroup()
+
+	exit.OnExit(closerGroup.Close)
+
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		log.Panicln("invalid time zone:", err.Error())
+	}
+
+	repos := openRepositories(closerGroup)
+	events := broker.New(bufferSize)
+	// the services record the activity events in the repository, publishing them to the streams and queuing them
+	// for the webhooks
+	repos.events = service.WebhookOutbox(events.ActivityEvents(repos.events), repos.webhooks)
+
 	var (
-		activitiesRepository = repository.NewActivitiesRepository(context.Background(), conn)
-		activitiesObserver   = observer.NewActivitiesObserver()
-		activitiesService    = service.NewActivitiesService(activitiesRepository, activitiesObserver)
-		activitiesHandler    = handlers.NewActivitiesHandler(activitiesService)
+		activitiesObserver = observer.NewActivitiesObserver(observerOptions()...)
+		activitiesService  = service.NewActivitiesService(repos.transactor, repos.activities, repos.events, repos.projects, repos.clients, activitiesObserver)
+		usersService       = service.NewUsersService(repos.users, repos.teams)
+		streamService      = service.NewStreamService(events)
+		webhooksService    = service.NewWebhooksService(repos.transactor, repos.webhooks, usersService, webhookClient())
 	)
 
-	router := mux.NewRouter().StrictSlash(true)
-	router.Use(middlewares.Logger)
-	router.Path("/metrics").Handler(promhttp.Handler())
-	activitiesHandler.Register(router)
+	// background workers stop on exit
+	ctx, cancel := context.WithCancel(context.Background())
+	closerGroup.Add(cancel)
+
+	if retention > 0 {
+		go purgeTrash(ctx, activitiesService, retention, purgeInterval)
+	}
+
+	go deliverWebhooks(ctx, webhooksService, deliverInterval)
+
+	identify := middlewares.Open(usersService)
+	if auth {
+		identify = middlewares.Auth(repos.tokens, usersService, splitList(authOpen)...)
+	} else {
+		log.Println("Authentication disabled, requests act as the default user without admin role, start with -auth to require api tokens")
+	}
+	router := handlers.NewRouter(&handlers.Services{
+		Activities: activitiesService,
+		Reports:    service.NewReportsService(repos.activities, repos.projects, repos.clients, location),
+		Import:     service.NewImportService(repos.transactor, repos.activities, repos.events, location),
+		Projects:   service.NewProjectsService(repos.clients, repos.projects, repos.activities),
+		Invoices:   service.NewInvoicesService(repos.transactor, repos.activities, repos.events, repos.invoices, repos.projects, repos.clients, location),
+		Users:      usersService,
+		Tokens:     service.NewTokensService(repos.tokens),
+		Teams:      service.NewTeamsService(repos.teams, repos.users),
+		Stream:     streamService,
+		Webhooks:   webhooksService,
+		Metrics:    promhttp.Handler(),
+		Health:     repos.health,
+		Heartbeat:  heartbeat,
+	}, middlewares.Logger, identify)
+
+	if grpcPort > 0 {
+		authenticate := rpc.OpenAuth(usersService)
+		if auth {
+			authenticate = rpc.TokenAuth(repos.tokens, usersService)
+		}
+		grpcServer := rpc.NewServer(authenticate, activitiesService, streamService)
+		closerGroup.Add(grpcServer.Stop)
+		go serveGRPC(grpcServer, grpcPort)
+	}
 
 	log.Printf("Listening http://localhost:%d\n\n", port)
 
-	log.Fatalln(http.ListenAndServe(httpext.Port(port).Addr(), cors.Apply(router)))
+	log.Fatalln(http.ListenAndServe(httpext.Port(port).Addr(), cors.Apply(router, splitList(origins)...)))
 }
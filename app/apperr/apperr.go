@@ -0,0 +1,109 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Codes of the kinds of errors, written in the error outputs.
+const (
+	CodeNotFound   = "not_found"
+	CodeConflict   = "conflict"
+	CodeValidation = "validation"
+	CodeForbidden  = "forbidden"
+	CodeInternal   = "internal"
+)
+
+// The errors of each kind, every error of a kind matches it with errors.Is.
+var (
+	ErrNotFound   = New(CodeNotFound, "not found")
+	ErrConflict   = New(CodeConflict, "conflict")
+	ErrValidation = New(CodeValidation, "invalid input")
+	ErrForbidden  = New(CodeForbidden, "forbidden")
+	ErrInternal   = New(CodeInternal, "internal error")
+)
+
+var kinds = []*Error{ErrNotFound, ErrConflict, ErrValidation, ErrForbidden, ErrInternal}
+
+// Error is a failure of the domain, the message is safe to show to the users, unlike the cause it wraps.
+// Validation errors may detail the invalid fields.
+type Error struct {
+	Code    string
+	Message string
+	Fields  map[string]string
+	Cause   error
+}
+
+func New(code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Errorf returns an error of the kind with the formatted message, prefixed by the message of the kind.
+func Errorf(code, format string, args ...any) *Error {
+	return New(code, fmt.Sprintf("%s: %s", messageOf(code), fmt.Sprintf(format, args...)))
+}
+
+// Wrap returns an error of the kind hiding the cause behind the message, errors.Is and errors.As still reach the cause.
+func Wrap(code string, cause error, message string) *Error {
+	return &Error{Code: code, Message: message, Cause: cause}
+}
+
+// Invalid returns a validation error with the message of the error, detailing the field when given.
+// The error is not wrapped, so its kind does not leak into the validation error.
+func Invalid(field string, err error) *Error {
+	invalid := Errorf(CodeValidation, "%s", err.Error())
+	if field != "" {
+		invalid.Fields = map[string]string{field: err.Error()}
+	}
+	return invalid
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is matches the error of the kind.
+func (e *Error) Is(target error) bool {
+	for _, kind := range kinds {
+		if target == kind {
+			return e.Code == kind.Code
+		}
+	}
+	return false
+}
+
+// CodeOf returns the code of the kind of the error, internal for the errors out of the domain.
+func CodeOf(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	for _, kind := range kinds {
+		if errors.Is(err, kind) {
+			return kind.Code
+		}
+	}
+	return CodeInternal
+}
+
+// FieldsOf returns the invalid fields of a validation error.
+func FieldsOf(err error) map[string]string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Fields
+	}
+	return nil
+}
+
+func messageOf(code string) string {
+	for _, kind := range kinds {
+		if kind.Code == code {
+			return kind.Message
+		}
+	}
+	return code
+}
@@ -0,0 +1,36 @@
+package apperr
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestKinds(t *testing.T) {
+	var (
+		notFound = Errorf(CodeNotFound, "activity %d", 1)
+		wrapped  = fmt.Errorf("get: %w", notFound)
+		hidden   = Wrap(CodeNotFound, sql.ErrNoRows, "not found")
+		invalid  = Invalid("started_at", errors.New("started_at is in the future"))
+	)
+
+	if notFound.Error() != "not found: activity 1" {
+		t.Errorf("unexpected message %q", notFound.Error())
+	}
+	if !errors.Is(wrapped, ErrNotFound) || errors.Is(wrapped, ErrConflict) {
+		t.Errorf("expected not found kind only, got %v", wrapped)
+	}
+	if CodeOf(wrapped) != CodeNotFound || CodeOf(errors.New("boom")) != CodeInternal {
+		t.Errorf("unexpected codes %s %s", CodeOf(wrapped), CodeOf(errors.New("boom")))
+	}
+	if !errors.Is(hidden, sql.ErrNoRows) || hidden.Error() != "not found" {
+		t.Errorf("expected cause kept behind the message, got %v", hidden)
+	}
+	if CodeOf(invalid) != CodeValidation || FieldsOf(invalid)["started_at"] != "started_at is in the future" {
+		t.Errorf("expected field details, got %v %v", invalid, FieldsOf(invalid))
+	}
+	if errors.Is(Invalid("project_id", notFound), ErrNotFound) {
+		t.Errorf("expected validation error not matching the kind of its message")
+	}
+}
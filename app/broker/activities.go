@@ -0,0 +1,79 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/types"
+	"log"
+)
+
+// Types of the activity events.
+const (
+	TypeStarted = "started"
+	TypeStopped = "stopped"
+	TypeUpdated = "updated"
+	TypeDeleted = "deleted"
+)
+
+// TypeOf returns the type of event published for the action of the history of an activity.
+func TypeOf(action string) string {
+	switch action {
+	case models.ActionCreated, models.ActionResumed:
+		return TypeStarted
+	case models.ActionStopped:
+		return TypeStopped
+	case models.ActionDeleted, models.ActionPurged:
+		return TypeDeleted
+	default:
+		return TypeUpdated
+	}
+}
+
+type publishingEventsRepository struct {
+	repository.ActivityEventsRepository
+	broker *Broker
+}
+
+// ActivityEvents returns the repository publishing the activity events it records, once their transaction commits.
+func (b *Broker) ActivityEvents(activityEventsRepository repository.ActivityEventsRepository) repository.ActivityEventsRepository {
+	return &publishingEventsRepository{ActivityEventsRepository: activityEventsRepository, broker: b}
+}
+
+func (r *publishingEventsRepository) Create(ctx context.Context, event *models.ActivityEvent) (int64, error) {
+	id, err := r.ActivityEventsRepository.Create(ctx, event)
+	if err != nil {
+		return id, err
+	}
+	repository.OnCommit(ctx, func() {
+		r.publish(event)
+	})
+	return id, nil
+}
+
+func (r *publishingEventsRepository) publish(event *models.ActivityEvent) {
+	data, err := DataOf(event)
+	if err != nil {
+		log.Println("Unable to publish activity event:", err.Error())
+		return
+	}
+	r.broker.Publish(TypeOf(event.Action), event.OwnerID, data)
+}
+
+// DataOf returns the data of the event published for an event of the history of an activity, the activity after
+// the event or before it once deleted.
+func DataOf(event *models.ActivityEvent) ([]byte, error) {
+	output := event.Out()
+	activity := output.After
+	if event.After == nil {
+		activity = output.Before
+	}
+	return json.Marshal(&types.ActivityStreamOutput{
+		ActivityID: output.ActivityID,
+		ActorID:    output.ActorID,
+		Action:     output.Action,
+		Activity:   activity,
+		CreatedAt:  output.CreatedAt,
+	})
+}
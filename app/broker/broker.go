@@ -0,0 +1,142 @@
+package broker
+
+import (
+	"sync"
+)
+
+// DefaultBufferSize is the number of events kept for the subscribers resuming a stream.
+const DefaultBufferSize = 1000
+
+// subscriberBuffer is the number of events a subscriber may lag behind before being dropped.
+const subscriberBuffer = 64
+
+// Event is a change pushed to the subscribers, its id grows with every event published.
+type Event struct {
+	ID      int64
+	Type    string
+	OwnerID int64
+	Data    []byte
+}
+
+// Broker fans the events out to the subscribers, keeping the last ones in a bounded buffer
+// so subscribers can resume after the last event they received.
+type Broker struct {
+	mutex       sync.Mutex
+	sequence    int64
+	buffer      []*Event
+	next        int
+	subscribers map[*Subscription]bool
+}
+
+func New(size int) *Broker {
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+	return &Broker{
+		buffer:      make([]*Event, 0, size),
+		subscribers: make(map[*Subscription]bool),
+	}
+}
+
+// Publish sends the event to the subscribers matching it, it never blocks: subscribers lagging behind are dropped,
+// their events channel is closed and they may resume from the buffer.
+func (b *Broker) Publish(eventType string, ownerID int64, data []byte) *Event {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.sequence++
+	event := &Event{ID: b.sequence, Type: eventType, OwnerID: ownerID, Data: data}
+
+	if len(b.buffer) < cap(b.buffer) {
+		b.buffer = append(b.buffer, event)
+	} else {
+		b.buffer[b.next] = event
+		b.next = (b.next + 1) % len(b.buffer)
+	}
+
+	for subscription := range b.subscribers {
+		if !subscription.match(event) {
+			continue
+		}
+		select {
+		case subscription.events <- event:
+		default:
+			b.unsubscribe(subscription)
+		}
+	}
+
+	return event
+}
+
+// Subscribe returns a subscription to the events matching, match nil matches every event. The events published
+// after lastID still in the buffer are part of it, when some of them were dropped already the subscription is reset.
+// Zero subscribes to the next events only.
+func (b *Broker) Subscribe(lastID int64, match func(event *Event) bool) *Subscription {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if match == nil {
+		match = func(*Event) bool { return true }
+	}
+
+	subscription := &Subscription{
+		broker: b,
+		match:  match,
+		events: make(chan *Event, subscriberBuffer),
+	}
+
+	if lastID > 0 {
+		buffered := b.buffered()
+		switch {
+		case lastID > b.sequence:
+			// ids of a previous run of the broker
+			subscription.Reset = true
+		case len(buffered) > 0 && lastID < buffered[0].ID-1:
+			subscription.Reset = true
+		}
+		for _, event := range buffered {
+			if event.ID > lastID && match(event) {
+				subscription.Missed = append(subscription.Missed, event)
+			}
+		}
+	}
+
+	b.subscribers[subscription] = true
+
+	return subscription
+}
+
+// buffered returns the buffered events, oldest first.
+func (b *Broker) buffered() []*Event {
+	events := make([]*Event, 0, len(b.buffer))
+	events = append(events, b.buffer[b.next:]...)
+	return append(events, b.buffer[:b.next]...)
+}
+
+func (b *Broker) unsubscribe(subscription *Subscription) {
+	if b.subscribers[subscription] {
+		delete(b.subscribers, subscription)
+		close(subscription.events)
+	}
+}
+
+// Subscription receives the events published after it was made, Missed holds the ones published before
+// it since the last event id given. Reset tells some events were missed for good.
+type Subscription struct {
+	Missed []*Event
+	Reset  bool
+	broker *Broker
+	match  func(event *Event) bool
+	events chan *Event
+}
+
+// Events returns the channel of the next events, closed once the subscription is closed or dropped.
+func (s *Subscription) Events() <-chan *Event {
+	return s.events
+}
+
+func (s *Subscription) Close() {
+	s.broker.mutex.Lock()
+	defer s.broker.mutex.Unlock()
+	s.broker.unsubscribe(s)
+}
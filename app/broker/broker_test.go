@@ -0,0 +1,79 @@
+package broker
+
+import (
+	"fmt"
+	"testing"
+)
+
+func eventIDs(events []*Event) []int64 {
+	ids := make([]int64, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func TestBroker(t *testing.T) {
+	b := New(3)
+
+	live := b.Subscribe(0, func(event *Event) bool { return event.OwnerID == 1 })
+	defer live.Close()
+
+	for i := 1; i <= 5; i++ {
+		b.Publish(TypeUpdated, int64(i%2), []byte(fmt.Sprint(i)))
+	}
+
+	received := make([]*Event, 0)
+	for len(live.Events()) > 0 {
+		received = append(received, <-live.Events())
+	}
+	if fmt.Sprint(eventIDs(received)) != fmt.Sprint([]int64{1, 3, 5}) {
+		t.Errorf("unexpected events received: %v", eventIDs(received))
+	}
+	if live.Reset || len(live.Missed) != 0 {
+		t.Errorf("expected new subscription without missed events, got reset=%v, missed=%v", live.Reset, eventIDs(live.Missed))
+	}
+
+	for _, test := range []struct {
+		lastID int64
+		missed []int64
+		reset  bool
+	}{
+		{lastID: 3, missed: []int64{4, 5}},
+		{lastID: 2, missed: []int64{3, 4, 5}},
+		{lastID: 1, missed: []int64{3, 4, 5}, reset: true},
+		{lastID: 5, missed: []int64{}},
+		{lastID: 9, missed: []int64{}, reset: true},
+	} {
+		resumed := b.Subscribe(test.lastID, nil)
+		if fmt.Sprint(eventIDs(resumed.Missed)) != fmt.Sprint(test.missed) || resumed.Reset != test.reset {
+			t.Errorf("unexpected resume after %d: missed=%v, reset=%v", test.lastID, eventIDs(resumed.Missed), resumed.Reset)
+		}
+		resumed.Close()
+		resumed.Close()
+	}
+}
+
+func TestBrokerDropsLaggingSubscribers(t *testing.T) {
+	b := New(DefaultBufferSize)
+
+	lagging := b.Subscribe(0, nil)
+	for i := 0; i < subscriberBuffer+1; i++ {
+		b.Publish(TypeStarted, 1, nil)
+	}
+
+	received := 0
+	for range lagging.Events() {
+		received++
+	}
+	if received != subscriberBuffer {
+		t.Errorf("expected lagging subscriber dropped after %d events, got %d", subscriberBuffer, received)
+	}
+
+	resumed := b.Subscribe(int64(received), nil)
+	defer resumed.Close()
+	if len(resumed.Missed) != 1 || resumed.Reset {
+		t.Errorf("expected dropped subscriber to resume from the buffer, got missed=%v, reset=%v", eventIDs(resumed.Missed), resumed.Reset)
+	}
+	lagging.Close()
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/types"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type ActivitiesClient interface {
+	StartActivity(ctx context.Context, input *types.StartActivityInput) (*types.StartActivityOutput, error)
+	CreateManualActivity(ctx context.Context, input *types.StartActivityInput) (*types.ActivityOutput, error)
+	StopActivity(ctx context.Context, id int64) (*types.ActivityOutput, error)
+	PauseActivity(ctx context.Context, id int64) (*types.ActivityOutput, error)
+	ResumeActivity(ctx context.Context, id int64) (*types.ActivityOutput, error)
+	UpdateActivityCategory(ctx context.Context, id int64, category string) (*types.ActivityOutput, error)
+	UpdateActivityDescription(ctx context.Context, id int64, description string) (*types.ActivityOutput, error)
+	TagActivity(ctx context.Context, id int64, tags ...string) (*types.ActivityOutput, error)
+	UntagActivity(ctx context.Context, id int64, tag string) (*types.ActivityOutput, error)
+	GetActivity(ctx context.Context, id int64) (*types.ActivityOutput, error)
+	GetActivityHistory(ctx context.Context, id int64) ([]*types.ActivityEventOutput, error)
+	ListActivities(ctx context.Context, input *types.ListActivitiesInput) (*types.ListActivitiesOutput, error)
+	SearchActivities(ctx context.Context, input *types.SearchActivitiesInput) ([]*types.ActivityOutput, error)
+	DeleteActivity(ctx context.Context, id int64) error
+	RestoreActivity(ctx context.Context, id int64) (*types.ActivityOutput, error)
+	ListTrash(ctx context.Context) ([]*types.ActivityOutput, error)
+	ImportActivities(ctx context.Context, input *types.ImportActivitiesInput, csv io.Reader) (*types.ImportActivitiesOutput, error)
+}
+
+type activitiesClient struct {
+	*client
+}
+
+func NewActivitiesClient(addr string, options ...Option) ActivitiesClient {
+	return &activitiesClient{client: newClient(addr, options...)}
+}
+
+func (c *activitiesClient) StartActivity(ctx context.Context, input *types.StartActivityInput) (*types.StartActivityOutput, error) {
+	output := new(types.StartActivityOutput)
+	err := c.do(ctx, http.MethodPost, "/activities", input, output)
+	return output, err
+}
+
+func (c *activitiesClient) CreateManualActivity(ctx context.Context, input *types.StartActivityInput) (*types.ActivityOutput, error) {
+	output := new(types.ActivityOutput)
+	err := c.do(ctx, http.MethodPost, "/activities/manual", input, output)
+	return output, err
+}
+
+func (c *activitiesClient) StopActivity(ctx context.Context, id int64) (*types.ActivityOutput, error) {
+	output := new(types.ActivityOutput)
+	err := c.do(ctx, http.MethodPut, fmt.Sprintf("/activities/%d/stop", id), &types.UpdateActivityInput{}, output)
+	return output, err
+}
+
+func (c *activitiesClient) PauseActivity(ctx context.Context, id int64) (*types.ActivityOutput, error) {
+	output := new(types.ActivityOutput)
+	err := c.do(ctx, http.MethodPut, fmt.Sprintf("/activities/%d/pause", id), nil, output)
+	return output, err
+}
+
+func (c *activitiesClient) ResumeActivity(ctx context.Context, id int64) (*types.ActivityOutput, error) {
+	output := new(types.ActivityOutput)
+	err := c.do(ctx, http.MethodPut, fmt.Sprintf("/activities/%d/resume", id), nil, output)
+	return output, err
+}
+
+func (c *activitiesClient) UpdateActivityCategory(ctx context.Context, id int64, category string) (*types.ActivityOutput, error) {
+	output := new(types.ActivityOutput)
+	err := c.do(ctx, http.MethodPut, fmt.Sprintf("/activities/%d/category", id), &types.UpdateActivityInput{Category: category}, output)
+	return output, err
+}
+
+func (c *activitiesClient) UpdateActivityDescription(ctx context.Context, id int64, description string) (*types.ActivityOutput, error) {
+	output := new(types.ActivityOutput)
+	err := c.do(ctx, http.MethodPut, fmt.Sprintf("/activities/%d/description", id), &types.UpdateActivityInput{Description: description}, output)
+	return output, err
+}
+
+func (c *activitiesClient) TagActivity(ctx context.Context, id int64, tags ...string) (*types.ActivityOutput, error) {
+	output := new(types.ActivityOutput)
+	err := c.do(ctx, http.MethodPost, fmt.Sprintf("/activities/%d/tags", id), &types.TagActivityInput{Tags: tags}, output)
+	return output, err
+}
+
+func (c *activitiesClient) UntagActivity(ctx context.Context, id int64, tag string) (*types.ActivityOutput, error) {
+	output := new(types.ActivityOutput)
+	err := c.do(ctx, http.MethodDelete, fmt.Sprintf("/activities/%d/tags/%s", id, url.PathEscape(tag)), nil, output)
+	return output, err
+}
+
+func (c *activitiesClient) GetActivity(ctx context.Context, id int64) (*types.ActivityOutput, error) {
+	output := new(types.ActivityOutput)
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/activities/%d", id), nil, output)
+	return output, err
+}
+
+func (c *activitiesClient) GetActivityHistory(ctx context.Context, id int64) ([]*types.ActivityEventOutput, error) {
+	output := make([]*types.ActivityEventOutput, 0)
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/activities/%d/history", id), nil, &output)
+	return output, err
+}
+
+func (c *activitiesClient) ListActivities(ctx context.Context, input *types.ListActivitiesInput) (*types.ListActivitiesOutput, error) {
+	output := &types.ListActivitiesOutput{Activities: make([]*types.ActivityOutput, 0)}
+	header, err := c.send(ctx, http.MethodGet, "/activities?"+listActivitiesQuery(input).Encode(), nil, &output.Activities)
+	if err != nil {
+		return output, err
+	}
+	output.NextCursor = header.Get(httpext.HeaderNextCursor)
+	return output, nil
+}
+
+func listActivitiesQuery(input *types.ListActivitiesInput) url.Values {
+	query := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	set("from", input.From)
+	set("to", input.To)
+	set("category", input.Category)
+	set("status", input.Status)
+	for _, tag := range input.Tags {
+		query.Add("tag", tag)
+	}
+	set("sort", input.Sort)
+	set("order", input.Order)
+	set("cursor", input.Cursor)
+	if input.Limit > 0 {
+		query.Set("limit", strconv.Itoa(input.Limit))
+	}
+	if input.UserID > 0 {
+		query.Set("user_id", strconv.FormatInt(input.UserID, 10))
+	}
+	return query
+}
+
+func (c *activitiesClient) SearchActivities(ctx context.Context, input *types.SearchActivitiesInput) ([]*types.ActivityOutput, error) {
+	query := url.Values{"term": {input.Term}}
+	for _, tag := range input.Tags {
+		query.Add("tag", tag)
+	}
+	output := make([]*types.ActivityOutput, 0)
+	err := c.do(ctx, http.MethodGet, "/activities/_/search?"+query.Encode(), nil, &output)
+	return output, err
+}
+
+func (c *activitiesClient) DeleteActivity(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/activities/%d", id), nil, nil)
+}
+
+func (c *activitiesClient) RestoreActivity(ctx context.Context, id int64) (*types.ActivityOutput, error) {
+	output := new(types.ActivityOutput)
+	err := c.do(ctx, http.MethodPost, fmt.Sprintf("/activities/%d/restore", id), nil, output)
+	return output, err
+}
+
+func (c *activitiesClient) ListTrash(ctx context.Context) ([]*types.ActivityOutput, error) {
+	output := make([]*types.ActivityOutput, 0)
+	err := c.do(ctx, http.MethodGet, "/activities/trash", nil, &output)
+	return output, err
+}
+
+func (c *activitiesClient) ImportActivities(ctx context.Context, input *types.ImportActivitiesInput, csv io.Reader) (*types.ImportActivitiesOutput, error) {
+	query := url.Values{}
+	if input.TimeZone != "" {
+		query.Set("tz", input.TimeZone)
+	}
+	if input.DryRun {
+		query.Set("dry_run", "true")
+	}
+	if input.Resolve != "" {
+		query.Set("resolve", input.Resolve)
+	}
+	if input.DateFormat != "" {
+		query.Set("date_format", input.DateFormat)
+	}
+	output := new(types.ImportActivitiesOutput)
+	_, err := c.request(ctx, http.MethodPost, "/activities/import?"+query.Encode(), httpext.MimeCsv, csv, output)
+	return output, err
+}
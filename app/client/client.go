@@ -0,0 +1,110 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/ioext"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const DefaultAddr = "http://localhost:15555"
+
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.Status, http.StatusText(e.Status), e.Message)
+}
+
+// Option configures a client.
+type Option func(*client)
+
+// WithToken authenticates the requests with a personal api token.
+func WithToken(token string) Option {
+	return func(c *client) {
+		c.token = token
+	}
+}
+
+type client struct {
+	addr  string
+	token string
+	http  *http.Client
+}
+
+func newClient(addr string, options ...Option) *client {
+	c := &client{
+		addr: strings.TrimSuffix(addr, "/"),
+		http: &http.Client{Timeout: time.Second * 30},
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+func (c *client) do(ctx context.Context, method, path string, in, out any) error {
+	_, err := c.send(ctx, method, path, in, out)
+	return err
+}
+
+func (c *client) send(ctx context.Context, method, path string, in, out any) (http.Header, error) {
+	var (
+		body        io.Reader
+		contentType string
+	)
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+		contentType = httpext.MimeJson
+	}
+	return c.request(ctx, method, path, contentType, body, out)
+}
+
+func (c *client) request(ctx context.Context, method, path, contentType string, body io.Reader, out any) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.addr+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set(httpext.HeaderContentType, contentType)
+	}
+	if c.token != "" {
+		req.Header.Set(httpext.HeaderAuthorization, "Bearer "+c.token)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer ioext.Close(res.Body)
+
+	if res.StatusCode >= http.StatusBadRequest {
+		errOut := new(httpext.ErrorOutput)
+		if err = json.NewDecoder(res.Body).Decode(errOut); err != nil {
+			errOut.Message = http.StatusText(res.StatusCode)
+		}
+		if errOut.Message == "" {
+			errOut.Message = errOut.Err
+		}
+		return res.Header, &Error{Status: res.StatusCode, Code: errOut.Code, Message: errOut.Message}
+	}
+
+	if out == nil || res.StatusCode == http.StatusNoContent {
+		return res.Header, nil
+	}
+
+	return res.Header, json.NewDecoder(res.Body).Decode(out)
+}
@@ -0,0 +1,38 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"github.com/ungame/command-time-track/app/types"
+	"net/http"
+)
+
+type TokensClient interface {
+	CreateToken(ctx context.Context, name string) (*types.CreateTokenOutput, error)
+	ListTokens(ctx context.Context) ([]*types.TokenOutput, error)
+	RevokeToken(ctx context.Context, id int64) error
+}
+
+type tokensClient struct {
+	*client
+}
+
+func NewTokensClient(addr string, options ...Option) TokensClient {
+	return &tokensClient{client: newClient(addr, options...)}
+}
+
+func (c *tokensClient) CreateToken(ctx context.Context, name string) (*types.CreateTokenOutput, error) {
+	output := new(types.CreateTokenOutput)
+	err := c.do(ctx, http.MethodPost, "/tokens", &types.TokenInput{Name: name}, output)
+	return output, err
+}
+
+func (c *tokensClient) ListTokens(ctx context.Context) ([]*types.TokenOutput, error) {
+	output := make([]*types.TokenOutput, 0)
+	err := c.do(ctx, http.MethodGet, "/tokens", nil, &output)
+	return output, err
+}
+
+func (c *tokensClient) RevokeToken(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/tokens/%d", id), nil, nil)
+}
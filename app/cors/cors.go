@@ -3,12 +3,20 @@ package cors
 import (
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
+	"github.com/ungame/command-time-track/app/httpext"
 	"net/http"
 )
 
-func Apply(router *mux.Router) http.Handler {
+// Apply allows cross origin requests to the router from the origins, "*" allows every origin and none
+// allows no cross origin request.
+func Apply(router *mux.Router, origins ...string) http.Handler {
+	if len(origins) == 0 {
+		// the handler of gorilla allows every origin when given none
+		return router
+	}
 	methods := handlers.AllowedMethods([]string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete})
-	headers := handlers.AllowedHeaders([]string{"Content-Type", "X-Requested-with"})
-	origins := handlers.AllowedOrigins([]string{"*"})
-	return handlers.CORS(methods, headers, origins)(router)
+	headers := handlers.AllowedHeaders([]string{"Content-Type", "X-Requested-with", httpext.HeaderAuthorization})
+	allowed := handlers.AllowedOrigins(origins)
+	exposed := handlers.ExposedHeaders([]string{httpext.HeaderNextCursor})
+	return handlers.CORS(methods, headers, allowed, exposed)(router)
 }
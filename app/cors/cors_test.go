@@ -0,0 +1,40 @@
+package cors
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/ungame/command-time-track/app/httpext"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	router := mux.NewRouter()
+	router.Path("/activities").Methods(http.MethodGet).HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	call := func(handler http.Handler, origin string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/activities", nil)
+		request.Header.Set("Origin", origin)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	if allowed := call(Apply(router), "https://example.com").Header().Get("Access-Control-Allow-Origin"); allowed != "" {
+		t.Errorf("expected no origin allowed by default, got %s", allowed)
+	}
+	handler := Apply(router, "https://example.com")
+	if allowed := call(handler, "https://example.com").Header().Get("Access-Control-Allow-Origin"); allowed != "https://example.com" {
+		t.Errorf("expected origin allowed, got %q", allowed)
+	}
+	if allowed := call(handler, "https://evil.example").Header().Get("Access-Control-Allow-Origin"); allowed != "" {
+		t.Errorf("expected other origin refused, got %s", allowed)
+	}
+	// browsers hide the response headers not exposed from the scripts, the cursor of the next page included
+	if exposed := call(handler, "https://example.com").Header().Get("Access-Control-Expose-Headers"); !strings.Contains(exposed, httpext.HeaderNextCursor) {
+		t.Errorf("expected %s exposed, got %q", httpext.HeaderNextCursor, exposed)
+	}
+}
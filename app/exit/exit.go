@@ -6,7 +6,7 @@ import (
 )
 
 func OnExit(onExit ...func()) {
-	ctrlC := make(chan os.Signal)
+	ctrlC := make(chan os.Signal, 1)
 	signal.Notify(ctrlC, os.Interrupt)
 
 	go func() {
@@ -22,10 +22,21 @@ func NewActivitiesHandler(activitiesService service.ActivitiesService) Handler {
 
 func (h *activitiesHandler) Register(router *mux.Router) {
 	router.Path("/activities").HandlerFunc(h.PostStartActivity).Methods(http.MethodPost)
+	router.Path("/activities/manual").HandlerFunc(h.PostManualActivity).Methods(http.MethodPost)
 	router.Path("/activities/{id}/stop").HandlerFunc(h.PutStopActivity).Methods(http.MethodPut)
+	router.Path("/activities/{id}/pause").HandlerFunc(h.PutPauseActivity).Methods(http.MethodPut)
+	router.Path("/activities/{id}/resume").HandlerFunc(h.PutResumeActivity).Methods(http.MethodPut)
 	router.Path("/activities/{id}/category").HandlerFunc(h.PutActivityCategory).Methods(http.MethodPut)
 	router.Path("/activities/{id}/description").HandlerFunc(h.PutActivityDescription).Methods(http.MethodPut)
+	router.Path("/activities/{id}/project").HandlerFunc(h.PutActivityProject).Methods(http.MethodPut)
+	router.Path("/activities/{id}/rate").HandlerFunc(h.PutActivityRate).Methods(http.MethodPut)
+	router.Path("/activities/{id}/tags").HandlerFunc(h.PostActivityTags).Methods(http.MethodPost)
+	router.Path("/activities/{id}/tags/{tag}").HandlerFunc(h.DeleteActivityTag).Methods(http.MethodDelete)
+	router.Path("/activities/export").HandlerFunc(h.GetExportActivities).Methods(http.MethodGet)
+	router.Path("/activities/trash").HandlerFunc(h.GetTrash).Methods(http.MethodGet)
+	router.Path("/activities/{id}/restore").HandlerFunc(h.PostRestoreActivity).Methods(http.MethodPost)
 	router.Path("/activities/{id}").HandlerFunc(h.GetActivity).Methods(http.MethodGet)
+	router.Path("/activities/{id}/history").HandlerFunc(h.GetActivityHistory).Methods(http.MethodGet)
 	router.Path("/activities/_/search").HandlerFunc(h.SearchActivity).Methods(http.MethodGet)
 	router.Path("/activities").HandlerFunc(h.GetActivities).Methods(http.MethodGet)
 	router.Path("/activities/{id}").HandlerFunc(h.DeleteActivity).Methods(http.MethodDelete)
@@ -43,15 +54,42 @@ func (h *activitiesHandler) PostStartActivity(w http.ResponseWriter, r *http.Req
 		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
 		return
 	}
+	if resolve := r.URL.Query().Get("resolve"); resolve != "" {
+		input.Resolve = resolve
+	}
 	output, err := h.activitiesService.StartActivity(r.Context(), input)
 	if err != nil {
-		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		writeError(w, err)
 		return
 	}
 	w.Header().Set("Location", fmt.Sprintf("%s/%d", r.RequestURI, output.ID))
 	httpext.WriteJson(w, http.StatusCreated, output)
 }
 
+func (h *activitiesHandler) PostManualActivity(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	input := new(types.StartActivityInput)
+	err = json.Unmarshal(body, input)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	if resolve := r.URL.Query().Get("resolve"); resolve != "" {
+		input.Resolve = resolve
+	}
+	output, err := h.activitiesService.CreateManualActivity(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/activities/%d", output.ID))
+	httpext.WriteJson(w, http.StatusCreated, output)
+}
+
 func (h *activitiesHandler) PutStopActivity(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.ParseInt(vars["id"], 10, 64)
@@ -73,7 +111,37 @@ func (h *activitiesHandler) PutStopActivity(w http.ResponseWriter, r *http.Reque
 	input.ID = id
 	output, err := h.activitiesService.StopActivity(r.Context(), input)
 	if err != nil {
-		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+func (h *activitiesHandler) PutPauseActivity(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	output, err := h.activitiesService.PauseActivity(r.Context(), &types.UpdateActivityInput{ID: id})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+func (h *activitiesHandler) PutResumeActivity(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	output, err := h.activitiesService.ResumeActivity(r.Context(), &types.UpdateActivityInput{ID: id})
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 	httpext.WriteJson(w, http.StatusOK, output)
@@ -100,7 +168,7 @@ func (h *activitiesHandler) PutActivityCategory(w http.ResponseWriter, r *http.R
 	input.ID = id
 	output, err := h.activitiesService.UpdateActivityCategory(r.Context(), input)
 	if err != nil {
-		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		writeError(w, err)
 		return
 	}
 	httpext.WriteJson(w, http.StatusOK, output)
@@ -126,10 +194,106 @@ func (h *activitiesHandler) PutActivityDescription(w http.ResponseWriter, r *htt
 	}
 	input.ID = id
 	output, err := h.activitiesService.UpdateActivityDescription(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+func (h *activitiesHandler) PutActivityProject(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	input := new(types.UpdateActivityProjectInput)
+	err = json.Unmarshal(body, input)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	input.ID = id
+	output, err := h.activitiesService.UpdateActivityProject(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+func (h *activitiesHandler) PutActivityRate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	input := new(types.UpdateActivityRateInput)
+	err = json.Unmarshal(body, input)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	input.ID = id
+	output, err := h.activitiesService.UpdateActivityRate(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+func (h *activitiesHandler) PostActivityTags(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	input := new(types.TagActivityInput)
+	err = json.Unmarshal(body, input)
 	if err != nil {
 		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
 		return
 	}
+	input.ID = id
+	output, err := h.activitiesService.AddActivityTags(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+func (h *activitiesHandler) DeleteActivityTag(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	output, err := h.activitiesService.RemoveActivityTags(r.Context(), &types.TagActivityInput{ID: id, Tags: []string{vars["tag"]}})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
 	httpext.WriteJson(w, http.StatusOK, output)
 }
 
@@ -142,29 +306,59 @@ func (h *activitiesHandler) GetActivity(w http.ResponseWriter, r *http.Request)
 	}
 	activity, err := h.activitiesService.GetActivityByID(r.Context(), &types.GetActivityInput{ID: id})
 	if err != nil {
-		httpext.WriteError(w, http.StatusBadRequest, err)
+		writeError(w, err)
 		return
 	}
 	httpext.WriteJson(w, http.StatusOK, activity)
 }
 
+func (h *activitiesHandler) GetActivityHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	history, err := h.activitiesService.GetActivityHistory(r.Context(), &types.GetActivityInput{ID: id})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, history)
+}
+
 func (h *activitiesHandler) SearchActivity(w http.ResponseWriter, r *http.Request) {
-	term := r.URL.Query().Get("term")
-	activities, err := h.activitiesService.SearchActivities(r.Context(), term)
+	query := r.URL.Query()
+	input := &types.SearchActivitiesInput{Term: query.Get("term"), Tags: tagsOf(query)}
+	userID, err := userIDOf(query)
 	if err != nil {
 		httpext.WriteError(w, http.StatusBadRequest, err)
 		return
 	}
+	input.UserID = userID
+	activities, err := h.activitiesService.SearchActivities(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
 	httpext.WriteJson(w, http.StatusOK, activities)
 }
 
 func (h *activitiesHandler) GetActivities(w http.ResponseWriter, r *http.Request) {
-	activities, err := h.activitiesService.ListActivities(r.Context())
+	input, err := listActivitiesInput(r)
 	if err != nil {
-		httpext.WriteError(w, http.StatusInternalServerError, err)
+		httpext.WriteError(w, http.StatusBadRequest, err)
 		return
 	}
-	httpext.WriteJson(w, http.StatusOK, activities)
+	output, err := h.activitiesService.ListActivities(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if output.NextCursor != "" {
+		w.Header().Set(httpext.HeaderNextCursor, output.NextCursor)
+	}
+	httpext.WriteJson(w, http.StatusOK, output.Activities)
 }
 
 func (h *activitiesHandler) DeleteActivity(w http.ResponseWriter, r *http.Request) {
@@ -176,9 +370,74 @@ func (h *activitiesHandler) DeleteActivity(w http.ResponseWriter, r *http.Reques
 	}
 	id, err = h.activitiesService.DeleteActivityByID(r.Context(), &types.DeleteActivityInput{ID: id})
 	if err != nil {
-		httpext.WriteError(w, http.StatusBadRequest, err)
+		writeError(w, err)
 		return
 	}
 	w.Header().Set("Entity", fmt.Sprint(id))
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *activitiesHandler) PostRestoreActivity(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	activity, err := h.activitiesService.RestoreActivity(r.Context(), &types.RestoreActivityInput{ID: id})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, activity)
+}
+
+func (h *activitiesHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	userID, err := userIDOf(r.URL.Query())
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	activities, err := h.activitiesService.ListTrash(r.Context(), &types.ListTrashInput{UserID: userID})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, activities)
+}
+
+func listActivitiesInput(r *http.Request) (*types.ListActivitiesInput, error) {
+	var (
+		query = r.URL.Query()
+		input = &types.ListActivitiesInput{
+			From:     query.Get("from"),
+			To:       query.Get("to"),
+			Category: query.Get("category"),
+			Tags:     tagsOf(query),
+			Status:   query.Get("status"),
+			Sort:     query.Get("sort"),
+			Order:    query.Get("order"),
+			Cursor:   query.Get("cursor"),
+		}
+	)
+	if projectID := query.Get("project_id"); projectID != "" {
+		value, err := strconv.ParseInt(projectID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid project_id: %s", projectID)
+		}
+		input.ProjectID = value
+	}
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			return nil, fmt.Errorf("invalid limit: %s", limit)
+		}
+		input.Limit = value
+	}
+	userID, err := userIDOf(query)
+	if err != nil {
+		return nil, err
+	}
+	input.UserID = userID
+	return input, nil
+}
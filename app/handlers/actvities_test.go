@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/ungame/command-time-track/app/apperr"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/service"
+	"github.com/ungame/command-time-track/app/types"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestActivityErrors(t *testing.T) {
+	var (
+		activitiesService = service.NewActivitiesService(repository.NewMemoryTransactor(), repository.NewMemoryActivitiesRepository(), repository.NewMemoryActivityEventsRepository(), repository.NewMemoryProjectsRepository(), repository.NewMemoryClientsRepository(), nopObserver{})
+		router            = mux.NewRouter()
+	)
+	NewActivitiesHandler(activitiesService).Register(router)
+
+	started, err := activitiesService.StartActivity(context.Background(), &types.StartActivityInput{Category: "dev"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = activitiesService.StopActivity(context.Background(), &types.UpdateActivityInput{ID: started.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+
+	for _, test := range []struct {
+		method, path, body string
+		status             int
+		code, field        string
+	}{
+		{http.MethodGet, "/activities/999", "", http.StatusNotFound, apperr.CodeNotFound, ""},
+		{http.MethodPut, "/activities/999/stop", "{}", http.StatusNotFound, apperr.CodeNotFound, ""},
+		{http.MethodDelete, "/activities/999", "", http.StatusNotFound, apperr.CodeNotFound, ""},
+		{http.MethodPut, "/activities/1/pause", "", http.StatusConflict, apperr.CodeConflict, ""},
+		{http.MethodPost, "/activities", "{", http.StatusUnprocessableEntity, apperr.CodeValidation, ""},
+		{http.MethodPost, "/activities", `{"category": "dev", "started_at": "` + future + `"}`, http.StatusBadRequest, apperr.CodeValidation, "started_at"},
+	} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(test.method, test.path, strings.NewReader(test.body)))
+
+		var out httpext.ErrorOutput
+		if err = json.Unmarshal(recorder.Body.Bytes(), &out); err != nil {
+			t.Fatalf("%s %s: %v", test.method, test.path, err)
+		}
+		if recorder.Code != test.status || out.Code != test.code || out.Message == "" {
+			t.Errorf("%s %s: expected %d %s, got %d %+v", test.method, test.path, test.status, test.code, recorder.Code, out)
+		}
+		if strings.Contains(out.Message, "sql") {
+			t.Errorf("%s %s: expected no sql error, got %s", test.method, test.path, out.Message)
+		}
+		if test.field != "" && out.Fields[test.field] == "" {
+			t.Errorf("%s %s: expected details of %s, got %+v", test.method, test.path, test.field, out.Fields)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package handlers
+
+import (
+	_ "embed"
+	"encoding/json"
+	"github.com/gorilla/mux"
+	swaggerFiles "github.com/swaggo/files/v2"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/importer"
+	"github.com/ungame/command-time-track/app/openapi"
+	"github.com/ungame/command-time-track/app/types"
+	"net/http"
+)
+
+const (
+	tagActivities = "activities"
+	tagReports    = "reports"
+	tagBilling    = "billing"
+	tagUsers      = "users"
+	tagWebhooks   = "webhooks"
+	tagServer     = "server"
+
+	schemeToken = "token"
+)
+
+//go:embed templates/docs.html
+var docsPage []byte
+
+type docsHandler struct {
+	document []byte
+	assets   http.Handler
+}
+
+// NewDocsHandler returns the handler serving the OpenAPI document of the api and a Swagger UI page browsing it.
+func NewDocsHandler() Handler {
+	document, err := json.Marshal(OpenAPI())
+	if err != nil {
+		panic(err)
+	}
+	return &docsHandler{
+		document: document,
+		assets:   http.StripPrefix("/docs/", http.FileServer(http.FS(swaggerFiles.FS))),
+	}
+}
+
+func (h *docsHandler) Register(router *mux.Router) {
+	router.Path("/openapi.json").HandlerFunc(h.GetOpenAPI).Methods(http.MethodGet)
+	router.Path("/docs").HandlerFunc(h.GetDocs).Methods(http.MethodGet)
+	router.Path("/docs/{file}").Handler(h.assets).Methods(http.MethodGet)
+}
+
+func (h *docsHandler) GetOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(httpext.HeaderContentType, httpext.MimeJson)
+	_, _ = w.Write(h.document)
+}
+
+func (h *docsHandler) GetDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(httpext.HeaderContentType, httpext.MimeHtml)
+	_, _ = w.Write(docsPage)
+}
+
+// OpenAPI returns the OpenAPI document of the routes of NewRouter, a route missing from it
+// fails the tests.
+func OpenAPI() *openapi.Document {
+	d := openapi.New("Command Time Track", "Tracks the time spent on activities, billing it to clients.", "1.0.0")
+	d.Components.SecuritySchemes[schemeToken] = &openapi.SecurityScheme{
+		Type:        "http",
+		Scheme:      "bearer",
+		Description: "personal api token, required when the server runs with -auth, without it requests act as the default user without admin role",
+	}
+	d.Security = []map[string][]string{{schemeToken: {}}, {}}
+
+	var (
+		errorOutput    = httpext.ErrorOutput{}
+		conflictOutput = types.ConflictOutput{}
+		activity       = types.ActivityOutput{}
+		activities     = []*types.ActivityOutput{}
+	)
+
+	activityUpdate := func(method, path, summary string, input any) *openapi.Operation {
+		operation := d.Operation(method, path, tagActivities, summary).
+			JSON(http.StatusOK, "the activity", activity).
+			Errors(errorOutput, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity)
+		if input != nil {
+			operation.JSONBody(input)
+		}
+		return operation
+	}
+	listFilters := func(operation *openapi.Operation) *openapi.Operation {
+		return operation.
+			Query("from", openapi.String(), "activities started from the date or time").
+			Query("to", openapi.String(), "activities started before the date or time").
+			Query("category", openapi.String(), "").
+			Query("project_id", openapi.Integer(), "").
+			Query("tag", openapi.Array(openapi.String()), "activities having every tag, repeated or comma separated").
+			Query("status", openapi.String(), "").
+			Query("sort", openapi.String(), "").
+			Query("order", openapi.String("asc", "desc"), "").
+			Query("limit", openapi.Integer(), "").
+			Query("cursor", openapi.String(), "the X-Next-Cursor of the previous page").
+			Query("user_id", openapi.Integer(), "activities of another user, for admins and team members")
+	}
+
+	// activities
+	d.Operation(http.MethodPost, "/activities", tagActivities, "Start an activity").
+		Query("resolve", openapi.String(), "stop to stop the running activities overlapping the new one").
+		JSONBody(types.StartActivityInput{}).
+		JSON(http.StatusCreated, "the started activity with the activities stopped by it", types.StartActivityOutput{}).
+		ResponseHeader(http.StatusCreated, "Location", "url of the activity", openapi.String()).
+		JSON(http.StatusConflict, "the activity overlaps others", conflictOutput).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusForbidden, http.StatusUnprocessableEntity)
+	d.Operation(http.MethodPost, "/activities/manual", tagActivities, "Create a finished activity").
+		Query("resolve", openapi.String(), "stop to stop the running activities overlapping the new one").
+		JSONBody(types.StartActivityInput{}).
+		JSON(http.StatusCreated, "the activity", activity).
+		ResponseHeader(http.StatusCreated, "Location", "url of the activity", openapi.String()).
+		JSON(http.StatusConflict, "the activity overlaps others", conflictOutput).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusForbidden, http.StatusUnprocessableEntity)
+	listFilters(d.Operation(http.MethodGet, "/activities", tagActivities, "List activities")).
+		JSON(http.StatusOK, "a page of activities", activities).
+		ResponseHeader(http.StatusOK, httpext.HeaderNextCursor, "cursor of the next page, missing on the last page", openapi.String()).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusForbidden)
+	activityUpdate(http.MethodPut, "/activities/{id}/stop", "Stop an activity", types.UpdateActivityInput{})
+	activityUpdate(http.MethodPut, "/activities/{id}/pause", "Pause an activity", nil).Errors(errorOutput, http.StatusConflict)
+	activityUpdate(http.MethodPut, "/activities/{id}/resume", "Resume a paused activity", nil).Errors(errorOutput, http.StatusConflict)
+	activityUpdate(http.MethodPut, "/activities/{id}/category", "Change the category of an activity", types.UpdateActivityInput{})
+	activityUpdate(http.MethodPut, "/activities/{id}/description", "Change the description of an activity", types.UpdateActivityInput{})
+	activityUpdate(http.MethodPut, "/activities/{id}/project", "Move an activity to a project", types.UpdateActivityProjectInput{})
+	activityUpdate(http.MethodPut, "/activities/{id}/rate", "Change the hourly rate of an activity", types.UpdateActivityRateInput{})
+	activityUpdate(http.MethodPost, "/activities/{id}/tags", "Tag an activity", types.TagActivityInput{})
+	activityUpdate(http.MethodDelete, "/activities/{id}/tags/{tag}", "Remove a tag of an activity", nil)
+	activityUpdate(http.MethodPost, "/activities/{id}/restore", "Restore a deleted activity", nil)
+	d.Operation(http.MethodGet, "/activities/{id}", tagActivities, "Get an activity").
+		JSON(http.StatusOK, "the activity", activity).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound)
+	d.Operation(http.MethodDelete, "/activities/{id}", tagActivities, "Move an activity to the trash").
+		Empty(http.StatusNoContent, "the activity is deleted").
+		ResponseHeader(http.StatusNoContent, "Entity", "id of the deleted activity", openapi.Integer()).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound)
+	d.Operation(http.MethodGet, "/activities/{id}/history", tagActivities, "List the changes of an activity").
+		JSON(http.StatusOK, "the changes, oldest first", []*types.ActivityEventOutput{}).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound)
+	d.Operation(http.MethodGet, "/activities/_/search", tagActivities, "Search activities").
+		Query("term", openapi.String(), "text searched in the category and description").
+		Query("tag", openapi.Array(openapi.String()), "activities having every tag, repeated or comma separated").
+		Query("user_id", openapi.Integer(), "activities of another user, for admins and team members").
+		JSON(http.StatusOK, "the activities found", activities).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusForbidden)
+	d.Operation(http.MethodGet, "/activities/trash", tagActivities, "List deleted activities").
+		Query("user_id", openapi.Integer(), "activities of another user, for admins and team members").
+		JSON(http.StatusOK, "the deleted activities", activities).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusForbidden)
+	listFilters(d.Operation(http.MethodGet, "/activities/export", tagActivities, "Export activities")).
+		Query("format", openapi.String(FormatCsv, FormatJson, FormatNdjson), "csv by default").
+		Respond(http.StatusOK, "the activities as an attachment", openapi.Binary(), httpext.MimeCsv, httpext.MimeNdjson).
+		Respond(http.StatusOK, "", openapi.Array(d.SchemaOf(types.ExportActivityOutput{})), httpext.MimeJson).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusForbidden)
+	d.Operation(http.MethodPost, "/activities/import", tagActivities, "Import activities from a csv").
+		Query("tz", openapi.String(), "time zone of the times without offset").
+		Query("dry_run", openapi.Boolean(), "validate the rows without importing them").
+		Query("resolve", openapi.String(), "how rows overlapping activities are handled").
+		Query("date_format", openapi.String(importer.DateFormatMonthFirst, importer.DateFormatDayFirst), "order of the day and the month of the dates with slashes, detected from the file by default").
+		Body(openapi.Binary(), httpext.MimeCsv, "multipart/form-data").
+		JSON(http.StatusCreated, "the import report", types.ImportActivitiesOutput{}).
+		JSON(http.StatusOK, "the report of a dry run", types.ImportActivitiesOutput{}).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusForbidden, http.StatusUnprocessableEntity)
+	d.Operation(http.MethodGet, "/activities/stream", tagActivities, "Stream the changes of the activities").
+		Header(httpext.HeaderLastEventID, "resume after the event").
+		Query("last_event_id", openapi.Integer(), "resume after the event, for clients unable to set headers").
+		Respond(http.StatusOK, "server-sent events holding ActivityStreamOutput data", d.SchemaOf(types.ActivityStreamOutput{}), httpext.MimeEventStream).
+		Errors(errorOutput, http.StatusBadRequest)
+
+	// reports
+	d.Operation(http.MethodGet, "/reports/summary", tagReports, "Summarize the time spent").
+		Query("from", openapi.String(), "").
+		Query("to", openapi.String(), "").
+		Query("group_by", openapi.String("day", "week", "category", "project", "client"), "").
+		Query("tz", openapi.String(), "time zone of the days and weeks").
+		Query("include_running", openapi.Boolean(), "count the running activities until now").
+		Query("tag", openapi.Array(openapi.String()), "activities having every tag, repeated or comma separated").
+		Query("user_id", openapi.Integer(), "activities of another user, for admins and team members").
+		JSON(http.StatusOK, "the summary", types.SummaryReportOutput{}).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusForbidden)
+
+	// billing
+	d.Operation(http.MethodPost, "/clients", tagBilling, "Create a client").
+		JSONBody(types.ClientInput{}).
+		JSON(http.StatusCreated, "the client", types.ClientOutput{}).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity)
+	d.Operation(http.MethodGet, "/clients", tagBilling, "List clients").
+		JSON(http.StatusOK, "the clients", []*types.ClientOutput{})
+	d.Operation(http.MethodGet, "/clients/{id}", tagBilling, "Get a client").
+		JSON(http.StatusOK, "the client", types.ClientOutput{}).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusNotFound)
+	d.Operation(http.MethodPut, "/clients/{id}", tagBilling, "Update a client").
+		JSONBody(types.ClientInput{}).
+		JSON(http.StatusOK, "the client", types.ClientOutput{}).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity)
+	d.Operation(http.MethodDelete, "/clients/{id}", tagBilling, "Delete a client").
+		Empty(http.StatusNoContent, "the client is deleted").
+		Errors(errorOutput, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict)
+	d.Operation(http.MethodPost, "/projects", tagBilling, "Create a project").
+		JSONBody(types.ProjectInput{}).
+		JSON(http.StatusCreated, "the project", types.ProjectOutput{}).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity)
+	d.Operation(http.MethodGet, "/projects", tagBilling, "List projects").
+		Query("client_id", openapi.Integer(), "projects of the client").
+		JSON(http.StatusOK, "the projects", []*types.ProjectOutput{}).
+		Errors(errorOutput, http.StatusBadRequest)
+	d.Operation(http.MethodGet, "/projects/{id}", tagBilling, "Get a project").
+		JSON(http.StatusOK, "the project", types.ProjectOutput{}).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusNotFound)
+	d.Operation(http.MethodPut, "/projects/{id}", tagBilling, "Update a project").
+		JSONBody(types.ProjectInput{}).
+		JSON(http.StatusOK, "the project", types.ProjectOutput{}).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity)
+	d.Operation(http.MethodDelete, "/projects/{id}", tagBilling, "Delete a project").
+		Empty(http.StatusNoContent, "the project is deleted").
+		Errors(errorOutput, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict)
+	d.Operation(http.MethodPost, "/invoices", tagBilling, "Invoice the finished activities of a period").
+		Query("format", openapi.String(FormatJson, FormatHtml), "json by default, html for a printable page").
+		JSONBody(types.CreateInvoiceInput{}).
+		JSON(http.StatusCreated, "the invoice", types.InvoiceOutput{}).
+		Respond(http.StatusCreated, "", openapi.String(), httpext.MimeHtml).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity)
+	d.Operation(http.MethodGet, "/invoices", tagBilling, "List invoices").
+		JSON(http.StatusOK, "the invoices", []*types.InvoiceOutput{}).
+		Errors(errorOutput, http.StatusForbidden)
+	d.Operation(http.MethodGet, "/invoices/{id}", tagBilling, "Get an invoice").
+		Query("format", openapi.String(FormatJson, FormatHtml), "json by default, html for a printable page").
+		JSON(http.StatusOK, "the invoice", types.InvoiceOutput{}).
+		Respond(http.StatusOK, "", openapi.String(), httpext.MimeHtml).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound)
+
+	// users
+	d.Operation(http.MethodPost, "/users", tagUsers, "Create a user").
+		JSONBody(types.UserInput{}).
+		JSON(http.StatusCreated, "the user", types.UserOutput{}).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusForbidden, http.StatusConflict, http.StatusUnprocessableEntity)
+	d.Operation(http.MethodGet, "/users", tagUsers, "List users").
+		JSON(http.StatusOK, "the users", []*types.UserOutput{})
+	d.Operation(http.MethodGet, "/users/{id}", tagUsers, "Get a user").
+		JSON(http.StatusOK, "the user", types.UserOutput{}).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusNotFound)
+	d.Operation(http.MethodPut, "/users/{id}/role", tagUsers, "Change the role of a user").
+		JSONBody(types.UserRoleInput{}).
+		JSON(http.StatusOK, "the user", types.UserOutput{}).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity)
+	d.Operation(http.MethodPost, "/teams", tagUsers, "Create a team").
+		JSONBody(types.TeamInput{}).
+		JSON(http.StatusCreated, "the team", types.TeamOutput{}).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusForbidden, http.StatusConflict, http.StatusUnprocessableEntity)
+	d.Operation(http.MethodGet, "/teams", tagUsers, "List teams").
+		JSON(http.StatusOK, "the teams", []*types.TeamOutput{})
+	d.Operation(http.MethodGet, "/teams/{id}", tagUsers, "Get a team").
+		JSON(http.StatusOK, "the team", types.TeamOutput{}).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusNotFound)
+	d.Operation(http.MethodPut, "/teams/{id}/members/{user_id}", tagUsers, "Add a member to a team").
+		JSON(http.StatusOK, "the team", types.TeamOutput{}).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound)
+	d.Operation(http.MethodDelete, "/teams/{id}/members/{user_id}", tagUsers, "Remove a member of a team").
+		JSON(http.StatusOK, "the team", types.TeamOutput{}).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound)
+	d.Operation(http.MethodPost, "/tokens", tagUsers, "Create a personal api token").
+		JSONBody(types.TokenInput{}).
+		JSON(http.StatusCreated, "the token with its secret, never returned again", types.CreateTokenOutput{}).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusUnprocessableEntity)
+	d.Operation(http.MethodGet, "/tokens", tagUsers, "List the tokens of the user").
+		JSON(http.StatusOK, "the tokens", []*types.TokenOutput{})
+	d.Operation(http.MethodDelete, "/tokens/{id}", tagUsers, "Revoke a token").
+		Empty(http.StatusNoContent, "the token is revoked").
+		Errors(errorOutput, http.StatusBadRequest, http.StatusNotFound)
+
+	// webhooks
+	d.Operation(http.MethodPost, "/webhooks", tagWebhooks, "Create a webhook").
+		JSONBody(types.WebhookInput{}).
+		JSON(http.StatusCreated, "the webhook with its secret, never returned again", types.CreateWebhookOutput{}).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusForbidden, http.StatusUnprocessableEntity)
+	d.Operation(http.MethodGet, "/webhooks", tagWebhooks, "List the webhooks of the user").
+		JSON(http.StatusOK, "the webhooks", []*types.WebhookOutput{})
+	d.Operation(http.MethodGet, "/webhooks/{id}", tagWebhooks, "Get a webhook").
+		JSON(http.StatusOK, "the webhook", types.WebhookOutput{}).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusNotFound)
+	d.Operation(http.MethodPut, "/webhooks/{id}", tagWebhooks, "Update a webhook").
+		JSONBody(types.UpdateWebhookInput{}).
+		JSON(http.StatusOK, "the webhook", types.WebhookOutput{}).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity)
+	d.Operation(http.MethodDelete, "/webhooks/{id}", tagWebhooks, "Delete a webhook").
+		Empty(http.StatusNoContent, "the webhook is deleted").
+		Errors(errorOutput, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound)
+	d.Operation(http.MethodGet, "/webhooks/{id}/deliveries", tagWebhooks, "List the deliveries of a webhook").
+		Query("status", openapi.String("pending", "succeeded", "failed"), "").
+		JSON(http.StatusOK, "the deliveries, latest first", []*types.WebhookDeliveryOutput{}).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusNotFound)
+	d.Operation(http.MethodPost, "/webhooks/{id}/deliveries/{delivery_id}/replay", tagWebhooks, "Deliver an event again").
+		JSON(http.StatusAccepted, "the new delivery", types.WebhookDeliveryOutput{}).
+		Errors(errorOutput, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound)
+
+	// server
+	d.Operation(http.MethodGet, "/health", tagServer, "Check the server can serve requests").
+		JSON(http.StatusOK, "the server is healthy", map[string]string{}).
+		Errors(errorOutput, http.StatusServiceUnavailable).
+		Open()
+	d.Operation(http.MethodGet, "/metrics", tagServer, "Prometheus metrics").
+		Respond(http.StatusOK, "the metrics in the Prometheus text format", openapi.String(), "text/plain").
+		Open()
+	d.Operation(http.MethodGet, "/openapi.json", tagServer, "This document").
+		Respond(http.StatusOK, "the OpenAPI document", &openapi.Schema{Type: "object"}, httpext.MimeJson).
+		Open()
+	d.Operation(http.MethodGet, "/docs", tagServer, "Swagger UI browsing this document").
+		Respond(http.StatusOK, "the page", openapi.String(), httpext.MimeHtml).
+		Open()
+	d.Operation(http.MethodGet, "/docs/{file}", tagServer, "Swagger UI assets").
+		Respond(http.StatusOK, "the asset", openapi.Binary(), "application/octet-stream").
+		Empty(http.StatusNotFound, "").
+		Open()
+
+	// the inputs read from the path and query, and the payloads posted to the webhooks
+	for _, value := range []any{
+		types.GetActivityInput{},
+		types.DeleteActivityInput{},
+		types.RestoreActivityInput{},
+		types.ListTrashInput{},
+		types.SearchActivitiesInput{},
+		types.ListActivitiesInput{},
+		types.ListActivitiesOutput{},
+		types.ImportActivitiesInput{},
+		types.SummaryReportInput{},
+		types.ListProjectsInput{},
+		types.TeamMemberInput{},
+		types.WebhookPayload{},
+		types.IntervalOutput{},
+	} {
+		d.SchemaOf(value)
+	}
+
+	return d
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/ungame/command-time-track/app/openapi"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestOpenAPIRoutes fails when a route of the router served by the app is missing from the OpenAPI document,
+// or the document describes a route the router does not serve.
+func TestOpenAPIRoutes(t *testing.T) {
+	var (
+		document = OpenAPI()
+		router   = NewRouter(&Services{Heartbeat: DefaultHeartbeat})
+		routes   int
+	)
+
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			t.Errorf("route %s has no methods to document", path)
+			return nil
+		}
+		for _, method := range methods {
+			routes++
+			if !document.Has(method, path) {
+				t.Errorf("route %s %s is missing from the OpenAPI document", method, path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	operations := 0
+	for _, item := range document.Paths {
+		operations += len(item)
+	}
+	if operations != routes {
+		t.Errorf("expected %d documented operations, got %d", routes, operations)
+	}
+}
+
+// TestOpenAPISchemas fails when a type of the api is missing from the components of the OpenAPI document.
+func TestOpenAPISchemas(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "../types/types.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemas := OpenAPI().Components.Schemas
+
+	names := []string{"ErrorOutput"}
+	for _, decl := range file.Decls {
+		if decl, ok := decl.(*ast.GenDecl); ok && decl.Tok == token.TYPE {
+			for _, spec := range decl.Specs {
+				names = append(names, spec.(*ast.TypeSpec).Name.Name)
+			}
+		}
+	}
+	for _, name := range names {
+		if _, ok := schemas[name]; !ok {
+			t.Errorf("type %s is missing from the OpenAPI components", name)
+		}
+	}
+
+	started := schemas["StartActivityOutput"]
+	if started == nil || started.Properties["id"] == nil || started.Properties["stopped"] == nil {
+		t.Errorf("expected embedded fields inlined, got %+v", started)
+	}
+	if finishedAt := schemas["ActivityOutput"].Properties["finished_at"]; finishedAt == nil || !finishedAt.Nullable {
+		t.Errorf("expected nullable finished_at, got %+v", finishedAt)
+	}
+}
+
+func TestDocs(t *testing.T) {
+	router := mux.NewRouter()
+	NewDocsHandler().Register(router)
+
+	call := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+
+	recorder := call("/openapi.json")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	var document openapi.Document
+	if err := json.Unmarshal(recorder.Body.Bytes(), &document); err != nil {
+		t.Fatal(err)
+	}
+	if document.OpenAPI != openapi.Version || !document.Has(http.MethodGet, "/activities/{id}") {
+		t.Errorf("unexpected document %s", document.OpenAPI)
+	}
+
+	if recorder = call("/docs"); recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "/openapi.json") {
+		t.Errorf("expected swagger ui page, got %d", recorder.Code)
+	}
+	if recorder = call("/docs/swagger-ui-bundle.js"); recorder.Code != http.StatusOK || recorder.Body.Len() == 0 {
+		t.Errorf("expected bundled swagger ui asset, got %d", recorder.Code)
+	}
+	if recorder = call("/docs/missing.js"); recorder.Code != http.StatusNotFound {
+		t.Errorf("expected missing asset not found, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/types"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatCsv    = "csv"
+	FormatJson   = "json"
+	FormatNdjson = "ndjson"
+
+	exportFlushEvery = 100
+)
+
+var exportCsvHeader = []string{
+	"id", "category", "description", "status", "started_at", "finished_at", "updated_at", "duration_seconds", "duration_hours", "tags",
+	"project_id", "hourly_rate", "billable_amount",
+}
+
+type activitiesEncoder interface {
+	Begin() error
+	Encode(output *types.ExportActivityOutput) error
+	Flush() error
+	End() error
+}
+
+func newActivitiesEncoder(format string, w io.Writer) (activitiesEncoder, string, error) {
+	switch format {
+	case FormatCsv:
+		return &csvEncoder{w: csv.NewWriter(w)}, httpext.MimeCsv, nil
+	case FormatJson:
+		return &jsonEncoder{w: w, encoder: json.NewEncoder(w)}, httpext.MimeJson, nil
+	case FormatNdjson:
+		return &ndjsonEncoder{encoder: json.NewEncoder(w)}, httpext.MimeNdjson, nil
+	default:
+		return nil, "", fmt.Errorf("invalid format: %s", format)
+	}
+}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvEncoder) Begin() error {
+	return e.w.Write(exportCsvHeader)
+}
+
+func (e *csvEncoder) Encode(output *types.ExportActivityOutput) error {
+	finishedAt := ""
+	if output.FinishedAt != nil {
+		finishedAt = *output.FinishedAt
+	}
+	return e.w.Write([]string{
+		strconv.FormatInt(output.ID, 10),
+		output.Category,
+		output.Description,
+		output.Status,
+		output.StartedAt,
+		finishedAt,
+		output.UpdatedAt,
+		strconv.FormatInt(output.Duration, 10),
+		strconv.FormatFloat(output.DurationHours, 'f', 2, 64),
+		strings.Join(output.Tags, ","),
+		optionalInt(output.ProjectID),
+		optionalAmount(output.HourlyRate),
+		optionalAmount(output.BillableAmount),
+	})
+}
+
+func optionalInt(value *int64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatInt(*value, 10)
+}
+
+func optionalAmount(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', 2, 64)
+}
+
+func (e *csvEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvEncoder) End() error {
+	return e.Flush()
+}
+
+type jsonEncoder struct {
+	w       io.Writer
+	encoder *json.Encoder
+	count   int
+}
+
+func (e *jsonEncoder) Begin() error {
+	_, err := io.WriteString(e.w, "[")
+	return err
+}
+
+func (e *jsonEncoder) Encode(output *types.ExportActivityOutput) error {
+	if e.count > 0 {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.count++
+	return e.encoder.Encode(output)
+}
+
+func (e *jsonEncoder) Flush() error {
+	return nil
+}
+
+func (e *jsonEncoder) End() error {
+	_, err := io.WriteString(e.w, "]\n")
+	return err
+}
+
+type ndjsonEncoder struct {
+	encoder *json.Encoder
+}
+
+func (e *ndjsonEncoder) Begin() error {
+	return nil
+}
+
+func (e *ndjsonEncoder) Encode(output *types.ExportActivityOutput) error {
+	return e.encoder.Encode(output)
+}
+
+func (e *ndjsonEncoder) Flush() error {
+	return nil
+}
+
+func (e *ndjsonEncoder) End() error {
+	return nil
+}
+
+func (h *activitiesHandler) GetExportActivities(w http.ResponseWriter, r *http.Request) {
+	input, err := listActivitiesInput(r)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = FormatCsv
+	}
+
+	encoder, mime, err := newActivitiesEncoder(format, w)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var (
+		flusher, _ = w.(http.Flusher)
+		started    bool
+		count      int
+	)
+
+	// the response starts with the first activity, so invalid filters still get an error status
+	begin := func() error {
+		if started {
+			return nil
+		}
+		started = true
+		w.Header().Set(httpext.HeaderContentType, mime)
+		w.Header().Set(httpext.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"activities.%s\"", format))
+		w.WriteHeader(http.StatusOK)
+		return encoder.Begin()
+	}
+
+	err = h.activitiesService.ExportActivities(r.Context(), input, func(output *types.ExportActivityOutput) error {
+		if err := begin(); err != nil {
+			return err
+		}
+		if err := encoder.Encode(output); err != nil {
+			return err
+		}
+		count++
+		if count%exportFlushEvery == 0 && flusher != nil {
+			if err := encoder.Flush(); err != nil {
+				return err
+			}
+			flusher.Flush()
+		}
+		return nil
+	})
+
+	if err != nil && !started {
+		writeError(w, err)
+		return
+	}
+	if err == nil {
+		if err = begin(); err == nil {
+			err = encoder.End()
+		}
+	}
+	if err != nil {
+		log.Println("Error on export activities:", err.Error())
+	}
+}
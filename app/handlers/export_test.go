@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/observer"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/service"
+	"github.com/ungame/command-time-track/app/types"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newExportRouter(t *testing.T, activities int) *mux.Router {
+	t.Helper()
+
+	activitiesService := service.NewActivitiesService(repository.NewMemoryTransactor(), repository.NewMemoryActivitiesRepository(), repository.NewMemoryActivityEventsRepository(), repository.NewMemoryProjectsRepository(), repository.NewMemoryClientsRepository(), observer.NewActivitiesObserver())
+
+	for i := 0; i < activities; i++ {
+		input := &types.StartActivityInput{Category: "export", Description: "row, with \"quotes\""}
+		if i%2 == 0 {
+			input.Tags = []string{"billable"}
+		}
+		output, err := activitiesService.StartActivity(context.Background(), input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = activitiesService.StopActivity(context.Background(), &types.UpdateActivityInput{ID: output.ID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	router := mux.NewRouter()
+	NewActivitiesHandler(activitiesService).Register(router)
+	return router
+}
+
+func export(router *mux.Router, query string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/activities/export"+query, nil))
+	return recorder
+}
+
+func TestExportActivities(t *testing.T) {
+	router := newExportRouter(t, 3)
+
+	t.Run("Csv", func(t *testing.T) {
+		res := export(router, "")
+		if res.Code != http.StatusOK || res.Header().Get(httpext.HeaderContentType) != httpext.MimeCsv {
+			t.Fatalf("unexpected response: status=%d, content-type=%s", res.Code, res.Header().Get(httpext.HeaderContentType))
+		}
+		records, err := csv.NewReader(res.Body).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 4 || records[0][0] != "id" || records[1][2] != "row, with \"quotes\"" || records[1][9] != "billable" {
+			t.Errorf("unexpected records: %v", records)
+		}
+	})
+
+	t.Run("Json", func(t *testing.T) {
+		res := export(router, "?format=json&order=desc")
+		var outputs []*types.ExportActivityOutput
+		if err := json.NewDecoder(res.Body).Decode(&outputs); err != nil {
+			t.Fatal(err)
+		}
+		if len(outputs) != 3 || outputs[0].ID != 3 || outputs[0].FinishedAt == nil {
+			t.Errorf("unexpected outputs: %+v", outputs)
+		}
+	})
+
+	t.Run("Ndjson", func(t *testing.T) {
+		res := export(router, "?format=ndjson&status=finished")
+		scanner, lines := bufio.NewScanner(res.Body), 0
+		for scanner.Scan() {
+			output := new(types.ExportActivityOutput)
+			if err := json.Unmarshal(scanner.Bytes(), output); err != nil {
+				t.Fatal(err)
+			}
+			lines++
+		}
+		if lines != 3 {
+			t.Errorf("expected 3 lines, got %d", lines)
+		}
+	})
+
+	t.Run("Tags", func(t *testing.T) {
+		res := export(router, "?format=json&tag=billable")
+		var outputs []*types.ExportActivityOutput
+		if err := json.NewDecoder(res.Body).Decode(&outputs); err != nil {
+			t.Fatal(err)
+		}
+		if len(outputs) != 2 || outputs[1].ID != 3 || len(outputs[1].Tags) != 1 {
+			t.Errorf("unexpected outputs tagged billable: %+v", outputs)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		res := export(router, "?format=json&category=none")
+		if body := strings.TrimSpace(res.Body.String()); body != "[]" {
+			t.Errorf("expected empty array, got %s", body)
+		}
+	})
+
+	t.Run("InvalidInput", func(t *testing.T) {
+		for _, query := range []string{"?format=xml", "?status=unknown", "?from=yesterday", "?tag=a,,b&tag=" + strings.Repeat("x", 51)} {
+			if res := export(router, query); res.Code != http.StatusBadRequest {
+				t.Errorf("expected bad request for %s, got %d", query, res.Code)
+			}
+		}
+	})
+}
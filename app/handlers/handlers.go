@@ -1,7 +1,56 @@
 package handlers
 
-import "github.com/gorilla/mux"
+import (
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/service"
+	"github.com/ungame/command-time-track/app/types"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
 
 type Handler interface {
 	Register(router *mux.Router)
 }
+
+// userIDOf returns the user of the user_id query parameter, zero when not given.
+func userIDOf(query url.Values) (int64, error) {
+	userID := query.Get("user_id")
+	if userID == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid user_id: %s", userID)
+	}
+	return value, nil
+}
+
+// tagsOf returns the tags of the repeated tag query parameter, each one may hold comma separated tags.
+func tagsOf(query url.Values) []string {
+	tags := make([]string, 0)
+	for _, value := range query["tag"] {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// writeError writes the service errors with the status of their kind, and conflicts with the ids of the
+// conflicting activities.
+func writeError(w http.ResponseWriter, err error) {
+	var conflict *service.ConflictError
+	if errors.As(err, &conflict) {
+		out := httpext.OutputOf(http.StatusConflict, err)
+		httpext.WriteJson(w, http.StatusConflict, &types.ConflictOutput{Code: out.Code, Message: out.Message, Err: out.Err, Conflicts: conflict.IDs})
+		return
+	}
+	httpext.Error(w, err)
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+	"github.com/gorilla/mux"
+	"github.com/ungame/command-time-track/app/httpext"
+	"net/http"
+	"time"
+)
+
+// HealthCheck reports an error when the server can't serve requests, like an unreachable database.
+type HealthCheck func(ctx context.Context) error
+
+type healthHandler struct {
+	check HealthCheck
+}
+
+// NewHealthHandler returns the health handler, a nil check always reports healthy.
+func NewHealthHandler(check HealthCheck) Handler {
+	return &healthHandler{check: check}
+}
+
+func (h *healthHandler) Register(router *mux.Router) {
+	router.Path("/health").HandlerFunc(h.GetHealth).Methods(http.MethodGet)
+}
+
+func (h *healthHandler) GetHealth(w http.ResponseWriter, r *http.Request) {
+	if h.check != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second*5)
+		defer cancel()
+		if err := h.check(ctx); err != nil {
+			httpext.WriteError(w, http.StatusServiceUnavailable, err)
+			return
+		}
+	}
+	httpext.WriteJson(w, http.StatusOK, map[string]string{"status": "ok"})
+}
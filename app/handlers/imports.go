@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/ioext"
+	"github.com/ungame/command-time-track/app/service"
+	"github.com/ungame/command-time-track/app/types"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+const maxImportSize = 32 << 20
+
+type importHandler struct {
+	importService service.ImportService
+}
+
+func NewImportHandler(importService service.ImportService) Handler {
+	return &importHandler{importService: importService}
+}
+
+func (h *importHandler) Register(router *mux.Router) {
+	router.Path("/activities/import").HandlerFunc(h.PostImportActivities).Methods(http.MethodPost)
+}
+
+// PostImportActivities accepts the csv as request body or as the "file" field of a multipart form.
+func (h *importHandler) PostImportActivities(w http.ResponseWriter, r *http.Request) {
+	input, err := importActivitiesInput(r)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	var body io.Reader = r.Body
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get(httpext.HeaderContentType)); mediaType == "multipart/form-data" {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			httpext.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		defer ioext.Close(file)
+		body = file
+	}
+
+	output, err := h.importService.ImportActivities(r.Context(), input, body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	status := http.StatusCreated
+	if input.DryRun {
+		status = http.StatusOK
+	}
+	httpext.WriteJson(w, status, output)
+}
+
+func importActivitiesInput(r *http.Request) (*types.ImportActivitiesInput, error) {
+	var (
+		query = r.URL.Query()
+		input = &types.ImportActivitiesInput{TimeZone: query.Get("tz"), Resolve: query.Get("resolve"), DateFormat: query.Get("date_format")}
+	)
+	if dryRun := query.Get("dry_run"); dryRun != "" {
+		value, err := strconv.ParseBool(dryRun)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dry_run: %s", dryRun)
+		}
+		input.DryRun = value
+	}
+	return input, nil
+}
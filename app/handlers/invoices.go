@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/service"
+	"github.com/ungame/command-time-track/app/types"
+	"html/template"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+const FormatHtml = "html"
+
+//go:embed templates/invoice.html
+var templates embed.FS
+
+var invoiceTemplate = template.Must(template.ParseFS(templates, "templates/invoice.html"))
+
+type invoicesHandler struct {
+	invoicesService service.InvoicesService
+}
+
+func NewInvoicesHandler(invoicesService service.InvoicesService) Handler {
+	return &invoicesHandler{invoicesService: invoicesService}
+}
+
+func (h *invoicesHandler) Register(router *mux.Router) {
+	router.Path("/invoices").HandlerFunc(h.PostInvoice).Methods(http.MethodPost)
+	router.Path("/invoices").HandlerFunc(h.GetInvoices).Methods(http.MethodGet)
+	router.Path("/invoices/{id}").HandlerFunc(h.GetInvoice).Methods(http.MethodGet)
+}
+
+func (h *invoicesHandler) PostInvoice(w http.ResponseWriter, r *http.Request) {
+	format, err := invoiceFormat(r)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	input := new(types.CreateInvoiceInput)
+	err = json.Unmarshal(body, input)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	output, err := h.invoicesService.CreateInvoice(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/invoices/%d", output.ID))
+	writeInvoice(w, format, http.StatusCreated, output)
+}
+
+func (h *invoicesHandler) GetInvoices(w http.ResponseWriter, r *http.Request) {
+	output, err := h.invoicesService.ListInvoices(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+func (h *invoicesHandler) GetInvoice(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	format, err := invoiceFormat(r)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	output, err := h.invoicesService.GetInvoiceByID(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeInvoice(w, format, http.StatusOK, output)
+}
+
+// invoiceFormat returns the format query parameter, invoices are json by default or printable pages with html.
+func invoiceFormat(r *http.Request) (string, error) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "", FormatJson:
+		return FormatJson, nil
+	case FormatHtml:
+		return FormatHtml, nil
+	default:
+		return "", fmt.Errorf("invalid format: %s", format)
+	}
+}
+
+func writeInvoice(w http.ResponseWriter, format string, status int, output *types.InvoiceOutput) {
+	if format != FormatHtml {
+		httpext.WriteJson(w, status, output)
+		return
+	}
+	w.Header().Set(httpext.HeaderContentType, httpext.MimeHtml)
+	w.WriteHeader(status)
+	if err := invoiceTemplate.Execute(w, output); err != nil {
+		log.Println("Error on render invoice:", err.Error())
+	}
+}
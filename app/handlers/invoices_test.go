@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"github.com/gorilla/mux"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/pointer"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInvoices(t *testing.T) {
+	var (
+		transactor      = repository.NewMemoryTransactor()
+		activitiesRepo  = repository.NewMemoryActivitiesRepository()
+		eventsRepo      = repository.NewMemoryActivityEventsRepository()
+		projectsRepo    = repository.NewMemoryProjectsRepository()
+		clientsRepo     = repository.NewMemoryClientsRepository()
+		invoicesService = service.NewInvoicesService(transactor, activitiesRepo, eventsRepo, repository.NewMemoryInvoicesRepository(), projectsRepo, clientsRepo, time.UTC)
+		startedAt       = time.Now().UTC().Add(-time.Hour * 2)
+		router          = mux.NewRouter()
+	)
+
+	_, err := activitiesRepo.Create(context.Background(), &models.Activity{
+		Category:   "<script>",
+		Status:     models.StatusFinished,
+		StartedAt:  startedAt,
+		UpdatedAt:  startedAt,
+		FinishedAt: pointer.New(startedAt.Add(time.Minute * 90)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	NewInvoicesHandler(invoicesService).Register(router)
+
+	body := `{"categories": ["<script>"], "from": "` + startedAt.Add(-time.Hour).Format(time.RFC3339) + `", "to": "` +
+		time.Now().UTC().Format(time.RFC3339) + `", "hourly_rate": 80, "rounding": 6}`
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/invoices?format=xml", strings.NewReader(body)))
+	if res.Code != http.StatusBadRequest {
+		t.Errorf("expected invalid format, got %d", res.Code)
+	}
+
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/invoices", strings.NewReader(body)))
+	if res.Code != http.StatusCreated || res.Header().Get("Location") != "/invoices/1" {
+		t.Fatalf("unexpected response: status=%d, body=%s", res.Code, res.Body.String())
+	}
+
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/invoices/1?format=html", nil))
+	if res.Code != http.StatusOK || res.Header().Get(httpext.HeaderContentType) != httpext.MimeHtml {
+		t.Fatalf("unexpected response: status=%d, content-type=%s", res.Code, res.Header().Get(httpext.HeaderContentType))
+	}
+	page := res.Body.String()
+	if !strings.Contains(page, "Invoice #1") || !strings.Contains(page, "120.00") || strings.Contains(page, "<script>") {
+		t.Errorf("unexpected invoice page: %s", page)
+	}
+
+	res = httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/invoices/2", nil))
+	if res.Code != http.StatusNotFound {
+		t.Errorf("expected invoice not found, got %d", res.Code)
+	}
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"github.com/gorilla/mux"
+	"net/http"
+)
+
+type metricsHandler struct {
+	handler http.Handler
+}
+
+// NewMetricsHandler returns the handler exposing the metrics written by the handler, like promhttp.Handler.
+func NewMetricsHandler(handler http.Handler) Handler {
+	return &metricsHandler{handler: handler}
+}
+
+func (h *metricsHandler) Register(router *mux.Router) {
+	router.Path("/metrics").Handler(h.handler).Methods(http.MethodGet)
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/service"
+	"github.com/ungame/command-time-track/app/types"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+type projectsHandler struct {
+	projectsService service.ProjectsService
+}
+
+func NewProjectsHandler(projectsService service.ProjectsService) Handler {
+	return &projectsHandler{projectsService: projectsService}
+}
+
+func (h *projectsHandler) Register(router *mux.Router) {
+	router.Path("/clients").HandlerFunc(h.PostClient).Methods(http.MethodPost)
+	router.Path("/clients").HandlerFunc(h.GetClients).Methods(http.MethodGet)
+	router.Path("/clients/{id}").HandlerFunc(h.GetClient).Methods(http.MethodGet)
+	router.Path("/clients/{id}").HandlerFunc(h.PutClient).Methods(http.MethodPut)
+	router.Path("/clients/{id}").HandlerFunc(h.DeleteClient).Methods(http.MethodDelete)
+	router.Path("/projects").HandlerFunc(h.PostProject).Methods(http.MethodPost)
+	router.Path("/projects").HandlerFunc(h.GetProjects).Methods(http.MethodGet)
+	router.Path("/projects/{id}").HandlerFunc(h.GetProject).Methods(http.MethodGet)
+	router.Path("/projects/{id}").HandlerFunc(h.PutProject).Methods(http.MethodPut)
+	router.Path("/projects/{id}").HandlerFunc(h.DeleteProject).Methods(http.MethodDelete)
+}
+
+func (h *projectsHandler) PostClient(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	input := new(types.ClientInput)
+	err = json.Unmarshal(body, input)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	output, err := h.projectsService.CreateClient(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/clients/%d", output.ID))
+	httpext.WriteJson(w, http.StatusCreated, output)
+}
+
+func (h *projectsHandler) GetClients(w http.ResponseWriter, r *http.Request) {
+	output, err := h.projectsService.ListClients(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+func (h *projectsHandler) GetClient(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	output, err := h.projectsService.GetClientByID(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+func (h *projectsHandler) PutClient(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	input := new(types.ClientInput)
+	err = json.Unmarshal(body, input)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	input.ID = id
+	output, err := h.projectsService.UpdateClient(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+func (h *projectsHandler) DeleteClient(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err = h.projectsService.DeleteClientByID(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Entity", fmt.Sprint(id))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *projectsHandler) PostProject(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	input := new(types.ProjectInput)
+	err = json.Unmarshal(body, input)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	output, err := h.projectsService.CreateProject(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/projects/%d", output.ID))
+	httpext.WriteJson(w, http.StatusCreated, output)
+}
+
+func (h *projectsHandler) GetProjects(w http.ResponseWriter, r *http.Request) {
+	input := new(types.ListProjectsInput)
+	if clientID := r.URL.Query().Get("client_id"); clientID != "" {
+		value, err := strconv.ParseInt(clientID, 10, 64)
+		if err != nil {
+			httpext.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid client_id: %s", clientID))
+			return
+		}
+		input.ClientID = value
+	}
+	output, err := h.projectsService.ListProjects(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+func (h *projectsHandler) GetProject(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	output, err := h.projectsService.GetProjectByID(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+func (h *projectsHandler) PutProject(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	input := new(types.ProjectInput)
+	err = json.Unmarshal(body, input)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	input.ID = id
+	output, err := h.projectsService.UpdateProject(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+func (h *projectsHandler) DeleteProject(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err = h.projectsService.DeleteProjectByID(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Entity", fmt.Sprint(id))
+	w.WriteHeader(http.StatusNoContent)
+}
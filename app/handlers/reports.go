@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/service"
+	"github.com/ungame/command-time-track/app/types"
+	"net/http"
+	"strconv"
+)
+
+type reportsHandler struct {
+	reportsService service.ReportsService
+}
+
+func NewReportsHandler(reportsService service.ReportsService) Handler {
+	return &reportsHandler{reportsService: reportsService}
+}
+
+func (h *reportsHandler) Register(router *mux.Router) {
+	router.Path("/reports/summary").HandlerFunc(h.GetSummary).Methods(http.MethodGet)
+}
+
+func (h *reportsHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
+	input, err := summaryReportInput(r)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	output, err := h.reportsService.Summary(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+func summaryReportInput(r *http.Request) (*types.SummaryReportInput, error) {
+	var (
+		query = r.URL.Query()
+		input = &types.SummaryReportInput{
+			From:     query.Get("from"),
+			To:       query.Get("to"),
+			GroupBy:  query.Get("group_by"),
+			TimeZone: query.Get("tz"),
+			Tags:     tagsOf(query),
+		}
+	)
+	if includeRunning := query.Get("include_running"); includeRunning != "" {
+		value, err := strconv.ParseBool(includeRunning)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include_running: %s", includeRunning)
+		}
+		input.IncludeRunning = value
+	}
+	userID, err := userIDOf(query)
+	if err != nil {
+		return nil, err
+	}
+	input.UserID = userID
+	return input, nil
+}
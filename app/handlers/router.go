@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/ungame/command-time-track/app/service"
+	"net/http"
+	"time"
+)
+
+// Services are what the handlers of the api serve.
+type Services struct {
+	Activities service.ActivitiesService
+	Reports    service.ReportsService
+	Import     service.ImportService
+	Projects   service.ProjectsService
+	Invoices   service.InvoicesService
+	Users      service.UsersService
+	Tokens     service.TokensService
+	Teams      service.TeamsService
+	Stream     service.StreamService
+	Webhooks   service.WebhooksService
+	Metrics    http.Handler
+	Health     HealthCheck
+	Heartbeat  time.Duration
+}
+
+// NewRouter returns the router of the api, serving every handler behind the middlewares.
+// The routes it registers are the ones the OpenAPI document must describe.
+func NewRouter(services *Services, middlewares ...mux.MiddlewareFunc) *mux.Router {
+	router := mux.NewRouter().StrictSlash(true)
+	router.Use(middlewares...)
+	for _, handler := range []Handler{
+		NewMetricsHandler(services.Metrics),
+		NewDocsHandler(),
+		NewStreamHandler(services.Stream, services.Heartbeat),
+		NewActivitiesHandler(services.Activities),
+		NewReportsHandler(services.Reports),
+		NewImportHandler(services.Import),
+		NewProjectsHandler(services.Projects),
+		NewInvoicesHandler(services.Invoices),
+		NewUsersHandler(services.Users),
+		NewTokensHandler(services.Tokens),
+		NewTeamsHandler(services.Teams),
+		NewWebhooksHandler(services.Webhooks),
+		NewHealthHandler(services.Health),
+	} {
+		handler.Register(router)
+	}
+	return router
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/ungame/command-time-track/app/broker"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/service"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultHeartbeat is how often idle streams get a comment, keeping proxies from closing them.
+const DefaultHeartbeat = time.Second * 15
+
+// typeReset tells the stream subscriber it missed events for good, the activities must be fetched again.
+const typeReset = "reset"
+
+type streamHandler struct {
+	streamService service.StreamService
+	heartbeat     time.Duration
+}
+
+func NewStreamHandler(streamService service.StreamService, heartbeat time.Duration) Handler {
+	return &streamHandler{streamService: streamService, heartbeat: heartbeat}
+}
+
+// Register must run before the activities handler registers /activities/{id}.
+func (h *streamHandler) Register(router *mux.Router) {
+	router.Path("/activities/stream").HandlerFunc(h.GetActivitiesStream).Methods(http.MethodGet)
+}
+
+// GetActivitiesStream pushes the changes of the activities as server-sent events, resuming after the
+// Last-Event-ID header or the last_event_id query parameter when given.
+func (h *streamHandler) GetActivitiesStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		httpext.WriteError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+
+	lastEventID := r.Header.Get(httpext.HeaderLastEventID)
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	var (
+		lastID int64
+		err    error
+	)
+	if lastEventID != "" {
+		if lastID, err = strconv.ParseInt(lastEventID, 10, 64); err != nil || lastID < 0 {
+			httpext.WriteError(w, http.StatusBadRequest, fmt.Errorf("invalid last event id: %s", lastEventID))
+			return
+		}
+	}
+
+	subscription, err := h.streamService.SubscribeActivities(r.Context(), lastID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer subscription.Close()
+
+	w.Header().Set(httpext.HeaderContentType, httpext.MimeEventStream)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// nginx buffers responses by default
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if subscription.Reset {
+		fmt.Fprintf(w, "event: %s\ndata: {}\n\n", typeReset)
+	}
+	for _, event := range subscription.Missed {
+		writeEvent(w, event)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		case event, ok := <-subscription.Events():
+			if !ok {
+				// dropped for lagging behind, the client reconnects and resumes from the buffer
+				return
+			}
+			writeEvent(w, event)
+		}
+		flusher.Flush()
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event *broker.Event) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+}
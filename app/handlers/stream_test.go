@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/ungame/command-time-track/app/broker"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/service"
+	"github.com/ungame/command-time-track/app/types"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type nopObserver struct{}
+
+func (nopObserver) Count(string, ...string)          {}
+func (nopObserver) DurationOf(string, time.Duration) {}
+
+func TestActivitiesStream(t *testing.T) {
+	var (
+		ctx               = context.Background()
+		events            = broker.New(broker.DefaultBufferSize)
+		activitiesService = service.NewActivitiesService(repository.NewMemoryTransactor(), repository.NewMemoryActivitiesRepository(), events.ActivityEvents(repository.NewMemoryActivityEventsRepository()), repository.NewMemoryProjectsRepository(), repository.NewMemoryClientsRepository(), nopObserver{})
+		streamService     = service.NewStreamService(events)
+		router            = mux.NewRouter()
+	)
+
+	NewStreamHandler(streamService, time.Millisecond*50).Register(router)
+	NewActivitiesHandler(activitiesService).Register(router)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	first, err := activitiesService.StartActivity(ctx, &types.StartActivityInput{Category: "first"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// stops the first activity, publishing stopped 2 and started 3
+	if _, err = activitiesService.StartActivity(ctx, &types.StartActivityInput{Category: "second"}); err != nil {
+		t.Fatal(err)
+	}
+
+	stranger, err := streamService.SubscribeActivities(service.WithIdentity(ctx, &service.Identity{UserID: 3, Role: models.RoleMember}), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stranger.Missed) != 0 {
+		t.Errorf("expected events of another user hidden, got %d", len(stranger.Missed))
+	}
+	stranger.Close()
+
+	reqCtx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(reqCtx, http.MethodGet, server.URL+"/activities/stream", nil)
+	req.Header.Set(httpext.HeaderLastEventID, "1")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK || res.Header.Get(httpext.HeaderContentType) != httpext.MimeEventStream {
+		t.Fatalf("unexpected response: status=%d, content type=%s", res.StatusCode, res.Header.Get(httpext.HeaderContentType))
+	}
+
+	type sse struct {
+		id, event string
+		data      *types.ActivityStreamOutput
+		heartbeat bool
+	}
+
+	lines := bufio.NewScanner(res.Body)
+	next := func() sse {
+		t.Helper()
+		var e sse
+		for lines.Scan() {
+			line := lines.Text()
+			switch {
+			case line == "":
+				return e
+			case strings.HasPrefix(line, ":"):
+				e.heartbeat = true
+			case strings.HasPrefix(line, "id: "):
+				e.id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				e.event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e.data); err != nil {
+					t.Fatalf("unexpected data %s: %s", line, err.Error())
+				}
+			}
+		}
+		t.Fatalf("stream closed: %v", lines.Err())
+		return e
+	}
+
+	if e := next(); e.id != "2" || e.event != broker.TypeStopped || e.data.ActivityID != first.ID || e.data.Action != models.ActionStopped {
+		t.Errorf("unexpected resumed event: %+v", e)
+	}
+	if e := next(); e.id != "3" || e.event != broker.TypeStarted || e.data.Action != models.ActionCreated {
+		t.Errorf("unexpected resumed event: %+v", e)
+	}
+
+	if e := next(); !e.heartbeat {
+		t.Errorf("expected heartbeat on idle stream, got %+v", e)
+	}
+
+	if _, err = activitiesService.UpdateActivityCategory(ctx, &types.UpdateActivityInput{ID: first.ID, Category: "renamed"}); err != nil {
+		t.Fatal(err)
+	}
+	e := next()
+	for e.heartbeat {
+		e = next()
+	}
+	if e.id != "4" || e.event != broker.TypeUpdated || e.data.Action != models.ActionCategoryUpdated {
+		t.Errorf("unexpected live event: %+v", e)
+	}
+	var activity types.ActivityOutput
+	if err = json.Unmarshal(e.data.Activity, &activity); err != nil || activity.Category != "renamed" {
+		t.Errorf("unexpected activity of live event: %s", e.data.Activity)
+	}
+
+	badReq := httptest.NewRequest(http.MethodGet, "/activities/stream?last_event_id=x", nil)
+	badRes := httptest.NewRecorder()
+	router.ServeHTTP(badRes, badReq)
+	if badRes.Code != http.StatusBadRequest {
+		t.Errorf("expected invalid last event id rejected, got %d", badRes.Code)
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/service"
+	"github.com/ungame/command-time-track/app/types"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+type teamsHandler struct {
+	teamsService service.TeamsService
+}
+
+func NewTeamsHandler(teamsService service.TeamsService) Handler {
+	return &teamsHandler{teamsService: teamsService}
+}
+
+func (h *teamsHandler) Register(router *mux.Router) {
+	router.Path("/teams").HandlerFunc(h.PostTeam).Methods(http.MethodPost)
+	router.Path("/teams").HandlerFunc(h.GetTeams).Methods(http.MethodGet)
+	router.Path("/teams/{id}").HandlerFunc(h.GetTeam).Methods(http.MethodGet)
+	router.Path("/teams/{id}/members/{user_id}").HandlerFunc(h.PutTeamMember).Methods(http.MethodPut)
+	router.Path("/teams/{id}/members/{user_id}").HandlerFunc(h.DeleteTeamMember).Methods(http.MethodDelete)
+}
+
+func (h *teamsHandler) PostTeam(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	input := new(types.TeamInput)
+	err = json.Unmarshal(body, input)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	output, err := h.teamsService.CreateTeam(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/teams/%d", output.ID))
+	httpext.WriteJson(w, http.StatusCreated, output)
+}
+
+func (h *teamsHandler) GetTeams(w http.ResponseWriter, r *http.Request) {
+	output, err := h.teamsService.ListTeams(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+func (h *teamsHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	output, err := h.teamsService.GetTeamByID(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+func (h *teamsHandler) PutTeamMember(w http.ResponseWriter, r *http.Request) {
+	input, err := teamMemberOf(r)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	output, err := h.teamsService.AddTeamMember(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+func (h *teamsHandler) DeleteTeamMember(w http.ResponseWriter, r *http.Request) {
+	input, err := teamMemberOf(r)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	output, err := h.teamsService.RemoveTeamMember(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+func teamMemberOf(r *http.Request) (*types.TeamMemberInput, error) {
+	vars := mux.Vars(r)
+	teamID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := strconv.ParseInt(vars["user_id"], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &types.TeamMemberInput{TeamID: teamID, UserID: userID}, nil
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/service"
+	"github.com/ungame/command-time-track/app/types"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+type tokensHandler struct {
+	tokensService service.TokensService
+}
+
+func NewTokensHandler(tokensService service.TokensService) Handler {
+	return &tokensHandler{tokensService: tokensService}
+}
+
+func (h *tokensHandler) Register(router *mux.Router) {
+	router.Path("/tokens").HandlerFunc(h.PostToken).Methods(http.MethodPost)
+	router.Path("/tokens").HandlerFunc(h.GetTokens).Methods(http.MethodGet)
+	router.Path("/tokens/{id}").HandlerFunc(h.DeleteToken).Methods(http.MethodDelete)
+}
+
+func (h *tokensHandler) PostToken(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	input := new(types.TokenInput)
+	err = json.Unmarshal(body, input)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	output, err := h.tokensService.CreateToken(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusCreated, output)
+}
+
+func (h *tokensHandler) GetTokens(w http.ResponseWriter, r *http.Request) {
+	output, err := h.tokensService.ListTokens(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+// DeleteToken revokes the token, it stays listed with its revocation time.
+func (h *tokensHandler) DeleteToken(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err = h.tokensService.RevokeToken(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Entity", fmt.Sprint(id))
+	w.WriteHeader(http.StatusNoContent)
+}
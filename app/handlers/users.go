@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/service"
+	"github.com/ungame/command-time-track/app/types"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+type usersHandler struct {
+	usersService service.UsersService
+}
+
+func NewUsersHandler(usersService service.UsersService) Handler {
+	return &usersHandler{usersService: usersService}
+}
+
+func (h *usersHandler) Register(router *mux.Router) {
+	router.Path("/users").HandlerFunc(h.PostUser).Methods(http.MethodPost)
+	router.Path("/users").HandlerFunc(h.GetUsers).Methods(http.MethodGet)
+	router.Path("/users/{id}").HandlerFunc(h.GetUser).Methods(http.MethodGet)
+	router.Path("/users/{id}/role").HandlerFunc(h.PutUserRole).Methods(http.MethodPut)
+}
+
+func (h *usersHandler) PostUser(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	input := new(types.UserInput)
+	err = json.Unmarshal(body, input)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	output, err := h.usersService.CreateUser(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/users/%d", output.ID))
+	httpext.WriteJson(w, http.StatusCreated, output)
+}
+
+func (h *usersHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	output, err := h.usersService.GetUserByID(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+func (h *usersHandler) PutUserRole(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	input := new(types.UserRoleInput)
+	err = json.Unmarshal(body, input)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	input.ID = id
+	output, err := h.usersService.UpdateUserRole(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+func (h *usersHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
+	output, err := h.usersService.ListUsers(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/service"
+	"github.com/ungame/command-time-track/app/types"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+type webhooksHandler struct {
+	webhooksService service.WebhooksService
+}
+
+func NewWebhooksHandler(webhooksService service.WebhooksService) Handler {
+	return &webhooksHandler{webhooksService: webhooksService}
+}
+
+func (h *webhooksHandler) Register(router *mux.Router) {
+	router.Path("/webhooks").HandlerFunc(h.PostWebhook).Methods(http.MethodPost)
+	router.Path("/webhooks").HandlerFunc(h.GetWebhooks).Methods(http.MethodGet)
+	router.Path("/webhooks/{id}").HandlerFunc(h.GetWebhook).Methods(http.MethodGet)
+	router.Path("/webhooks/{id}").HandlerFunc(h.PutWebhook).Methods(http.MethodPut)
+	router.Path("/webhooks/{id}").HandlerFunc(h.DeleteWebhook).Methods(http.MethodDelete)
+	router.Path("/webhooks/{id}/deliveries").HandlerFunc(h.GetDeliveries).Methods(http.MethodGet)
+	router.Path("/webhooks/{id}/deliveries/{delivery_id}/replay").HandlerFunc(h.PostReplay).Methods(http.MethodPost)
+}
+
+func (h *webhooksHandler) PostWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	input := new(types.WebhookInput)
+	err = json.Unmarshal(body, input)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	output, err := h.webhooksService.CreateWebhook(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/webhooks/%d", output.ID))
+	httpext.WriteJson(w, http.StatusCreated, output)
+}
+
+func (h *webhooksHandler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	output, err := h.webhooksService.ListWebhooks(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+func (h *webhooksHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	output, err := h.webhooksService.GetWebhook(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+func (h *webhooksHandler) PutWebhook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	input := new(types.UpdateWebhookInput)
+	err = json.Unmarshal(body, input)
+	if err != nil {
+		httpext.WriteError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	input.ID = id
+	output, err := h.webhooksService.UpdateWebhook(r.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+func (h *webhooksHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err = h.webhooksService.DeleteWebhook(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Entity", fmt.Sprint(id))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDeliveries lists the last deliveries of the webhook, filtered by the status query parameter.
+func (h *webhooksHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	output, err := h.webhooksService.ListDeliveries(r.Context(), id, r.URL.Query().Get("status"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusOK, output)
+}
+
+// PostReplay queues the event of a delivery again, answering with the new delivery.
+func (h *webhooksHandler) PostReplay(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	deliveryID, err := strconv.ParseInt(vars["delivery_id"], 10, 64)
+	if err != nil {
+		httpext.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	output, err := h.webhooksService.ReplayDelivery(r.Context(), id, deliveryID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	httpext.WriteJson(w, http.StatusAccepted, output)
+}
@@ -2,14 +2,28 @@ package httpext
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/ungame/command-time-track/app/apperr"
 	"log"
 	"net/http"
 )
 
 const (
-	HeaderContentType = "Content-Type"
-	MimeJson          = "application/json"
+	HeaderContentType        = "Content-Type"
+	HeaderContentDisposition = "Content-Disposition"
+	HeaderNextCursor         = "X-Next-Cursor"
+	HeaderUser               = "X-User"
+	HeaderAuthorization      = "Authorization"
+	HeaderLastEventID        = "Last-Event-ID"
+	HeaderWebhookEvent       = "X-Webhook-Event"
+	HeaderWebhookDelivery    = "X-Webhook-Delivery"
+	HeaderWebhookSignature   = "X-Webhook-Signature"
+	MimeJson                 = "application/json"
+	MimeNdjson               = "application/x-ndjson"
+	MimeCsv                  = "text/csv"
+	MimeHtml                 = "text/html; charset=utf-8"
+	MimeEventStream          = "text/event-stream"
 )
 
 type Port int
@@ -18,8 +32,23 @@ func (p Port) Addr() string {
 	return fmt.Sprintf(":%d", p)
 }
 
+// CodeUnauthorized is the code of the requests missing valid credentials, refused before reaching the domain.
+const CodeUnauthorized = "unauthorized"
+
+// ErrorOutput is the body of the error responses, fields details the invalid fields of validation errors.
+// Err repeats the message for the clients reading it before the code was added.
 type ErrorOutput struct {
-	Err string `json:"error"`
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+	Reason  string            `json:"reason,omitempty"`
+	Err     string            `json:"error"`
+}
+
+// ReasonError is an error with a machine readable reason, like the reason a request is forbidden.
+type ReasonError interface {
+	error
+	ErrorReason() string
 }
 
 func WriteJson(w http.ResponseWriter, status int, data any) {
@@ -30,10 +59,68 @@ func WriteJson(w http.ResponseWriter, status int, data any) {
 	}
 }
 
+// StatusOf returns the status of the kind of the error, 500 for the errors out of the domain.
+func StatusOf(err error) int {
+	switch apperr.CodeOf(err) {
+	case apperr.CodeValidation:
+		return http.StatusBadRequest
+	case apperr.CodeConflict:
+		return http.StatusConflict
+	case apperr.CodeNotFound:
+		return http.StatusNotFound
+	case apperr.CodeForbidden:
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Error writes the error with the status of its kind.
+func Error(w http.ResponseWriter, err error) {
+	WriteError(w, StatusOf(err), err)
+}
+
+// WriteError writes the error with the status, like the malformed requests refused before reaching the services.
+// Internal errors are logged and written without their message, which may hold details like the sql errors.
 func WriteError(w http.ResponseWriter, status int, err error) {
-	out := ErrorOutput{"unknown error"}
-	if err != nil {
-		out.Err = err.Error()
+	WriteJson(w, status, OutputOf(status, err))
+}
+
+// OutputOf returns the output of the error written with the status.
+func OutputOf(status int, err error) *ErrorOutput {
+	if err == nil {
+		err = errors.New("unknown error")
+	}
+	out := &ErrorOutput{Code: codeOf(status, err), Message: err.Error(), Fields: apperr.FieldsOf(err)}
+	if status == http.StatusInternalServerError {
+		log.Println("Internal error:", err.Error())
+		out.Code, out.Message = apperr.CodeInternal, apperr.ErrInternal.Message
+	}
+	var reason ReasonError
+	if errors.As(err, &reason) {
+		out.Reason = reason.ErrorReason()
+	}
+	out.Err = out.Message
+	return out
+}
+
+// codeOf returns the code of the error, or the one of the status for the errors out of the domain.
+func codeOf(status int, err error) string {
+	if code := apperr.CodeOf(err); code != apperr.CodeInternal {
+		return code
+	}
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return apperr.CodeValidation
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return apperr.CodeForbidden
+	case http.StatusNotFound:
+		return apperr.CodeNotFound
+	case http.StatusConflict:
+		return apperr.CodeConflict
+	default:
+		return apperr.CodeInternal
 	}
-	WriteJson(w, status, out)
 }
@@ -0,0 +1,50 @@
+package httpext
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ungame/command-time-track/app/apperr"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	for _, test := range []struct {
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		{apperr.Errorf(apperr.CodeNotFound, "activity %d", 1), http.StatusNotFound, apperr.CodeNotFound, "not found: activity 1"},
+		{fmt.Errorf("%w: overlaps", apperr.ErrConflict), http.StatusConflict, apperr.CodeConflict, "conflict: overlaps"},
+		{apperr.Invalid("tz", errors.New("invalid time zone: x")), http.StatusBadRequest, apperr.CodeValidation, "invalid input: invalid time zone: x"},
+		{apperr.Errorf(apperr.CodeForbidden, "read only"), http.StatusForbidden, apperr.CodeForbidden, "forbidden: read only"},
+		{sql.ErrConnDone, http.StatusInternalServerError, apperr.CodeInternal, "internal error"},
+	} {
+		recorder := httptest.NewRecorder()
+		Error(recorder, test.err)
+
+		var out ErrorOutput
+		if err := json.Unmarshal(recorder.Body.Bytes(), &out); err != nil {
+			t.Fatal(err)
+		}
+		if recorder.Code != test.status || out.Code != test.code || out.Message != test.message || out.Err != test.message {
+			t.Errorf("expected %d %s %q for %v, got %d %+v", test.status, test.code, test.message, test.err, recorder.Code, out)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	Error(recorder, apperr.Invalid("tz", errors.New("invalid time zone: x")))
+	if body := recorder.Body.String(); body != `{"code":"validation","message":"invalid input: invalid time zone: x","fields":{"tz":"invalid time zone: x"},"error":"invalid input: invalid time zone: x"}`+"\n" {
+		t.Errorf("unexpected body %s", body)
+	}
+
+	recorder = httptest.NewRecorder()
+	WriteError(recorder, http.StatusUnprocessableEntity, errors.New("unexpected end of JSON input"))
+	if out := OutputOf(http.StatusUnprocessableEntity, errors.New("x")); recorder.Code != http.StatusUnprocessableEntity || out.Code != apperr.CodeValidation {
+		t.Errorf("expected malformed request as validation error, got %d %+v", recorder.Code, out)
+	}
+}
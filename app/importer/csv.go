@@ -0,0 +1,276 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	SourceToggl    = "toggl"
+	SourceClockify = "clockify"
+
+	DefaultCategory   = "undefined"
+	MaxCategoryLength = 50
+)
+
+// Formats of the dates written with slashes, the exports order the day and the month by the locale of the user.
+const (
+	DateFormatMonthFirst = "mm/dd/yyyy"
+	DateFormatDayFirst   = "dd/mm/yyyy"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported csv, expected a Toggl or Clockify detailed export")
+	// ErrAmbiguousDates rejects files with dates written with slashes read either way, set the date format.
+	ErrAmbiguousDates = fmt.Errorf("ambiguous dates, unable to tell the day from the month: set the date format, %s or %s", DateFormatMonthFirst, DateFormatDayFirst)
+	ErrMixedDates     = errors.New("mixed date formats, dates have the day first and the month first")
+	ErrDateFormat     = fmt.Errorf("invalid date format, expected %s or %s", DateFormatMonthFirst, DateFormatDayFirst)
+)
+
+var timeLayouts = []string{"15:04:05", "15:04", "03:04:05 PM", "3:04:05 PM", "03:04 PM", "3:04 PM"}
+
+// dateLayouts returns the layouts of the dates of a file, with the dates written with slashes in the format.
+func dateLayouts(format string) []string {
+	slashed := "01/02/2006"
+	if format == DateFormatDayFirst {
+		slashed = "02/01/2006"
+	}
+	return []string{"2006-01-02", slashed, "02.01.2006"}
+}
+
+// Record is an activity read from a csv row, rows are numbered from 1 including the header.
+type Record struct {
+	Row         int
+	Category    string
+	Description string
+	StartedAt   time.Time
+	FinishedAt  time.Time
+}
+
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Err.Error())
+}
+
+type Result struct {
+	Source   string
+	Records  []*Record
+	Rejected []*RowError
+}
+
+type columns map[string]int
+
+func (c columns) get(record []string, name string) string {
+	i, ok := c[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// ReadCSV reads a Toggl or Clockify detailed csv export, times without offset are read at loc. Dates written with
+// slashes are read in the date format, detected from the dates of the file when empty: files where every date reads
+// either way fail with ErrAmbiguousDates. Rows that can't be imported are returned as rejected instead of failing
+// the whole file.
+func ReadCSV(r io.Reader, loc *time.Location, dateFormat string) (*Result, error) {
+	switch dateFormat {
+	case "", DateFormatMonthFirst, DateFormatDayFirst:
+	default:
+		return nil, ErrDateFormat
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, ErrUnsupportedFormat
+		}
+		return nil, err
+	}
+
+	cols := make(columns, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	result := &Result{}
+
+	switch {
+	case hasColumns(cols, "duration (h)") || hasColumns(cols, "duration (decimal)"):
+		result.Source = SourceClockify
+	case hasColumns(cols, "duration"):
+		result.Source = SourceToggl
+	}
+	if result.Source == "" || !hasColumns(cols, "start date", "start time", "end date", "end time") {
+		return nil, ErrUnsupportedFormat
+	}
+
+	// the rows are read before parsing them to tell the date format from every date
+	rows := make([]*rawRow, 0)
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, err
+			}
+			rows = append(rows, &rawRow{row: row, err: parseErr.Err})
+			continue
+		}
+		rows = append(rows, &rawRow{row: row, record: record})
+	}
+
+	if dateFormat == "" {
+		dates := make([]string, 0, len(rows)*2)
+		for _, raw := range rows {
+			dates = append(dates, cols.get(raw.record, "start date"), cols.get(raw.record, "end date"))
+		}
+		if dateFormat, err = detectDateFormat(dates); err != nil {
+			return nil, err
+		}
+	}
+
+	layouts := dateLayouts(dateFormat)
+	for _, raw := range rows {
+		if raw.err != nil {
+			result.Rejected = append(result.Rejected, &RowError{Row: raw.row, Err: raw.err})
+			continue
+		}
+		activity, err := parseRecord(cols, raw.record, loc, layouts)
+		if err != nil {
+			result.Rejected = append(result.Rejected, &RowError{Row: raw.row, Err: err})
+			continue
+		}
+		activity.Row = raw.row
+		result.Records = append(result.Records, activity)
+	}
+
+	return result, nil
+}
+
+// rawRow is a row read before parsing it, err is set on rows that aren't valid csv.
+type rawRow struct {
+	row    int
+	record []string
+	err    error
+}
+
+// detectDateFormat returns the format of the dates written with slashes, from the dates with a day over 12.
+// Without such dates it is empty when no date is written with slashes, and fails otherwise.
+func detectDateFormat(dates []string) (string, error) {
+	var slashed, dayFirst, monthFirst bool
+	for _, date := range dates {
+		parts := strings.Split(date, "/")
+		if len(parts) != 3 {
+			continue
+		}
+		slashed = true
+		first, err1 := strconv.Atoi(parts[0])
+		second, err2 := strconv.Atoi(parts[1])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		dayFirst = dayFirst || first > 12
+		monthFirst = monthFirst || second > 12
+	}
+	switch {
+	case dayFirst && monthFirst:
+		return "", ErrMixedDates
+	case dayFirst:
+		return DateFormatDayFirst, nil
+	case monthFirst:
+		return DateFormatMonthFirst, nil
+	case slashed:
+		return "", ErrAmbiguousDates
+	default:
+		return "", nil
+	}
+}
+
+func hasColumns(cols columns, names ...string) bool {
+	for _, name := range names {
+		if _, ok := cols[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func parseRecord(cols columns, record []string, loc *time.Location, layouts []string) (*Record, error) {
+	startedAt, err := parseDateTime(cols.get(record, "start date"), cols.get(record, "start time"), loc, layouts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start: %w", err)
+	}
+	finishedAt, err := parseDateTime(cols.get(record, "end date"), cols.get(record, "end time"), loc, layouts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end: %w", err)
+	}
+	if !finishedAt.After(startedAt) {
+		return nil, errors.New("activity finishes before it starts")
+	}
+
+	category := cols.get(record, "project")
+	if category == "" {
+		category = firstTag(cols.get(record, "tags"))
+	}
+	if category == "" {
+		category = DefaultCategory
+	}
+	if utf8.RuneCountInString(category) > MaxCategoryLength {
+		return nil, fmt.Errorf("category longer than %d characters", MaxCategoryLength)
+	}
+
+	description := cols.get(record, "description")
+	if description == "" {
+		description = cols.get(record, "task")
+	}
+
+	return &Record{
+		Category:    category,
+		Description: description,
+		StartedAt:   startedAt.UTC(),
+		FinishedAt:  finishedAt.UTC(),
+	}, nil
+}
+
+func firstTag(tags string) string {
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			return tag
+		}
+	}
+	return ""
+}
+
+func parseDateTime(date, clock string, loc *time.Location, layouts []string) (time.Time, error) {
+	if date == "" || clock == "" {
+		return time.Time{}, errors.New("missing date or time")
+	}
+	value := date + " " + strings.ToUpper(clock)
+	for _, dateLayout := range layouts {
+		for _, timeLayout := range timeLayouts {
+			if t, err := time.ParseInLocation(dateLayout+" "+timeLayout, value, loc); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("unknown date or time format: %s %s", date, clock)
+}
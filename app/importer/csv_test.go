@@ -0,0 +1,102 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const togglExport = "\ufeffUser,Email,Client,Project,Task,Description,Billable,Start date,Start time,End date,End time,Duration,Tags,Amount ()\n" +
+	"Ana,ana@example.com,,Backend,,Review pull requests,No,2023-01-15,09:00:00,2023-01-15,10:30:00,01:30:00,,\n" +
+	"Ana,ana@example.com,,,,Standup,No,2023-01-15,10:30:00,2023-01-15,10:45:00,00:15:00,\"meeting, daily\",\n" +
+	"Ana,ana@example.com,,Backend,,Broken row,No,2023-01-15,11:00:00,2023-01-15,10:00:00,00:00:00,,\n" +
+	"Ana,ana@example.com,,Backend,,Late night,No,2023-01-15,23:30:00,2023-01-16,00:30:00,01:00:00,,\n"
+
+const clockifyExport = "Project,Client,Description,Task,User,Group,Email,Tags,Billable,Start Date,Start Time,End Date,End Time,Duration (h),Duration (decimal)\n" +
+	"Frontend,,,Fix layout,Bruno,,bruno@example.com,,Yes,01/16/2023,01:00:00 PM,01/16/2023,02:15:00 PM,01:15:00,1.25\n" +
+	"Frontend,,Demo,,Bruno,,bruno@example.com,,Yes,not a date,01:00:00 PM,01/16/2023,02:15:00 PM,01:15:00,1.25\n"
+
+func TestReadCSV(t *testing.T) {
+	loc := time.FixedZone("UTC-3", -3*60*60)
+
+	t.Run("Toggl", func(t *testing.T) {
+		result, err := ReadCSV(strings.NewReader(togglExport), loc, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Source != SourceToggl || len(result.Records) != 3 || len(result.Rejected) != 1 {
+			t.Fatalf("unexpected result: source=%s, records=%d, rejected=%v", result.Source, len(result.Records), result.Rejected)
+		}
+
+		first := result.Records[0]
+		if first.Row != 2 || first.Category != "Backend" || first.Description != "Review pull requests" {
+			t.Errorf("unexpected first record: %+v", first)
+		}
+		if expected := time.Date(2023, 1, 15, 12, 0, 0, 0, time.UTC); !first.StartedAt.Equal(expected) {
+			t.Errorf("unexpected started at: expected=%s, got=%s", expected, first.StartedAt)
+		}
+		if first.FinishedAt.Sub(first.StartedAt) != time.Minute*90 {
+			t.Errorf("unexpected duration: %s", first.FinishedAt.Sub(first.StartedAt))
+		}
+		if category := result.Records[1].Category; category != "meeting" {
+			t.Errorf("expected category from first tag, got %s", category)
+		}
+		if result.Rejected[0].Row != 4 {
+			t.Errorf("unexpected rejected row: %v", result.Rejected[0])
+		}
+	})
+
+	t.Run("Clockify", func(t *testing.T) {
+		result, err := ReadCSV(strings.NewReader(clockifyExport), loc, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Source != SourceClockify || len(result.Records) != 1 || len(result.Rejected) != 1 {
+			t.Fatalf("unexpected result: source=%s, records=%d, rejected=%v", result.Source, len(result.Records), result.Rejected)
+		}
+		record := result.Records[0]
+		if record.Description != "Fix layout" || record.StartedAt.Hour() != 16 || record.FinishedAt.Sub(record.StartedAt) != time.Minute*75 {
+			t.Errorf("unexpected record: %+v", record)
+		}
+	})
+
+	t.Run("DateFormat", func(t *testing.T) {
+		const header = "Project,Client,Description,Task,User,Group,Email,Tags,Billable,Start Date,Start Time,End Date,End Time,Duration (h),Duration (decimal)\n"
+		row := func(date string) string {
+			return "Frontend,,Review,,Bruno,,bruno@example.com,,Yes," + date + ",09:00:00," + date + ",10:00:00,01:00:00,1.00\n"
+		}
+
+		result, err := ReadCSV(strings.NewReader(header+row("03/04/2023")+row("13/04/2023")), time.UTC, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Records) != 2 || result.Records[0].StartedAt.Month() != time.April || result.Records[0].StartedAt.Day() != 3 {
+			t.Errorf("expected day first dates detected, got %+v", result.Records)
+		}
+
+		ambiguous := header + row("03/04/2023") + row("05/06/2023")
+		if _, err = ReadCSV(strings.NewReader(ambiguous), time.UTC, ""); err != ErrAmbiguousDates {
+			t.Errorf("expected ambiguous dates rejected, got %v", err)
+		}
+		if result, err = ReadCSV(strings.NewReader(ambiguous), time.UTC, DateFormatDayFirst); err != nil || result.Records[0].StartedAt.Month() != time.April {
+			t.Errorf("expected dates read in the format given, got %+v, %v", result, err)
+		}
+		if result, err = ReadCSV(strings.NewReader(ambiguous), time.UTC, DateFormatMonthFirst); err != nil || result.Records[0].StartedAt.Month() != time.March {
+			t.Errorf("expected dates read in the format given, got %+v, %v", result, err)
+		}
+		if _, err = ReadCSV(strings.NewReader(header+row("13/04/2023")+row("04/13/2023")), time.UTC, ""); err != ErrMixedDates {
+			t.Errorf("expected mixed dates rejected, got %v", err)
+		}
+		if _, err = ReadCSV(strings.NewReader(ambiguous), time.UTC, "yyyy/mm/dd"); err != ErrDateFormat {
+			t.Errorf("expected invalid date format rejected, got %v", err)
+		}
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		for _, data := range []string{"", "id,category\n1,work\n"} {
+			if _, err := ReadCSV(strings.NewReader(data), loc, ""); err != ErrUnsupportedFormat {
+				t.Errorf("expected unsupported format error, got %v", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package middlewares
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/service"
+	"net/http"
+	"strings"
+)
+
+const bearer = "Bearer "
+
+var errInvalidToken = errors.New("missing, invalid or revoked api token")
+
+// Auth requires a personal api token in the Authorization header and makes the request act as the user owning it,
+// requests to the open paths skip the check, an open path ending with a slash leaves the paths under it open.
+func Auth(tokensRepository repository.TokensRepository, usersService service.UsersService, open ...string) mux.MiddlewareFunc {
+	var (
+		skip     = make(map[string]bool, len(open))
+		prefixes []string
+	)
+	for _, path := range open {
+		skip[path] = true
+		if strings.HasSuffix(path, "/") {
+			prefixes = append(prefixes, path)
+		}
+	}
+	isOpen := func(path string) bool {
+		if skip[path] {
+			return true
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if isOpen(request.URL.Path) || request.Method == http.MethodOptions {
+				next.ServeHTTP(writer, request)
+				return
+			}
+
+			header := request.Header.Get(httpext.HeaderAuthorization)
+			if !strings.HasPrefix(header, bearer) {
+				unauthorized(writer)
+				return
+			}
+
+			token, err := tokensRepository.GetByHash(request.Context(), models.HashToken(strings.TrimSpace(header[len(bearer):])))
+			if errors.Is(err, sql.ErrNoRows) || (err == nil && token.Revoked()) {
+				unauthorized(writer)
+				return
+			}
+			if err != nil {
+				httpext.WriteError(writer, http.StatusInternalServerError, err)
+				return
+			}
+
+			ctx, err := usersService.Identify(request.Context(), token.UserID)
+			if err != nil {
+				httpext.WriteError(writer, http.StatusInternalServerError, err)
+				return
+			}
+			next.ServeHTTP(writer, request.WithContext(ctx))
+		})
+	}
+}
+
+func unauthorized(writer http.ResponseWriter) {
+	writer.Header().Set("WWW-Authenticate", `Bearer realm="ctt"`)
+	httpext.WriteError(writer, http.StatusUnauthorized, errInvalidToken)
+}
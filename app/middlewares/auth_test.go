@@ -0,0 +1,80 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAuth(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		tokens = repository.NewMemoryTokensRepository()
+		users  = repository.NewMemoryUsersRepository()
+		router = mux.NewRouter()
+		user   = &models.User{Name: "alice", Role: models.RoleMember}
+	)
+
+	if _, err := users.Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	token, secret, err := models.NewToken(user.ID, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tokens.Create(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+
+	router.Use(Auth(tokens, service.NewUsersService(users, repository.NewMemoryTeamsRepository()), "/health", "/docs/"))
+	router.Path("/health").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	router.Path("/docs/{file}").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	router.Path("/whoami").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, repository.OwnerFrom(r.Context()))
+	})
+
+	call := func(path, authorization string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		if authorization != "" {
+			request.Header.Set(httpext.HeaderAuthorization, authorization)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	if recorder := call("/health", ""); recorder.Code != http.StatusOK {
+		t.Errorf("expected open path, got %d", recorder.Code)
+	}
+	if recorder := call("/docs/index.css", ""); recorder.Code != http.StatusOK {
+		t.Errorf("expected path under open prefix, got %d", recorder.Code)
+	}
+	if recorder := call("/whoami", ""); recorder.Code != http.StatusUnauthorized || recorder.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("expected missing token unauthorized, got %d", recorder.Code)
+	}
+	if recorder := call("/whoami", "Bearer "+secret+"x"); recorder.Code != http.StatusUnauthorized {
+		t.Errorf("expected invalid token unauthorized, got %d", recorder.Code)
+	}
+	if recorder := call("/whoami", "Bearer "+secret); recorder.Code != http.StatusOK || recorder.Body.String() != fmt.Sprint(user.ID) {
+		t.Errorf("expected request of user %d, got %d %s", user.ID, recorder.Code, recorder.Body.String())
+	}
+
+	if _, err = tokens.Revoke(ctx, user.ID, token.ID, time.Now().UTC()); err != nil {
+		t.Fatal(err)
+	}
+	if recorder := call("/whoami", "Bearer "+secret); recorder.Code != http.StatusUnauthorized {
+		t.Errorf("expected revoked token unauthorized, got %d", recorder.Code)
+	}
+}
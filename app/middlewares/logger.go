@@ -16,6 +16,13 @@ func (r *statusCodeRecorder) WriteHeader(status int) {
 	r.status = status
 }
 
+// Flush lets streaming handlers flush through the recorder.
+func (r *statusCodeRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
 func Logger(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
 		var (
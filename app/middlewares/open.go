@@ -0,0 +1,31 @@
+package middlewares
+
+import (
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/service"
+	"net/http"
+)
+
+var errUserHeader = errors.New("the X-User header is not trusted, start the server with -auth and use an api token")
+
+// Open makes the requests of a server without authentication act as the default user, never as an admin. Requests
+// naming a user in the X-User header are refused, nothing proves who makes them.
+func Open(usersService service.UsersService) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if request.Header.Get(httpext.HeaderUser) != "" {
+				httpext.WriteError(writer, http.StatusUnauthorized, errUserHeader)
+				return
+			}
+			ctx, err := usersService.Identify(request.Context(), models.DefaultUserID)
+			if err != nil {
+				httpext.WriteError(writer, http.StatusInternalServerError, err)
+				return
+			}
+			next.ServeHTTP(writer, request.WithContext(service.WithoutAdmin(ctx)))
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/service"
+	"github.com/ungame/command-time-track/app/types"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpen(t *testing.T) {
+	var (
+		usersService = service.NewUsersService(repository.NewMemoryUsersRepository(), repository.NewMemoryTeamsRepository())
+		router       = mux.NewRouter()
+	)
+
+	router.Use(Open(usersService))
+	router.Path("/users").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := usersService.CreateUser(r.Context(), &types.UserInput{Name: r.URL.Query().Get("name")}); err != nil {
+			httpext.Error(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	call := func(name, user string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/users?name="+name, strings.NewReader(""))
+		if user != "" {
+			request.Header.Set(httpext.HeaderUser, user)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	if recorder := call("alice", ""); recorder.Code != http.StatusForbidden {
+		t.Errorf("expected request of the default user not to act as admin, got %d", recorder.Code)
+	}
+	if recorder := call("alice", "default"); recorder.Code != http.StatusUnauthorized {
+		t.Errorf("expected request naming the admin unauthorized, got %d %s", recorder.Code, recorder.Body.String())
+	}
+}
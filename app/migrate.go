@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"github.com/ungame/command-time-track/app/ioext"
+	"github.com/ungame/command-time-track/db"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+const migrateUsage = "usage: main [flags] migrate up|down|status"
+
+func Migrate(args []string) {
+	if len(args) != 1 {
+		log.Fatalln(migrateUsage)
+	}
+
+	if store == StoreMemory {
+		log.Fatalln("migrations are not supported by the memory storage backend")
+	}
+
+	conn := openStore()
+	defer ioext.Close(conn)
+
+	migrator, err := db.NewMigrator(conn, store)
+	if err != nil {
+		log.Fatalln("unable to load migrations:", err.Error())
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Println("Applied", migration.Name)
+		}
+		if err != nil {
+			log.Fatalln(err.Error())
+		}
+		if len(applied) == 0 {
+			fmt.Println("No pending migrations")
+		}
+	case "down":
+		reverted, err := migrator.Down(ctx)
+		if err != nil {
+			log.Fatalln(err.Error())
+		}
+		if reverted == nil {
+			fmt.Println("No applied migrations")
+			return
+		}
+		fmt.Println("Reverted", reverted.Name)
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalln(err.Error())
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range status {
+			appliedAt := "pending"
+			if s.Applied() {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		if err = w.Flush(); err != nil {
+			log.Println("Error on write migrations status:", err.Error())
+		}
+	default:
+		log.Fatalln(migrateUsage)
+	}
+}
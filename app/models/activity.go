@@ -1,23 +1,104 @@
 package models
 
 import (
+	"fmt"
 	"github.com/ungame/command-time-track/app/pointer"
 	"github.com/ungame/command-time-track/app/types"
+	"math"
+	"sort"
+	"strings"
 	"time"
 )
 
+const MaxTagLength = 50
+
 type Status string
 
 const (
 	StatusFinished Status = "0"
 	StatusStarted  Status = "1"
+	StatusPaused   Status = "2"
 )
 
 func (s Status) String() string {
-	if s == "0" {
+	switch s {
+	case StatusFinished:
 		return "FINISHED"
+	case StatusPaused:
+		return "PAUSED"
+	default:
+		return "STARTED"
+	}
+}
+
+func ParseStatus(s string) (Status, error) {
+	switch strings.ToUpper(s) {
+	case "FINISHED", string(StatusFinished):
+		return StatusFinished, nil
+	case "STARTED", string(StatusStarted):
+		return StatusStarted, nil
+	case "PAUSED", string(StatusPaused):
+		return StatusPaused, nil
+	default:
+		return "", fmt.Errorf("invalid status: %s", s)
+	}
+}
+
+// ParseTags trims and lower cases the tags, dropping repeated ones. Tags can't be empty,
+// longer than MaxTagLength or contain commas, which separate tags in filters and exports.
+func ParseTags(tags []string) ([]string, error) {
+	parsed := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		switch {
+		case tag == "":
+			return nil, fmt.Errorf("invalid tag: empty")
+		case len([]rune(tag)) > MaxTagLength:
+			return nil, fmt.Errorf("invalid tag: %s, longer than %d characters", tag, MaxTagLength)
+		case strings.Contains(tag, ","):
+			return nil, fmt.Errorf("invalid tag: %s, commas are not allowed", tag)
+		}
+		if !contains(parsed, tag) {
+			parsed = append(parsed, tag)
+		}
+	}
+	return parsed, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+type Interval struct {
+	ID         int64
+	ActivityID int64
+	StartedAt  time.Time
+	FinishedAt *time.Time
+}
+
+func (i *Interval) Duration(now time.Time) time.Duration {
+	if i.FinishedAt == nil {
+		return now.Sub(i.StartedAt)
 	}
-	return "STARTED"
+	return i.FinishedAt.Sub(i.StartedAt)
+}
+
+func (i *Interval) Out() *types.IntervalOutput {
+	out := &types.IntervalOutput{
+		ID:           i.ID,
+		StartedAt:    i.StartedAt.String(),
+		StartedTime:  i.StartedAt,
+		FinishedTime: timeCopy(i.FinishedAt),
+	}
+	if i.FinishedAt != nil {
+		out.FinishedAt = pointer.New(i.FinishedAt.String())
+	}
+	return out
 }
 
 type Activity struct {
@@ -28,6 +109,14 @@ type Activity struct {
 	StartedAt   time.Time
 	UpdatedAt   time.Time
 	FinishedAt  *time.Time
+	Intervals   []*Interval
+	Tags        []string
+	ProjectID   *int64
+	HourlyRate  *Cents
+	InvoiceID   *int64
+	OwnerID     int64
+	// DeletedAt is set while the activity is in the trash.
+	DeletedAt *time.Time
 }
 
 func (a *Activity) GetFinishedAt() string {
@@ -37,14 +126,162 @@ func (a *Activity) GetFinishedAt() string {
 	return a.FinishedAt.String()
 }
 
+func (a *Activity) OpenInterval() *Interval {
+	for _, interval := range a.Intervals {
+		if interval.FinishedAt == nil {
+			return interval
+		}
+	}
+	return nil
+}
+
+// Duration sums the running intervals of the activity, activities recorded
+// before intervals existed fall back to the whole period between start and finish.
+func (a *Activity) Duration(now time.Time) time.Duration {
+	if len(a.Intervals) == 0 {
+		if a.FinishedAt == nil {
+			return now.Sub(a.StartedAt)
+		}
+		return a.FinishedAt.Sub(a.StartedAt)
+	}
+	var total time.Duration
+	for _, interval := range a.Intervals {
+		total += interval.Duration(now)
+	}
+	return total
+}
+
+func (a *Activity) Pause(now time.Time) {
+	if interval := a.OpenInterval(); interval != nil {
+		interval.FinishedAt = pointer.New(now)
+	}
+	a.Status = StatusPaused
+	a.UpdatedAt = now
+}
+
+func (a *Activity) Resume(now time.Time) {
+	if a.OpenInterval() == nil {
+		a.Intervals = append(a.Intervals, &Interval{ActivityID: a.ID, StartedAt: now})
+	}
+	a.Status = StatusStarted
+	a.UpdatedAt = now
+}
+
+func (a *Activity) Finish(now time.Time) {
+	if interval := a.OpenInterval(); interval != nil {
+		interval.FinishedAt = pointer.New(now)
+	}
+	a.Status = StatusFinished
+	a.FinishedAt = pointer.New(now)
+	a.UpdatedAt = now
+}
+
+// HasTags reports whether the activity has every one of the tags.
+func (a *Activity) HasTags(tags ...string) bool {
+	for _, tag := range tags {
+		if !contains(a.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// AddTags adds the tags the activity doesn't have yet, keeping tags sorted.
+func (a *Activity) AddTags(tags ...string) bool {
+	added := false
+	for _, tag := range tags {
+		if !contains(a.Tags, tag) {
+			a.Tags = append(a.Tags, tag)
+			added = true
+		}
+	}
+	sort.Strings(a.Tags)
+	return added
+}
+
+// RemoveTags removes the tags from the activity, reporting whether it had any of them.
+func (a *Activity) RemoveTags(tags ...string) bool {
+	kept := make([]string, 0, len(a.Tags))
+	for _, tag := range a.Tags {
+		if !contains(tags, tag) {
+			kept = append(kept, tag)
+		}
+	}
+	removed := len(kept) != len(a.Tags)
+	a.Tags = kept
+	return removed
+}
+
 func (a *Activity) Out() *types.ActivityOutput {
+	intervals := make([]*types.IntervalOutput, 0, len(a.Intervals))
+	for _, interval := range a.Intervals {
+		intervals = append(intervals, interval.Out())
+	}
 	return &types.ActivityOutput{
-		ID:          a.ID,
-		Category:    a.Category,
-		Description: a.Description,
-		Status:      a.Status.String(),
-		StartedAt:   a.StartedAt.String(),
-		UpdatedAt:   a.UpdatedAt.String(),
-		FinishedAt:  pointer.New(a.GetFinishedAt()),
+		ID:           a.ID,
+		Category:     a.Category,
+		Description:  a.Description,
+		Status:       a.Status.String(),
+		StartedAt:    a.StartedAt.String(),
+		UpdatedAt:    a.UpdatedAt.String(),
+		FinishedAt:   pointer.New(a.GetFinishedAt()),
+		Duration:     int64(a.Duration(time.Now().UTC()).Seconds()),
+		Intervals:    intervals,
+		Tags:         a.tags(),
+		ProjectID:    a.ProjectID,
+		HourlyRate:   floatOf(a.HourlyRate),
+		InvoiceID:    a.InvoiceID,
+		DeletedAt:    timeString(a.DeletedAt),
+		StartedTime:  a.StartedAt,
+		UpdatedTime:  a.UpdatedAt,
+		FinishedTime: timeCopy(a.FinishedAt),
+		DeletedTime:  timeCopy(a.DeletedAt),
+	}
+}
+
+// Export returns the activity with RFC 3339 times and the duration computed at now.
+func (a *Activity) Export(now time.Time) *types.ExportActivityOutput {
+	duration := a.Duration(now).Round(time.Second)
+	out := &types.ExportActivityOutput{
+		ID:            a.ID,
+		Category:      a.Category,
+		Description:   a.Description,
+		Status:        a.Status.String(),
+		StartedAt:     a.StartedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:     a.UpdatedAt.UTC().Format(time.RFC3339),
+		Duration:      int64(duration.Seconds()),
+		DurationHours: math.Round(duration.Hours()*100) / 100,
+		Tags:          a.tags(),
+		ProjectID:     a.ProjectID,
+		HourlyRate:    floatOf(a.HourlyRate),
+		InvoiceID:     a.InvoiceID,
+	}
+	if a.FinishedAt != nil {
+		out.FinishedAt = pointer.New(a.FinishedAt.UTC().Format(time.RFC3339))
+	}
+	return out
+}
+
+// tags returns the activity tags, never nil so outputs list no tags as an empty array.
+func (a *Activity) tags() []string {
+	if a.Tags == nil {
+		return make([]string, 0)
+	}
+	return a.Tags
+}
+
+// timeCopy returns a copy of the time, so outputs don't change with the activity, nil when not set.
+func timeCopy(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	return pointer.New(*t)
+}
+
+// timeString returns the time the way outputs show it, nil when not set.
+func timeString(t *time.Time) *string {
+	if t == nil {
+		return nil
 	}
+	return pointer.New(t.String())
 }
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestActivityDuration(t *testing.T) {
+
+	var (
+		started = time.Date(2022, 11, 7, 9, 0, 0, 0, time.UTC)
+		now     = started.Add(time.Hour * 4)
+	)
+
+	activity := &Activity{StartedAt: started, Status: StatusStarted, Intervals: []*Interval{{StartedAt: started}}}
+
+	if duration := activity.Duration(started.Add(time.Hour)); duration != time.Hour {
+		t.Errorf("unexpected duration of running activity: expected=%s, got=%s", time.Hour, duration)
+	}
+
+	activity.Pause(started.Add(time.Hour * 2))
+
+	if activity.Status != StatusPaused || activity.OpenInterval() != nil {
+		t.Errorf("unexpected paused activity: status=%v, intervals=%v", activity.Status, activity.Intervals)
+	}
+
+	if duration := activity.Duration(now); duration != time.Hour*2 {
+		t.Errorf("unexpected duration of paused activity: expected=%s, got=%s", time.Hour*2, duration)
+	}
+
+	activity.Resume(started.Add(time.Hour * 3))
+	activity.Resume(started.Add(time.Hour * 3))
+
+	if len(activity.Intervals) != 2 {
+		t.Errorf("unexpected intervals on resume twice: expected=2, got=%d", len(activity.Intervals))
+	}
+
+	activity.Finish(now)
+
+	if duration := activity.Duration(now.Add(time.Hour)); duration != time.Hour*3 {
+		t.Errorf("unexpected duration of finished activity: expected=%s, got=%s", time.Hour*3, duration)
+	}
+
+	legacy := &Activity{StartedAt: started, FinishedAt: &now}
+
+	if duration := legacy.Duration(now.Add(time.Hour)); duration != time.Hour*4 {
+		t.Errorf("unexpected duration of activity without intervals: expected=%s, got=%s", time.Hour*4, duration)
+	}
+}
+
+func TestStatusString(t *testing.T) {
+	for status, expected := range map[Status]string{
+		StatusStarted:  "STARTED",
+		StatusPaused:   "PAUSED",
+		StatusFinished: "FINISHED",
+	} {
+		if status.String() != expected {
+			t.Errorf("unexpected status string: expected=%s, got=%s", expected, status.String())
+		}
+	}
+}
+
+func TestActivityTags(t *testing.T) {
+
+	tags, err := ParseTags([]string{" Billable", "meeting", "billable ", "PROJ-123"})
+	if err != nil {
+		t.Fatalf("unexpected error on parse tags: %s", err.Error())
+	}
+	if fmt.Sprint(tags) != "[billable meeting proj-123]" {
+		t.Errorf("unexpected parsed tags: %v", tags)
+	}
+
+	for _, invalid := range [][]string{{""}, {"  "}, {"a,b"}, {strings.Repeat("x", MaxTagLength+1)}} {
+		if _, err = ParseTags(invalid); err == nil {
+			t.Errorf("expected error on parse tags: %q", invalid)
+		}
+	}
+
+	activity := new(Activity)
+
+	if !activity.AddTags("oncall", "billable") || activity.AddTags("billable") {
+		t.Errorf("unexpected added tags: %v", activity.Tags)
+	}
+	if !activity.HasTags("billable", "oncall") || activity.HasTags("billable", "meeting") {
+		t.Errorf("unexpected has tags: %v", activity.Tags)
+	}
+	if !activity.RemoveTags("oncall", "meeting") || activity.RemoveTags("meeting") {
+		t.Errorf("unexpected removed tags: %v", activity.Tags)
+	}
+	if out := activity.Out(); fmt.Sprint(out.Tags) != "[billable]" {
+		t.Errorf("unexpected output tags: %v", out.Tags)
+	}
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"encoding/json"
+	"github.com/ungame/command-time-track/app/types"
+	"time"
+)
+
+// Actions recorded in the history of an activity.
+const (
+	ActionCreated            = "created"
+	ActionStopped            = "stopped"
+	ActionPaused             = "paused"
+	ActionResumed            = "resumed"
+	ActionCategoryUpdated    = "category_updated"
+	ActionDescriptionUpdated = "description_updated"
+	ActionProjectUpdated     = "project_updated"
+	ActionRateUpdated        = "rate_updated"
+	ActionTagged             = "tagged"
+	ActionUntagged           = "untagged"
+	ActionTrimmed            = "trimmed"
+	ActionSplit              = "split"
+	ActionInvoiced           = "invoiced"
+	ActionDeleted            = "deleted"
+	ActionRestored           = "restored"
+	ActionPurged             = "purged"
+)
+
+// ActivityEvent is a change made to an activity by an actor. Before and after hold the activity
+// as JSON, before is nil for created activities and after is nil for purged ones.
+type ActivityEvent struct {
+	ID         int64
+	ActivityID int64
+	OwnerID    int64
+	ActorID    int64
+	Action     string
+	Before     *string
+	After      *string
+	CreatedAt  time.Time
+}
+
+func (e *ActivityEvent) Out() *types.ActivityEventOutput {
+	return &types.ActivityEventOutput{
+		ID:         e.ID,
+		ActivityID: e.ActivityID,
+		ActorID:    e.ActorID,
+		Action:     e.Action,
+		Before:     rawJSON(e.Before),
+		After:      rawJSON(e.After),
+		CreatedAt:  e.CreatedAt.String(),
+	}
+}
+
+// rawJSON returns the value as raw JSON, null when missing.
+func rawJSON(value *string) json.RawMessage {
+	if value == nil {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(*value)
+}
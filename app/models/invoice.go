@@ -0,0 +1,98 @@
+package models
+
+import (
+	"github.com/ungame/command-time-track/app/types"
+	"math"
+	"time"
+)
+
+// Invoice bills finished activities at a single hourly rate, one item per category.
+// Durations are rounded per activity to the nearest Rounding before being billed.
+type Invoice struct {
+	ID         int64
+	Client     string
+	From       time.Time
+	To         time.Time
+	HourlyRate Cents
+	Rounding   time.Duration
+	Total      Cents
+	Items      []*InvoiceItem
+	CreatedAt  time.Time
+}
+
+type InvoiceItem struct {
+	ID         int64
+	InvoiceID  int64
+	Category   string
+	Activities int
+	Duration   time.Duration
+	Amount     Cents
+}
+
+// Round returns the duration rounded to the nearest multiple of the invoice rounding, halves round up,
+// durations are rounded to the second without rounding.
+func (i *Invoice) Round(duration time.Duration) time.Duration {
+	if i.Rounding <= 0 {
+		return duration.Round(time.Second)
+	}
+	return duration.Round(i.Rounding)
+}
+
+// Add bills the duration of an activity in the item of its category, creating the item on its first activity.
+func (i *Invoice) Add(category string, duration time.Duration) {
+	var item *InvoiceItem
+	for _, existing := range i.Items {
+		if existing.Category == category {
+			item = existing
+			break
+		}
+	}
+	if item == nil {
+		item = &InvoiceItem{Category: category}
+		i.Items = append(i.Items, item)
+	}
+	item.Activities++
+	item.Duration += i.Round(duration)
+
+	i.Total -= item.Amount
+	item.Amount = i.HourlyRate.Bill(item.Duration)
+	i.Total += item.Amount
+}
+
+// Duration returns the billed duration of the invoice.
+func (i *Invoice) Duration() time.Duration {
+	var total time.Duration
+	for _, item := range i.Items {
+		total += item.Duration
+	}
+	return total
+}
+
+func (i *Invoice) Out() *types.InvoiceOutput {
+	items := make([]*types.InvoiceItemOutput, 0, len(i.Items))
+	for _, item := range i.Items {
+		items = append(items, item.Out())
+	}
+	return &types.InvoiceOutput{
+		ID:         i.ID,
+		Client:     i.Client,
+		From:       i.From.UTC().Format(time.RFC3339),
+		To:         i.To.UTC().Format(time.RFC3339),
+		HourlyRate: i.HourlyRate.Float(),
+		Rounding:   int(i.Rounding.Minutes()),
+		Duration:   int64(i.Duration().Seconds()),
+		Total:      i.Total.Float(),
+		Items:      items,
+		CreatedAt:  i.CreatedAt.String(),
+	}
+}
+
+func (i *InvoiceItem) Out() *types.InvoiceItemOutput {
+	return &types.InvoiceItemOutput{
+		Category:      i.Category,
+		Activities:    i.Activities,
+		Duration:      int64(i.Duration.Seconds()),
+		DurationHours: math.Round(i.Duration.Hours()*100) / 100,
+		Amount:        i.Amount.Float(),
+	}
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Cents is an amount of money in hundredths of the currency unit, rates and amounts are kept as integers
+// so sums don't accumulate floating point errors.
+type Cents int64
+
+// CentsOf converts an amount in currency units to cents, rounding to the nearest cent.
+func CentsOf(amount float64) (Cents, error) {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) || amount < 0 {
+		return 0, fmt.Errorf("invalid amount: %v", amount)
+	}
+	if amount > math.MaxInt64/100 {
+		return 0, fmt.Errorf("invalid amount: %v, too large", amount)
+	}
+	return Cents(math.Round(amount * 100)), nil
+}
+
+// Float returns the amount in currency units.
+func (c Cents) Float() float64 {
+	return float64(c) / 100
+}
+
+// Bill returns the amount due for the duration at c per hour, rounded to the nearest cent.
+func (c Cents) Bill(duration time.Duration) Cents {
+	return Cents(math.Round(float64(c) * duration.Hours()))
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCents(t *testing.T) {
+	rate, err := CentsOf(80.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate != 8050 || rate.Float() != 80.5 {
+		t.Errorf("unexpected cents of 80.5: %d", rate)
+	}
+
+	if amount := rate.Bill(time.Minute * 90); amount != 12075 {
+		t.Errorf("unexpected amount of 90 minutes: expected=12075, got=%d", amount)
+	}
+	if amount := rate.Bill(time.Minute); amount != 134 {
+		t.Errorf("expected amount rounded to the nearest cent, got %d", amount)
+	}
+
+	for _, amount := range []float64{-1, math.NaN(), math.Inf(1), math.MaxFloat64} {
+		if _, err = CentsOf(amount); err == nil {
+			t.Errorf("expected invalid amount: %v", amount)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"github.com/ungame/command-time-track/app/pointer"
+	"github.com/ungame/command-time-track/app/types"
+	"time"
+)
+
+type Client struct {
+	ID         int64
+	Name       string
+	HourlyRate *Cents
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (c *Client) Out() *types.ClientOutput {
+	return &types.ClientOutput{
+		ID:         c.ID,
+		Name:       c.Name,
+		HourlyRate: floatOf(c.HourlyRate),
+		CreatedAt:  c.CreatedAt.String(),
+		UpdatedAt:  c.UpdatedAt.String(),
+	}
+}
+
+type Project struct {
+	ID         int64
+	ClientID   int64
+	Name       string
+	HourlyRate *Cents
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (p *Project) Out() *types.ProjectOutput {
+	return &types.ProjectOutput{
+		ID:         p.ID,
+		ClientID:   p.ClientID,
+		Name:       p.Name,
+		HourlyRate: floatOf(p.HourlyRate),
+		CreatedAt:  p.CreatedAt.String(),
+		UpdatedAt:  p.UpdatedAt.String(),
+	}
+}
+
+func floatOf(cents *Cents) *float64 {
+	if cents == nil {
+		return nil
+	}
+	return pointer.New(cents.Float())
+}
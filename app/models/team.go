@@ -0,0 +1,25 @@
+package models
+
+import (
+	"github.com/ungame/command-time-track/app/types"
+	"time"
+)
+
+// Team groups users reading the activities of each other.
+type Team struct {
+	ID        int64
+	Name      string
+	Members   []int64
+	CreatedAt time.Time
+}
+
+func (t *Team) Out() *types.TeamOutput {
+	members := make([]int64, len(t.Members))
+	copy(members, t.Members)
+	return &types.TeamOutput{
+		ID:        t.ID,
+		Name:      t.Name,
+		Members:   members,
+		CreatedAt: t.CreatedAt.String(),
+	}
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/ungame/command-time-track/app/types"
+	"time"
+)
+
+const (
+	// TokenPrefix starts every api token, telling them apart from other secrets.
+	TokenPrefix = "ctt_"
+	// tokenVisible is the length of the start of a token kept in clear to recognize it.
+	tokenVisible = len(TokenPrefix) + 6
+)
+
+// Token is a personal api token, only its hash is stored, the secret is shown once on creation.
+type Token struct {
+	ID        int64
+	UserID    int64
+	Name      string
+	Prefix    string
+	Hash      string
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
+
+// NewToken returns a token of the user and its secret.
+func NewToken(userID int64, name string) (*Token, string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, "", err
+	}
+	secret := TokenPrefix + hex.EncodeToString(random)
+	token := &Token{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    secret[:tokenVisible],
+		Hash:      HashToken(secret),
+		CreatedAt: time.Now().UTC(),
+	}
+	return token, secret, nil
+}
+
+// HashToken returns the hash stored for the token secret.
+func HashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func (t *Token) Revoked() bool {
+	return t.RevokedAt != nil
+}
+
+func (t *Token) Out() *types.TokenOutput {
+	output := &types.TokenOutput{
+		ID:        t.ID,
+		Name:      t.Name,
+		Prefix:    t.Prefix,
+		CreatedAt: t.CreatedAt.String(),
+	}
+	if t.RevokedAt != nil {
+		revokedAt := t.RevokedAt.String()
+		output.RevokedAt = &revokedAt
+	}
+	return output
+}
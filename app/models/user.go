@@ -0,0 +1,46 @@
+package models
+
+import (
+	"fmt"
+	"github.com/ungame/command-time-track/app/types"
+	"strings"
+	"time"
+)
+
+// DefaultUserID is the user created by the migrations, owning the activities recorded before users existed.
+const DefaultUserID int64 = 1
+
+// Role sets what a user is allowed to do: viewers only read, members track their own activities
+// and admins manage every user, team, client, project and invoice.
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleMember Role = "member"
+	RoleAdmin  Role = "admin"
+)
+
+func ParseRole(s string) (Role, error) {
+	switch role := Role(strings.ToLower(strings.TrimSpace(s))); role {
+	case RoleViewer, RoleMember, RoleAdmin:
+		return role, nil
+	default:
+		return "", fmt.Errorf("invalid role: %s, must be viewer, member or admin", s)
+	}
+}
+
+type User struct {
+	ID        int64
+	Name      string
+	Role      Role
+	CreatedAt time.Time
+}
+
+func (u *User) Out() *types.UserOutput {
+	return &types.UserOutput{
+		ID:        u.ID,
+		Name:      u.Name,
+		Role:      string(u.Role),
+		CreatedAt: u.CreatedAt.String(),
+	}
+}
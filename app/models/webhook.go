@@ -0,0 +1,143 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/ungame/command-time-track/app/types"
+	"time"
+)
+
+// WebhookSecretPrefix starts every generated webhook secret.
+const WebhookSecretPrefix = "whsec_"
+
+// Statuses of a webhook delivery, pending deliveries are attempted again until they succeed or fail for good.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook receives the activity events of its owner, the events it accepts or every event when none are set.
+// The secret signs the payloads, it is kept in clear to sign them.
+type Webhook struct {
+	ID        int64
+	OwnerID   int64
+	URL       string
+	Secret    string
+	Events    []string
+	Active    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewWebhookSecret returns a random secret to sign the payloads of a webhook.
+func NewWebhookSecret() (string, error) {
+	random := make([]byte, 24)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return WebhookSecretPrefix + hex.EncodeToString(random), nil
+}
+
+// Accepts tells whether the webhook receives the events of the type.
+func (w *Webhook) Accepts(eventType string) bool {
+	if !w.Active {
+		return false
+	}
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, accepted := range w.Events {
+		if accepted == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Webhook) Out() *types.WebhookOutput {
+	events := make([]string, len(w.Events))
+	copy(events, w.Events)
+	return &types.WebhookOutput{
+		ID:        w.ID,
+		URL:       w.URL,
+		Events:    events,
+		Active:    w.Active,
+		CreatedAt: w.CreatedAt.String(),
+		UpdatedAt: w.UpdatedAt.String(),
+	}
+}
+
+// WebhookEvent is an activity event queued to dispatch to the webhooks, identified by the id of the activity event.
+type WebhookEvent struct {
+	EventID   int64
+	EventType string
+	OwnerID   int64
+	Data      string
+	CreatedAt time.Time
+}
+
+// WebhookDelivery is an event sent to a webhook, with the outcome of its last attempt.
+type WebhookDelivery struct {
+	ID             int64
+	WebhookID      int64
+	EventID        int64
+	EventType      string
+	Payload        string
+	Status         string
+	Attempts       int
+	ResponseStatus *int
+	LastError      *string
+	NextAttemptAt  *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Succeed records a successful attempt.
+func (d *WebhookDelivery) Succeed(status int, at time.Time) {
+	d.Attempts++
+	d.Status = DeliverySucceeded
+	d.ResponseStatus = &status
+	d.LastError = nil
+	d.NextAttemptAt = nil
+	d.UpdatedAt = at
+}
+
+// Fail records a failed attempt, status is zero when no response was received. The delivery is attempted again
+// at next, failing for good when next is nil.
+func (d *WebhookDelivery) Fail(status int, cause error, at time.Time, next *time.Time) {
+	d.Attempts++
+	d.Status = DeliveryPending
+	d.ResponseStatus = nil
+	if status != 0 {
+		d.ResponseStatus = &status
+	}
+	message := cause.Error()
+	d.LastError = &message
+	d.NextAttemptAt = next
+	if next == nil {
+		d.Status = DeliveryFailed
+	}
+	d.UpdatedAt = at
+}
+
+func (d *WebhookDelivery) Out() *types.WebhookDeliveryOutput {
+	output := &types.WebhookDeliveryOutput{
+		ID:             d.ID,
+		WebhookID:      d.WebhookID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Payload:        rawJSON(&d.Payload),
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt.String(),
+		UpdatedAt:      d.UpdatedAt.String(),
+	}
+	if d.NextAttemptAt != nil {
+		nextAttemptAt := d.NextAttemptAt.String()
+		output.NextAttemptAt = &nextAttemptAt
+	}
+	return output
+}
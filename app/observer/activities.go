@@ -9,54 +9,83 @@ const (
 	DefaultNamespace = "default"
 	DefaultSubsystem = "activities"
 	LabelCategory    = "category"
+	LabelTag         = "tag"
 )
 
 type ActivitiesObserver interface {
-	Count(category string)
-	DurationOf(category string, startedAt time.Time)
+	Count(category string, tags ...string)
+	DurationOf(category string, duration time.Duration)
+}
+
+// Option configures the activities observer.
+type Option func(o *activitiesObserver)
+
+// WithTagLabel adds the tag label to the counter, activities are counted once for each of their tags and with an
+// empty tag when untagged, so the counter sums by category no longer match the number of activities. Every tag
+// becomes a label value, leave it off when tags are unbounded, like ticket numbers.
+func WithTagLabel() Option {
+	return func(o *activitiesObserver) {
+		o.tagLabel = true
+	}
 }
 
 type activitiesObserver struct {
 	counter  *prometheus.CounterVec
 	duration *prometheus.SummaryVec
+	tagLabel bool
 }
 
-func NewActivitiesObserver() ActivitiesObserver {
-
-	var (
-		counter = prometheus.NewCounterVec(prometheus.CounterOpts{
-			Namespace: DefaultNamespace,
-			Subsystem: DefaultSubsystem,
-			Name:      "counter",
-			Help:      "Counter of activities by category",
-		}, []string{LabelCategory})
-
-		duration = prometheus.NewSummaryVec(prometheus.SummaryOpts{
-			Namespace:  DefaultNamespace,
-			Subsystem:  DefaultSubsystem,
-			Name:       "duration",
-			Help:       "Duration of activities by category",
-			Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
-		}, []string{LabelCategory})
-	)
-
-	prometheus.MustRegister(counter)
-	prometheus.MustRegister(duration)
-
-	return &activitiesObserver{
-		counter:  counter,
-		duration: duration,
+func NewActivitiesObserver(options ...Option) ActivitiesObserver {
+
+	o := new(activitiesObserver)
+	for _, option := range options {
+		option(o)
+	}
+
+	counterLabels := []string{LabelCategory}
+	if o.tagLabel {
+		counterLabels = append(counterLabels, LabelTag)
 	}
+
+	o.counter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: DefaultNamespace,
+		Subsystem: DefaultSubsystem,
+		Name:      "counter",
+		Help:      "Counter of activities by category",
+	}, counterLabels)
+
+	o.duration = prometheus.NewSummaryVec(prometheus.SummaryOpts{
+		Namespace:  DefaultNamespace,
+		Subsystem:  DefaultSubsystem,
+		Name:       "duration",
+		Help:       "Duration of activities by category",
+		Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+	}, []string{LabelCategory})
+
+	prometheus.MustRegister(o.counter)
+	prometheus.MustRegister(o.duration)
+
+	return o
 }
 
-func (o *activitiesObserver) Count(category string) {
-	o.counter.WithLabelValues(category).Inc()
+func (o *activitiesObserver) Count(category string, tags ...string) {
+	if !o.tagLabel {
+		o.counter.WithLabelValues(category).Inc()
+		return
+	}
+	if len(tags) == 0 {
+		o.counter.WithLabelValues(category, "").Inc()
+		return
+	}
+	for _, tag := range tags {
+		o.counter.WithLabelValues(category, tag).Inc()
+	}
 }
 
-func (o *activitiesObserver) DurationOf(category string, startedAt time.Time) {
-	o.duration.WithLabelValues(category).Observe(ms(startedAt))
+func (o *activitiesObserver) DurationOf(category string, duration time.Duration) {
+	o.duration.WithLabelValues(category).Observe(ms(duration))
 }
 
-func ms(startAt time.Time) float64 {
-	return float64(time.Since(startAt).Nanoseconds()) / 1e6
+func ms(duration time.Duration) float64 {
+	return float64(duration.Nanoseconds()) / 1e6
 }
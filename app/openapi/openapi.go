@@ -0,0 +1,351 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version is the version of the OpenAPI specification the documents follow.
+const Version = "3.0.3"
+
+const (
+	InPath   = "path"
+	InQuery  = "query"
+	InHeader = "header"
+)
+
+var (
+	pathParams = regexp.MustCompile(`{([^}:]+)(:[^}]+)?}`)
+	rawMessage = reflect.TypeOf(json.RawMessage{})
+)
+
+// Document is an OpenAPI document, holding the parts of the specification the api uses.
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Security   []map[string][]string `json:"security,omitempty"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem holds the operations of a path by lower case method.
+type PathItem map[string]*Operation
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme,omitempty"`
+	Name        string `json:"name,omitempty"`
+	In          string `json:"in,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type Operation struct {
+	Tags        []string               `json:"tags,omitempty"`
+	Summary     string                 `json:"summary"`
+	OperationID string                 `json:"operationId"`
+	Parameters  []*Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody           `json:"requestBody,omitempty"`
+	Responses   map[string]*Response   `json:"responses"`
+	Security    *[]map[string][]string `json:"security,omitempty"`
+
+	document *Document
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// String, Integer and Boolean return the schemas of the scalar parameters.
+func String(enum ...string) *Schema { return &Schema{Type: "string", Enum: enum} }
+func Integer() *Schema              { return &Schema{Type: "integer", Format: "int64"} }
+func Boolean() *Schema              { return &Schema{Type: "boolean"} }
+
+// Array returns the schema of a list of items.
+func Array(items *Schema) *Schema { return &Schema{Type: "array", Items: items} }
+
+// Binary returns the schema of a raw body, like a file.
+func Binary() *Schema { return &Schema{Type: "string", Format: "binary"} }
+
+// New returns an empty document.
+func New(title, description, version string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: title, Description: description, Version: version},
+		Paths:   make(map[string]PathItem),
+		Components: Components{
+			Schemas:         make(map[string]*Schema),
+			SecuritySchemes: make(map[string]*SecurityScheme),
+		},
+	}
+}
+
+// SchemaOf returns the schema of the json encoding of the value, named structs become components
+// referenced by the schema.
+func (d *Document) SchemaOf(value any) *Schema {
+	return d.schemaOf(reflect.TypeOf(value))
+}
+
+func (d *Document) schemaOf(t reflect.Type) *Schema {
+	if t == rawMessage {
+		return &Schema{Description: "any json value"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := d.schemaOf(t.Elem())
+		if schema.Ref != "" {
+			return schema
+		}
+		schema.Nullable = true
+		return schema
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		return Array(d.schemaOf(t.Elem()))
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.objectOf(t)
+		}
+		if _, ok := d.Components.Schemas[t.Name()]; !ok {
+			// registered before walking the fields, so recursive types end on the reference
+			d.Components.Schemas[t.Name()] = &Schema{}
+			*d.Components.Schemas[t.Name()] = *d.objectOf(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	default:
+		return &Schema{}
+	}
+}
+
+// objectOf returns the schema of the fields of a struct, embedded structs are inlined as encoding/json does.
+func (d *Document) objectOf(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for property, value := range d.objectOf(embedded).Properties {
+					schema.Properties[property] = value
+				}
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = d.schemaOf(field.Type)
+	}
+	return schema
+}
+
+// Operation adds the operation of the method on the path, the path may hold mux variables,
+// documented as path parameters.
+func (d *Document) Operation(method, path, tag, summary string) *Operation {
+	var (
+		template   = pathParams.ReplaceAllString(path, "{$1}")
+		parameters []*Parameter
+	)
+	for _, match := range pathParams.FindAllStringSubmatch(path, -1) {
+		name := match[1]
+		schema := String()
+		if name == "id" || strings.HasSuffix(name, "_id") {
+			schema = Integer()
+		}
+		parameters = append(parameters, &Parameter{Name: name, In: InPath, Required: true, Schema: schema})
+	}
+	operation := &Operation{
+		Tags:        []string{tag},
+		Summary:     summary,
+		OperationID: operationID(method, template),
+		Parameters:  parameters,
+		Responses:   make(map[string]*Response),
+		document:    d,
+	}
+	item, ok := d.Paths[template]
+	if !ok {
+		item = make(PathItem)
+		d.Paths[template] = item
+	}
+	item[strings.ToLower(method)] = operation
+	return operation
+}
+
+// Has tells whether the document describes the method on the path, given as a mux path template.
+func (d *Document) Has(method, path string) bool {
+	_, ok := d.Paths[pathParams.ReplaceAllString(path, "{$1}")][strings.ToLower(method)]
+	return ok
+}
+
+// operationID returns a camel case id of the operation, like getActivitiesId for GET /activities/{id}.
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return !isAlphanumeric(r) }) {
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+	return id
+}
+
+func isAlphanumeric(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
+
+// Query adds a query parameter.
+func (o *Operation) Query(name string, schema *Schema, description string) *Operation {
+	o.Parameters = append(o.Parameters, &Parameter{Name: name, In: InQuery, Description: description, Schema: schema})
+	return o
+}
+
+// Header adds a request header.
+func (o *Operation) Header(name string, description string) *Operation {
+	o.Parameters = append(o.Parameters, &Parameter{Name: name, In: InHeader, Description: description, Schema: String()})
+	return o
+}
+
+// Body sets the request body, accepted as any of the media types.
+func (o *Operation) Body(schema *Schema, mediaTypes ...string) *Operation {
+	if o.RequestBody == nil {
+		o.RequestBody = &RequestBody{Required: true, Content: make(map[string]*MediaType)}
+	}
+	for _, mediaType := range mediaTypes {
+		o.RequestBody.Content[mediaType] = &MediaType{Schema: schema}
+	}
+	return o
+}
+
+// JSONBody sets the json request body to the schema of the value.
+func (o *Operation) JSONBody(value any) *Operation {
+	return o.Body(o.document.SchemaOf(value), "application/json")
+}
+
+// Respond adds the response of the status, holding the schema as any of the media types.
+func (o *Operation) Respond(status int, description string, schema *Schema, mediaTypes ...string) *Operation {
+	response := o.response(status, description)
+	for _, mediaType := range mediaTypes {
+		if response.Content == nil {
+			response.Content = make(map[string]*MediaType)
+		}
+		response.Content[mediaType] = &MediaType{Schema: schema}
+	}
+	return o
+}
+
+// JSON adds the json response of the status with the schema of the value.
+func (o *Operation) JSON(status int, description string, value any) *Operation {
+	return o.Respond(status, description, o.document.SchemaOf(value), "application/json")
+}
+
+// Empty adds a response without body.
+func (o *Operation) Empty(status int, description string) *Operation {
+	o.response(status, description)
+	return o
+}
+
+// ResponseHeader documents a header of the response of the status.
+func (o *Operation) ResponseHeader(status int, name, description string, schema *Schema) *Operation {
+	response := o.response(status, "")
+	if response.Headers == nil {
+		response.Headers = make(map[string]*Header)
+	}
+	response.Headers[name] = &Header{Description: description, Schema: schema}
+	return o
+}
+
+// Errors adds the error responses of the statuses, holding the value, described by their status text.
+func (o *Operation) Errors(value any, statuses ...int) *Operation {
+	for _, status := range statuses {
+		o.JSON(status, http.StatusText(status), value)
+	}
+	return o
+}
+
+// Open leaves the operation open, overriding the security of the document.
+func (o *Operation) Open() *Operation {
+	o.Security = &[]map[string][]string{}
+	return o
+}
+
+func (o *Operation) response(status int, description string) *Response {
+	key := strconv.Itoa(status)
+	response, ok := o.Responses[key]
+	if !ok {
+		response = &Response{Description: description}
+		o.Responses[key] = response
+	}
+	if response.Description == "" {
+		response.Description = description
+	}
+	if response.Description == "" {
+		response.Description = http.StatusText(status)
+	}
+	return response
+}
@@ -6,16 +6,35 @@ import (
 	"fmt"
 	"github.com/ungame/command-time-track/app/ioext"
 	"github.com/ungame/command-time-track/app/models"
+	"strings"
+	"time"
 )
 
+const intervalsBatchSize = 500
+
 type ActivitiesRepository interface {
 	Create(ctx context.Context, activity *models.Activity) (int64, error)
 	Update(ctx context.Context, activity *models.Activity) (int64, error)
+	// Delete moves the activity to the trash, hiding it from every method but Trash, GetDeleted, Restore and Purge.
 	Delete(ctx context.Context, id int64) (int64, error)
+	// Restore takes the activity out of the trash.
+	Restore(ctx context.Context, id int64) (int64, error)
+	// Purge deletes the activity in the trash for good.
+	Purge(ctx context.Context, id int64) (int64, error)
+	// Trash returns the activities in the trash, the last deleted first.
+	Trash(ctx context.Context) ([]*models.Activity, error)
+	GetDeleted(ctx context.Context, id int64) (*models.Activity, error)
 	Get(ctx context.Context, id int64) (*models.Activity, error)
 	GetAll(ctx context.Context) ([]*models.Activity, error)
 	Search(ctx context.Context, term string) ([]*models.Activity, error)
 	GetByStatus(ctx context.Context, status models.Status) ([]*models.Activity, error)
+	// LockStarted locks the started activities of the owner until the transaction in the context ends, so
+	// transactions starting activities of the owner run one at a time. Call it first in the transaction, its reads
+	// then see the activities started by the transactions it waited for.
+	LockStarted(ctx context.Context) error
+	Find(ctx context.Context, query *ActivitiesQuery) ([]*models.Activity, error)
+	// SetInvoice sets the invoice of the activities not invoiced yet, returning how many were set.
+	SetInvoice(ctx context.Context, invoiceID int64, ids []int64) (int64, error)
 }
 
 type activitiesRepository struct {
@@ -41,140 +60,485 @@ func (r *activitiesRepository) Close() {
 }
 
 func (r *activitiesRepository) Create(ctx context.Context, activity *models.Activity) (int64, error) {
-	result, err := r.createStmt.ExecContext(
-		ctx,
-		activity.Category,
-		activity.Description,
-		activity.StartedAt,
-		activity.UpdatedAt,
-	)
+	if activity.Status == "" {
+		activity.Status = models.StatusStarted
+	}
+	setOwner(ctx, activity)
+	var id int64
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.StmtContext(ctx, r.createStmt).ExecContext(
+			ctx,
+			activity.Category,
+			activity.Description,
+			activity.Status,
+			activity.StartedAt,
+			activity.UpdatedAt,
+			activity.FinishedAt,
+			activity.ProjectID,
+			activity.HourlyRate,
+			activity.InvoiceID,
+			activity.OwnerID,
+		)
+		if err != nil {
+			return err
+		}
+		if id, err = result.LastInsertId(); err != nil {
+			return err
+		}
+		activity.ID = id
+		if err = saveIntervals(ctx, tx, activity); err != nil {
+			return err
+		}
+		return saveTags(ctx, tx, activity)
+	})
+	return id, err
+}
+
+func (r *activitiesRepository) Update(ctx context.Context, activity *models.Activity) (int64, error) {
+	var rows int64
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.StmtContext(ctx, r.updateStmt).ExecContext(
+			ctx,
+			activity.Category,
+			activity.Description,
+			activity.Status,
+			activity.StartedAt,
+			activity.UpdatedAt,
+			activity.FinishedAt,
+			activity.ProjectID,
+			activity.HourlyRate,
+			activity.ID,
+			OwnerFrom(ctx),
+			OwnerFrom(ctx),
+		)
+		if err != nil {
+			return err
+		}
+		if rows, err = result.RowsAffected(); err != nil || rows == 0 {
+			return err
+		}
+		if err = saveIntervals(ctx, tx, activity); err != nil {
+			return err
+		}
+		return saveTags(ctx, tx, activity)
+	})
+	return rows, err
+}
+
+func (r *activitiesRepository) Delete(ctx context.Context, id int64) (int64, error) {
+	stmt := r.deleteStmt
+	if tx := txFrom(ctx); tx != nil {
+		stmt = tx.StmtContext(ctx, stmt)
+	}
+	result, err := stmt.ExecContext(ctx, time.Now().UTC(), id, OwnerFrom(ctx), OwnerFrom(ctx))
 	if err != nil {
 		return 0, err
 	}
-	return result.LastInsertId()
+	return result.RowsAffected()
 }
 
-func (r *activitiesRepository) Update(ctx context.Context, activity *models.Activity) (int64, error) {
-	result, err := r.updateStmt.ExecContext(
-		ctx,
-		activity.Category,
-		activity.Description,
-		activity.Status,
-		activity.UpdatedAt,
-		activity.FinishedAt,
-		activity.ID,
-	)
+func (r *activitiesRepository) Restore(ctx context.Context, id int64) (int64, error) {
+	result, err := queryerFrom(ctx, r.conn).ExecContext(ctx, restoreActivityQuery, id, OwnerFrom(ctx), OwnerFrom(ctx))
 	if err != nil {
 		return 0, err
 	}
 	return result.RowsAffected()
 }
 
-func (r *activitiesRepository) Delete(ctx context.Context, id int64) (int64, error) {
-	result, err := r.deleteStmt.ExecContext(ctx, id)
+// Purge relies on the foreign keys to delete the intervals and tags of the activity.
+func (r *activitiesRepository) Purge(ctx context.Context, id int64) (int64, error) {
+	result, err := queryerFrom(ctx, r.conn).ExecContext(ctx, purgeActivityQuery, id, OwnerFrom(ctx), OwnerFrom(ctx))
 	if err != nil {
 		return 0, err
 	}
 	return result.RowsAffected()
 }
 
+func (r *activitiesRepository) Trash(ctx context.Context) ([]*models.Activity, error) {
+	var where conditions
+	where.add(`deleted_at is not null`)
+	where.scope(ctx)
+	query := `select ` + activityColumns + ` from activities` + where.String() + ` order by deleted_at desc, id desc`
+	return r.queryActivities(ctx, query, where.args...)
+}
+
+func (r *activitiesRepository) GetDeleted(ctx context.Context, id int64) (*models.Activity, error) {
+	var where conditions
+	where.add(`id = ?`, id)
+	where.add(`deleted_at is not null`)
+	where.scope(ctx)
+	return r.get(ctx, where)
+}
+
+func (r *activitiesRepository) SetInvoice(ctx context.Context, invoiceID int64, ids []int64) (int64, error) {
+	var rows int64
+	for start := 0; start < len(ids); start += intervalsBatchSize {
+		end := start + intervalsBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		args := make([]any, 0, end-start+3)
+		args = append(args, invoiceID, OwnerFrom(ctx), OwnerFrom(ctx))
+		for _, id := range ids[start:end] {
+			args = append(args, id)
+		}
+		result, err := queryerFrom(ctx, r.conn).ExecContext(ctx, setActivityInvoiceQuery+`(`+placeholders(end-start)+`)`, args...)
+		if err != nil {
+			return rows, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return rows, err
+		}
+		rows += affected
+	}
+	return rows, nil
+}
+
 func (r *activitiesRepository) Get(ctx context.Context, id int64) (*models.Activity, error) {
+	var where conditions
+	where.add(`id = ?`, id)
+	where.visible(ctx)
+	return r.get(ctx, where)
+}
+
+func (r *activitiesRepository) get(ctx context.Context, where conditions) (*models.Activity, error) {
 	var (
 		activity = new(models.Activity)
-		query    = `select * from activities where id = ?`
-		row      = r.conn.QueryRowContext(ctx, query, id)
-	)
-	err := row.Scan(
-		&activity.ID,
-		&activity.Category,
-		&activity.Description,
-		&activity.Status,
-		&activity.StartedAt,
-		&activity.UpdatedAt,
-		&activity.FinishedAt,
+		query    = `select ` + activityColumns + ` from activities` + where.String()
+		row      = queryerFrom(ctx, r.conn).QueryRowContext(ctx, query, where.args...)
 	)
-	return activity, err
+	err := scanActivity(row, activity)
+	if err != nil {
+		return activity, notFound(err)
+	}
+	return activity, r.loadRelations(ctx, []*models.Activity{activity})
 }
 
 func (r *activitiesRepository) GetAll(ctx context.Context) ([]*models.Activity, error) {
-	query := `select * from activities`
-	rows, err := r.conn.QueryContext(ctx, query)
+	var where conditions
+	where.visible(ctx)
+	query := `select ` + activityColumns + ` from activities` + where.String() + ` order by id`
+	return r.queryActivities(ctx, query, where.args...)
+}
+
+func (r *activitiesRepository) GetByStatus(ctx context.Context, status models.Status) ([]*models.Activity, error) {
+	var where conditions
+	where.add(`status = ?`, status)
+	where.visible(ctx)
+	query := `select ` + activityColumns + ` from activities` + where.String() + ` order by id`
+	return r.queryActivities(ctx, query, where.args...)
+}
+
+func (r *activitiesRepository) LockStarted(ctx context.Context) error {
+	// locking the started activities alone lets a concurrent transaction insert one when there are none, the row
+	// of the owner is locked instead. SQLite has no select for update, the update takes the lock on both.
+	owner := OwnerFrom(ctx)
+	if owner == 0 {
+		owner = models.DefaultUserID
+	}
+	_, err := queryerFrom(ctx, r.conn).ExecContext(ctx, lockUserQuery, owner)
+	return err
+}
+
+func (r *activitiesRepository) Search(ctx context.Context, term string) ([]*models.Activity, error) {
+	var where conditions
+	where.add(`(category like ? or description like ?)`, like(term), like(term))
+	where.visible(ctx)
+	query := `select ` + activityColumns + ` from activities` + where.String() + ` order by id`
+	return r.queryActivities(ctx, query, where.args...)
+}
+
+func (r *activitiesRepository) Find(ctx context.Context, q *ActivitiesQuery) ([]*models.Activity, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	var (
+		where     conditions
+		direction = q.Order
+		operator  = ">"
+	)
+
+	where.visible(ctx)
+
+	if q.From != nil {
+		where.add(`(finished_at is null or finished_at > ?)`, *q.From)
+	}
+	if q.To != nil {
+		where.add(`started_at < ?`, *q.To)
+	}
+	if q.Category != "" {
+		where.add(`category = ?`, q.Category)
+	}
+	if q.ProjectID != nil {
+		where.add(`project_id = ?`, *q.ProjectID)
+	}
+	for _, tag := range q.Tags {
+		where.add(activityHasTagCondition, tag)
+	}
+	if q.Status != nil {
+		where.add(`status = ?`, *q.Status)
+	}
+	if q.Invoiced != nil {
+		if *q.Invoiced {
+			where.add(`invoice_id is not null`)
+		} else {
+			where.add(`invoice_id is null`)
+		}
+	}
+	if q.Order == OrderDesc {
+		operator = "<"
+	}
+	if q.After != nil {
+		value, err := q.After.value()
+		if err != nil {
+			return nil, err
+		}
+		if q.Sort == SortID {
+			where.add(`id `+operator+` ?`, q.After.ID)
+		} else {
+			where.add(`(`+q.Sort+` `+operator+` ? or (`+q.Sort+` = ? and id `+operator+` ?))`, value, value, q.After.ID)
+		}
+	}
+
+	query := `select ` + activityColumns + ` from activities` + where.String() + ` order by `
+	if q.Sort != SortID {
+		query += q.Sort + ` ` + direction + `, `
+	}
+	query += `id ` + direction
+
+	if q.Limit > 0 {
+		query += ` limit ?`
+		where.args = append(where.args, q.Limit)
+	}
+
+	return r.queryActivities(ctx, query, where.args...)
+}
+
+func (r *activitiesRepository) queryActivities(ctx context.Context, query string, args ...any) ([]*models.Activity, error) {
+	rows, err := queryerFrom(ctx, r.conn).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
-	defer ioext.Close(rows)
 	activities := make([]*models.Activity, 0, 10)
 	for rows.Next() {
 		var activity models.Activity
-		err = rows.Scan(
-			&activity.ID,
-			&activity.Category,
-			&activity.Description,
-			&activity.Status,
-			&activity.StartedAt,
-			&activity.UpdatedAt,
-			&activity.FinishedAt,
-		)
+		err = scanActivity(rows, &activity)
 		if err != nil {
+			ioext.Close(rows)
 			return activities, err
 		}
 		activities = append(activities, &activity)
 	}
-	return activities, nil
+	ioext.Close(rows)
+	if err = rows.Err(); err != nil {
+		return activities, err
+	}
+	return activities, r.loadRelations(ctx, activities)
 }
 
-func (r *activitiesRepository) GetByStatus(ctx context.Context, status models.Status) ([]*models.Activity, error) {
-	query := `select * from activities where status = ?`
-	rows, err := r.conn.QueryContext(ctx, query, status)
+// loadRelations loads the intervals and tags of the activities.
+func (r *activitiesRepository) loadRelations(ctx context.Context, activities []*models.Activity) error {
+	byID := make(map[int64]*models.Activity, len(activities))
+	for _, activity := range activities {
+		activity.Intervals = make([]*models.Interval, 0, 1)
+		activity.Tags = make([]string, 0)
+		byID[activity.ID] = activity
+	}
+	for start := 0; start < len(activities); start += intervalsBatchSize {
+		end := start + intervalsBatchSize
+		if end > len(activities) {
+			end = len(activities)
+		}
+		args := make([]any, 0, end-start)
+		for _, activity := range activities[start:end] {
+			args = append(args, activity.ID)
+		}
+		if err := r.loadIntervals(ctx, byID, args); err != nil {
+			return err
+		}
+		if err := r.loadTags(ctx, byID, args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *activitiesRepository) loadIntervals(ctx context.Context, byID map[int64]*models.Activity, ids []any) error {
+	query := `select ` + intervalColumns + ` from activity_intervals where activity_id in (` + placeholders(len(ids)) + `) order by started_at, id`
+	rows, err := queryerFrom(ctx, r.conn).QueryContext(ctx, query, ids...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer ioext.Close(rows)
-	activities := make([]*models.Activity, 0, 10)
 	for rows.Next() {
-		var activity models.Activity
-		err = rows.Scan(
-			&activity.ID,
-			&activity.Category,
-			&activity.Description,
-			&activity.Status,
-			&activity.StartedAt,
-			&activity.UpdatedAt,
-			&activity.FinishedAt,
-		)
+		interval := new(models.Interval)
+		err = rows.Scan(&interval.ID, &interval.ActivityID, &interval.StartedAt, &interval.FinishedAt)
 		if err != nil {
-			return activities, err
+			return err
 		}
-		activities = append(activities, &activity)
+		activity := byID[interval.ActivityID]
+		activity.Intervals = append(activity.Intervals, interval)
 	}
-	return activities, nil
+	return rows.Err()
 }
 
-func (r *activitiesRepository) Search(ctx context.Context, term string) ([]*models.Activity, error) {
-	query := `select * from activities where category like ? or description like ?`
-	rows, err := r.conn.QueryContext(ctx, query, like(term), like(term))
+func (r *activitiesRepository) loadTags(ctx context.Context, byID map[int64]*models.Activity, ids []any) error {
+	query := `select at.activity_id, t.name from activity_tags at join tags t on t.id = at.tag_id where at.activity_id in (` + placeholders(len(ids)) + `) order by t.name`
+	rows, err := queryerFrom(ctx, r.conn).QueryContext(ctx, query, ids...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer ioext.Close(rows)
-	activities := make([]*models.Activity, 0, 10)
 	for rows.Next() {
-		var activity models.Activity
-		err = rows.Scan(
-			&activity.ID,
-			&activity.Category,
-			&activity.Description,
-			&activity.Status,
-			&activity.StartedAt,
-			&activity.UpdatedAt,
-			&activity.FinishedAt,
+		var (
+			id  int64
+			tag string
 		)
-		if err != nil {
-			return activities, err
+		if err = rows.Scan(&id, &tag); err != nil {
+			return err
 		}
-		activities = append(activities, &activity)
+		activity := byID[id]
+		activity.Tags = append(activity.Tags, tag)
+	}
+	return rows.Err()
+}
+
+// withTx runs fn in the transaction of the context, if any, otherwise in a new transaction.
+func (r *activitiesRepository) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	if tx := txFrom(ctx); tx != nil {
+		return fn(tx)
 	}
-	return activities, nil
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err = fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func saveIntervals(ctx context.Context, tx *sql.Tx, activity *models.Activity) error {
+	keep := make([]any, 0, len(activity.Intervals)+1)
+	keep = append(keep, activity.ID)
+	for _, interval := range activity.Intervals {
+		interval.ActivityID = activity.ID
+		if interval.ID == 0 {
+			result, err := tx.ExecContext(ctx, insertIntervalQuery, interval.ActivityID, interval.StartedAt, interval.FinishedAt)
+			if err != nil {
+				return err
+			}
+			if interval.ID, err = result.LastInsertId(); err != nil {
+				return err
+			}
+		} else {
+			_, err := tx.ExecContext(ctx, updateIntervalQuery, interval.StartedAt, interval.FinishedAt, interval.ID, interval.ActivityID)
+			if err != nil {
+				return err
+			}
+		}
+		keep = append(keep, interval.ID)
+	}
+	query := deleteIntervalsQuery
+	if len(keep) > 1 {
+		query += ` and id not in (` + placeholders(len(keep)-1) + `)`
+	}
+	_, err := tx.ExecContext(ctx, query, keep...)
+	return err
+}
+
+// saveTags replaces the tags of the activity, creating the tags used for the first time.
+func saveTags(ctx context.Context, tx *sql.Tx, activity *models.Activity) error {
+	if _, err := tx.ExecContext(ctx, deleteActivityTagsQuery, activity.ID); err != nil {
+		return err
+	}
+	for _, tag := range activity.Tags {
+		var id int64
+		err := tx.QueryRowContext(ctx, selectTagQuery, tag).Scan(&id)
+		if err == sql.ErrNoRows {
+			result, err := tx.ExecContext(ctx, insertTagQuery, tag)
+			if err != nil {
+				return err
+			}
+			if id, err = result.LastInsertId(); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+		if _, err = tx.ExecContext(ctx, insertActivityTagQuery, activity.ID, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type conditions struct {
+	clauses []string
+	args    []any
+}
+
+func (c *conditions) add(clause string, args ...any) {
+	c.clauses = append(c.clauses, clause)
+	c.args = append(c.args, args...)
+}
+
+// scope restricts the activities to the owner of the context, if any.
+func (c *conditions) scope(ctx context.Context) {
+	if owner := OwnerFrom(ctx); owner != 0 {
+		c.add(`owner_id = ?`, owner)
+	}
+}
+
+// visible restricts the activities to the ones of the owner of the context out of the trash.
+func (c *conditions) visible(ctx context.Context) {
+	c.add(`deleted_at is null`)
+	c.scope(ctx)
+}
+
+func (c *conditions) String() string {
+	if len(c.clauses) == 0 {
+		return ""
+	}
+	return ` where ` + strings.Join(c.clauses, ` and `)
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanActivity(row scanner, activity *models.Activity) error {
+	return row.Scan(
+		&activity.ID,
+		&activity.Category,
+		&activity.Description,
+		&activity.Status,
+		&activity.StartedAt,
+		&activity.UpdatedAt,
+		&activity.FinishedAt,
+		&activity.ProjectID,
+		&activity.HourlyRate,
+		&activity.InvoiceID,
+		&activity.OwnerID,
+		&activity.DeletedAt,
+	)
+}
+
+// setOwner sets the owner of a new activity: the owner of the context, otherwise the default user.
+func setOwner(ctx context.Context, activity *models.Activity) {
+	if owner := OwnerFrom(ctx); owner != 0 {
+		activity.OwnerID = owner
+	}
+	if activity.OwnerID == 0 {
+		activity.OwnerID = models.DefaultUserID
+	}
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
 }
 
 func like(s string) string {
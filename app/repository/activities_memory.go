@@ -0,0 +1,394 @@
+package repository
+
+import (
+	"context"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/pointer"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryActivitiesRepository struct {
+	mutex            sync.RWMutex
+	sequence         int64
+	intervalSequence int64
+	activities       map[int64]*models.Activity
+}
+
+func NewMemoryActivitiesRepository() ActivitiesRepository {
+	return &memoryActivitiesRepository{activities: make(map[int64]*models.Activity)}
+}
+
+func (r *memoryActivitiesRepository) Create(ctx context.Context, activity *models.Activity) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.sequence++
+	r.keep(ctx, r.sequence)
+
+	if activity.Status == "" {
+		activity.Status = models.StatusStarted
+	}
+	setOwner(ctx, activity)
+	activity.ID = r.sequence
+	r.assignIntervals(activity)
+
+	created := cloneActivity(activity)
+
+	r.activities[created.ID] = created
+
+	return created.ID, nil
+}
+
+func (r *memoryActivitiesRepository) Update(ctx context.Context, activity *models.Activity) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existing, ok := r.activities[activity.ID]
+	if !ok || !visible(ctx, existing) {
+		return 0, nil
+	}
+
+	r.keep(ctx, activity.ID)
+
+	r.assignIntervals(activity)
+
+	updated := cloneActivity(activity)
+	updated.InvoiceID = existing.InvoiceID
+	updated.OwnerID = existing.OwnerID
+	r.activities[activity.ID] = updated
+
+	return 1, nil
+}
+
+func (r *memoryActivitiesRepository) SetInvoice(ctx context.Context, invoiceID int64, ids []int64) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var rows int64
+	for _, id := range ids {
+		existing, ok := r.activities[id]
+		if !ok || existing.InvoiceID != nil || !visible(ctx, existing) {
+			continue
+		}
+		r.keep(ctx, id)
+		invoiced := cloneActivity(existing)
+		invoiced.InvoiceID = pointer.New(invoiceID)
+		r.activities[id] = invoiced
+		rows++
+	}
+
+	return rows, nil
+}
+
+// keep registers the current state of the activity to restore it if the transaction in the context is
+// rolled back. Stored activities are replaced on change, never modified.
+func (r *memoryActivitiesRepository) keep(ctx context.Context, id int64) {
+	previous, existed := r.activities[id]
+	onRollback(ctx, func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		if existed {
+			r.activities[id] = previous
+		} else {
+			delete(r.activities, id)
+		}
+	})
+}
+
+func (r *memoryActivitiesRepository) assignIntervals(activity *models.Activity) {
+	for _, interval := range activity.Intervals {
+		interval.ActivityID = activity.ID
+		if interval.ID == 0 {
+			r.intervalSequence++
+			interval.ID = r.intervalSequence
+		}
+	}
+}
+
+func (r *memoryActivitiesRepository) Delete(ctx context.Context, id int64) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existing, ok := r.activities[id]
+	if !ok || !visible(ctx, existing) {
+		return 0, nil
+	}
+
+	r.keep(ctx, id)
+
+	deleted := cloneActivity(existing)
+	deleted.DeletedAt = pointer.New(time.Now().UTC())
+	r.activities[id] = deleted
+
+	return 1, nil
+}
+
+func (r *memoryActivitiesRepository) Restore(ctx context.Context, id int64) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existing, ok := r.activities[id]
+	if !ok || existing.DeletedAt == nil || !owns(ctx, existing) {
+		return 0, nil
+	}
+
+	r.keep(ctx, id)
+
+	restored := cloneActivity(existing)
+	restored.DeletedAt = nil
+	r.activities[id] = restored
+
+	return 1, nil
+}
+
+func (r *memoryActivitiesRepository) Purge(ctx context.Context, id int64) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existing, ok := r.activities[id]
+	if !ok || existing.DeletedAt == nil || !owns(ctx, existing) {
+		return 0, nil
+	}
+
+	r.keep(ctx, id)
+
+	delete(r.activities, id)
+
+	return 1, nil
+}
+
+func (r *memoryActivitiesRepository) Trash(ctx context.Context) ([]*models.Activity, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	activities := make([]*models.Activity, 0, 10)
+	for _, activity := range r.activities {
+		if activity.DeletedAt != nil && owns(ctx, activity) {
+			activities = append(activities, cloneActivity(activity))
+		}
+	}
+
+	sort.Slice(activities, func(i, j int) bool {
+		if !activities[i].DeletedAt.Equal(*activities[j].DeletedAt) {
+			return activities[i].DeletedAt.After(*activities[j].DeletedAt)
+		}
+		return activities[i].ID > activities[j].ID
+	})
+
+	return activities, nil
+}
+
+func (r *memoryActivitiesRepository) GetDeleted(ctx context.Context, id int64) (*models.Activity, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	activity, ok := r.activities[id]
+	if !ok || activity.DeletedAt == nil || !owns(ctx, activity) {
+		return new(models.Activity), ErrNotFound
+	}
+
+	return cloneActivity(activity), nil
+}
+
+func (r *memoryActivitiesRepository) Get(ctx context.Context, id int64) (*models.Activity, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	activity, ok := r.activities[id]
+	if !ok || !visible(ctx, activity) {
+		return new(models.Activity), ErrNotFound
+	}
+
+	return cloneActivity(activity), nil
+}
+
+func (r *memoryActivitiesRepository) GetAll(ctx context.Context) ([]*models.Activity, error) {
+	return r.filter(ctx, func(*models.Activity) bool { return true }), nil
+}
+
+func (r *memoryActivitiesRepository) Search(ctx context.Context, term string) ([]*models.Activity, error) {
+	term = strings.ToLower(term)
+	return r.filter(ctx, func(activity *models.Activity) bool {
+		return strings.Contains(strings.ToLower(activity.Category), term) ||
+			strings.Contains(strings.ToLower(activity.Description), term)
+	}), nil
+}
+
+func (r *memoryActivitiesRepository) GetByStatus(ctx context.Context, status models.Status) ([]*models.Activity, error) {
+	return r.filter(ctx, func(activity *models.Activity) bool {
+		return activity.Status == status
+	}), nil
+}
+
+// LockStarted needs no lock, the memory transactions run one at a time.
+func (r *memoryActivitiesRepository) LockStarted(_ context.Context) error {
+	return nil
+}
+
+func (r *memoryActivitiesRepository) Find(ctx context.Context, q *ActivitiesQuery) ([]*models.Activity, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	var after any
+	if q.After != nil {
+		value, err := q.After.value()
+		if err != nil {
+			return nil, err
+		}
+		after = value
+	}
+
+	activities := r.filter(ctx, func(activity *models.Activity) bool {
+		if q.From != nil && activity.FinishedAt != nil && !activity.FinishedAt.After(*q.From) {
+			return false
+		}
+		if q.To != nil && !activity.StartedAt.Before(*q.To) {
+			return false
+		}
+		if q.Category != "" && activity.Category != q.Category {
+			return false
+		}
+		if q.ProjectID != nil && (activity.ProjectID == nil || *activity.ProjectID != *q.ProjectID) {
+			return false
+		}
+		if !activity.HasTags(q.Tags...) {
+			return false
+		}
+		if q.Status != nil && activity.Status != *q.Status {
+			return false
+		}
+		if q.Invoiced != nil && (activity.InvoiceID != nil) != *q.Invoiced {
+			return false
+		}
+		if q.After != nil {
+			order := compareSortKey(q.Sort, activity, after, q.After.ID)
+			if q.Order == OrderDesc {
+				order = -order
+			}
+			return order > 0
+		}
+		return true
+	})
+
+	sort.SliceStable(activities, func(i, j int) bool {
+		order := compareSortKey(q.Sort, activities[i], sortValue(q.Sort, activities[j]), activities[j].ID)
+		if q.Order == OrderDesc {
+			return order > 0
+		}
+		return order < 0
+	})
+
+	if q.Limit > 0 && len(activities) > q.Limit {
+		activities = activities[:q.Limit]
+	}
+
+	return activities, nil
+}
+
+func sortValue(sort string, activity *models.Activity) any {
+	switch sort {
+	case SortStartedAt:
+		return activity.StartedAt
+	case SortUpdatedAt:
+		return activity.UpdatedAt
+	case SortCategory:
+		return activity.Category
+	default:
+		return activity.ID
+	}
+}
+
+func compareSortKey(sort string, activity *models.Activity, value any, id int64) int {
+	order := 0
+	switch v := value.(type) {
+	case time.Time:
+		t := sortValue(sort, activity).(time.Time)
+		if t.Before(v) {
+			order = -1
+		} else if t.After(v) {
+			order = 1
+		}
+	case string:
+		order = strings.Compare(activity.Category, v)
+	}
+	if order != 0 {
+		return order
+	}
+	switch {
+	case activity.ID < id:
+		return -1
+	case activity.ID > id:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// filter returns the activities of the owner of the context out of the trash matching, sorted by id.
+func (r *memoryActivitiesRepository) filter(ctx context.Context, match func(activity *models.Activity) bool) []*models.Activity {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	activities := make([]*models.Activity, 0, 10)
+	for _, activity := range r.activities {
+		if visible(ctx, activity) && match(activity) {
+			activities = append(activities, cloneActivity(activity))
+		}
+	}
+
+	sort.Slice(activities, func(i, j int) bool {
+		return activities[i].ID < activities[j].ID
+	})
+
+	return activities
+}
+
+// owns reports whether the activity belongs to the owner of the context, any owner when not scoped.
+func owns(ctx context.Context, activity *models.Activity) bool {
+	owner := OwnerFrom(ctx)
+	return owner == 0 || activity.OwnerID == owner
+}
+
+// visible reports whether the activity belongs to the owner of the context and is out of the trash.
+func visible(ctx context.Context, activity *models.Activity) bool {
+	return activity.DeletedAt == nil && owns(ctx, activity)
+}
+
+func cloneActivity(activity *models.Activity) *models.Activity {
+	clone := *activity
+	if activity.FinishedAt != nil {
+		finishedAt := *activity.FinishedAt
+		clone.FinishedAt = &finishedAt
+	}
+	if activity.ProjectID != nil {
+		clone.ProjectID = pointer.New(*activity.ProjectID)
+	}
+	if activity.HourlyRate != nil {
+		clone.HourlyRate = pointer.New(*activity.HourlyRate)
+	}
+	if activity.InvoiceID != nil {
+		clone.InvoiceID = pointer.New(*activity.InvoiceID)
+	}
+	if activity.DeletedAt != nil {
+		clone.DeletedAt = pointer.New(*activity.DeletedAt)
+	}
+	clone.Tags = append(make([]string, 0, len(activity.Tags)), activity.Tags...)
+	sort.Strings(clone.Tags)
+	clone.Intervals = make([]*models.Interval, 0, len(activity.Intervals))
+	for _, interval := range activity.Intervals {
+		intervalClone := *interval
+		if interval.FinishedAt != nil {
+			finishedAt := *interval.FinishedAt
+			intervalClone.FinishedAt = &finishedAt
+		}
+		clone.Intervals = append(clone.Intervals, &intervalClone)
+	}
+	sort.SliceStable(clone.Intervals, func(i, j int) bool {
+		return clone.Intervals[i].StartedAt.Before(clone.Intervals[j].StartedAt)
+	})
+	return &clone
+}
@@ -4,18 +4,15 @@ import (
 	"context"
 	"github.com/ungame/command-time-track/app/models"
 	"github.com/ungame/command-time-track/app/pointer"
-	"github.com/ungame/command-time-track/db"
 	"sort"
 	"testing"
 	"time"
 )
 
-func TestActivitiesRepository(t *testing.T) {
+func testActivitiesRepository(t *testing.T, repo ActivitiesRepository) {
 
 	var (
 		ctx      = context.Background()
-		conn     = db.New()
-		repo     = NewActivitiesRepository(ctx, conn)
 		id       int64
 		err      error
 		activity *models.Activity
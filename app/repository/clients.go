@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/ungame/command-time-track/app/ioext"
+	"github.com/ungame/command-time-track/app/models"
+)
+
+type ClientsRepository interface {
+	Create(ctx context.Context, client *models.Client) (int64, error)
+	Update(ctx context.Context, client *models.Client) (int64, error)
+	Delete(ctx context.Context, id int64) (int64, error)
+	Get(ctx context.Context, id int64) (*models.Client, error)
+	GetAll(ctx context.Context) ([]*models.Client, error)
+}
+
+type clientsRepository struct {
+	conn *sql.DB
+}
+
+func NewClientsRepository(conn *sql.DB) ClientsRepository {
+	return &clientsRepository{conn: conn}
+}
+
+func (r *clientsRepository) Create(ctx context.Context, client *models.Client) (int64, error) {
+	result, err := queryerFrom(ctx, r.conn).ExecContext(ctx, insertClientQuery, client.Name, client.HourlyRate, client.CreatedAt, client.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	if client.ID, err = result.LastInsertId(); err != nil {
+		return 0, err
+	}
+	return client.ID, nil
+}
+
+func (r *clientsRepository) Update(ctx context.Context, client *models.Client) (int64, error) {
+	result, err := queryerFrom(ctx, r.conn).ExecContext(ctx, updateClientQuery, client.Name, client.HourlyRate, client.UpdatedAt, client.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *clientsRepository) Delete(ctx context.Context, id int64) (int64, error) {
+	result, err := queryerFrom(ctx, r.conn).ExecContext(ctx, deleteClientQuery, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *clientsRepository) Get(ctx context.Context, id int64) (*models.Client, error) {
+	client := new(models.Client)
+	row := queryerFrom(ctx, r.conn).QueryRowContext(ctx, `select `+clientColumns+` from clients where id = ?`, id)
+	return client, notFound(scanClient(row, client))
+}
+
+func (r *clientsRepository) GetAll(ctx context.Context) ([]*models.Client, error) {
+	rows, err := queryerFrom(ctx, r.conn).QueryContext(ctx, `select `+clientColumns+` from clients order by name, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer ioext.Close(rows)
+	clients := make([]*models.Client, 0, 10)
+	for rows.Next() {
+		client := new(models.Client)
+		if err = scanClient(rows, client); err != nil {
+			return clients, err
+		}
+		clients = append(clients, client)
+	}
+	return clients, rows.Err()
+}
+
+func scanClient(row scanner, client *models.Client) error {
+	return row.Scan(&client.ID, &client.Name, &client.HourlyRate, &client.CreatedAt, &client.UpdatedAt)
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/pointer"
+	"sort"
+	"sync"
+)
+
+type memoryClientsRepository struct {
+	mutex    sync.RWMutex
+	sequence int64
+	clients  map[int64]*models.Client
+}
+
+func NewMemoryClientsRepository() ClientsRepository {
+	return &memoryClientsRepository{clients: make(map[int64]*models.Client)}
+}
+
+func (r *memoryClientsRepository) Create(_ context.Context, client *models.Client) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.sequence++
+	client.ID = r.sequence
+	r.clients[client.ID] = cloneClient(client)
+
+	return client.ID, nil
+}
+
+func (r *memoryClientsRepository) Update(_ context.Context, client *models.Client) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existing, ok := r.clients[client.ID]
+	if !ok {
+		return 0, nil
+	}
+
+	updated := cloneClient(client)
+	updated.CreatedAt = existing.CreatedAt
+	r.clients[client.ID] = updated
+
+	return 1, nil
+}
+
+func (r *memoryClientsRepository) Delete(_ context.Context, id int64) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.clients[id]; !ok {
+		return 0, nil
+	}
+	delete(r.clients, id)
+
+	return 1, nil
+}
+
+func (r *memoryClientsRepository) Get(_ context.Context, id int64) (*models.Client, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	client, ok := r.clients[id]
+	if !ok {
+		return new(models.Client), ErrNotFound
+	}
+	return cloneClient(client), nil
+}
+
+func (r *memoryClientsRepository) GetAll(_ context.Context) ([]*models.Client, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	clients := make([]*models.Client, 0, len(r.clients))
+	for _, client := range r.clients {
+		clients = append(clients, cloneClient(client))
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		if clients[i].Name != clients[j].Name {
+			return clients[i].Name < clients[j].Name
+		}
+		return clients[i].ID < clients[j].ID
+	})
+	return clients, nil
+}
+
+func cloneClient(client *models.Client) *models.Client {
+	clone := *client
+	if client.HourlyRate != nil {
+		clone.HourlyRate = pointer.New(*client.HourlyRate)
+	}
+	return &clone
+}
@@ -0,0 +1,903 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/pointer"
+	"github.com/ungame/command-time-track/db"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stores are the repositories of a backend, sharing its connection.
+type stores struct {
+	transactor Transactor
+	activities ActivitiesRepository
+	clients    ClientsRepository
+	projects   ProjectsRepository
+	invoices   InvoicesRepository
+	users      UsersRepository
+	tokens     TokensRepository
+	teams      TeamsRepository
+	events     ActivityEventsRepository
+	webhooks   WebhooksRepository
+}
+
+func sqlStores(conn *sql.DB) *stores {
+	return &stores{
+		transactor: NewTransactor(conn),
+		activities: NewActivitiesRepository(context.Background(), conn),
+		clients:    NewClientsRepository(conn),
+		projects:   NewProjectsRepository(conn),
+		invoices:   NewInvoicesRepository(conn),
+		users:      NewUsersRepository(conn),
+		tokens:     NewTokensRepository(conn),
+		teams:      NewTeamsRepository(conn),
+		events:     NewActivityEventsRepository(conn),
+		webhooks:   NewWebhooksRepository(conn),
+	}
+}
+
+type backend struct {
+	name string
+	open func(t *testing.T) *stores
+}
+
+func (b backend) repository(t *testing.T) ActivitiesRepository {
+	return b.open(t).activities
+}
+
+var backends = []backend{
+	{name: "mysql", open: openMySQLStores},
+	{name: "sqlite", open: openSQLiteStores},
+	{name: "memory", open: openMemoryStores},
+}
+
+func openMySQLStores(t *testing.T) *stores {
+	conn := db.New()
+	t.Cleanup(func() { conn.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*2)
+	defer cancel()
+
+	if err := conn.PingContext(ctx); err != nil {
+		t.Skipf("mysql is not available: %s", err.Error())
+	}
+
+	db.MustMigrate(conn, db.DialectMySQL)
+
+	return sqlStores(conn)
+}
+
+func openSQLiteStores(t *testing.T) *stores {
+	conn := db.NewSQLite(filepath.Join(t.TempDir(), "activities.db"))
+	t.Cleanup(func() { conn.Close() })
+	db.MustMigrate(conn, db.DialectSQLite)
+	return sqlStores(conn)
+}
+
+func openMemoryStores(_ *testing.T) *stores {
+	return &stores{
+		transactor: NewMemoryTransactor(),
+		activities: NewMemoryActivitiesRepository(),
+		clients:    NewMemoryClientsRepository(),
+		projects:   NewMemoryProjectsRepository(),
+		invoices:   NewMemoryInvoicesRepository(),
+		users:      NewMemoryUsersRepository(),
+		tokens:     NewMemoryTokensRepository(),
+		teams:      NewMemoryTeamsRepository(),
+		events:     NewMemoryActivityEventsRepository(),
+		webhooks:   NewMemoryWebhooksRepository(),
+	}
+}
+
+// TestActivitiesRepository runs the conformance suite against every ActivitiesRepository
+// implementation. New implementations must be added to backends.
+func TestActivitiesRepository(t *testing.T) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			t.Run("Lifecycle", func(t *testing.T) { testActivitiesRepository(t, b.repository(t)) })
+			t.Run("Get", func(t *testing.T) { testGetActivity(t, b.repository(t)) })
+			t.Run("Update", func(t *testing.T) { testUpdateActivity(t, b.repository(t)) })
+			t.Run("Delete", func(t *testing.T) { testDeleteActivity(t, b.repository(t)) })
+			t.Run("Trash", func(t *testing.T) { testActivitiesTrash(t, b.repository(t)) })
+			t.Run("Search", func(t *testing.T) { testSearchActivities(t, b.repository(t)) })
+			t.Run("GetByStatus", func(t *testing.T) { testGetActivitiesByStatus(t, b.repository(t)) })
+			t.Run("Intervals", func(t *testing.T) { testActivityIntervals(t, b.repository(t)) })
+			t.Run("CreateFinished", func(t *testing.T) { testCreateFinishedActivity(t, b.repository(t)) })
+			t.Run("Find", func(t *testing.T) { testFindActivities(t, b.repository(t)) })
+			t.Run("Tags", func(t *testing.T) { testActivityTags(t, b.repository(t)) })
+			t.Run("ConcurrentCreate", func(t *testing.T) { testConcurrentCreateActivities(t, b.repository(t)) })
+			t.Run("ConcurrentStart", func(t *testing.T) { testConcurrentStartActivities(t, b.open(t)) })
+			t.Run("Transaction", func(t *testing.T) {
+				s := b.open(t)
+				testActivitiesTransaction(t, s.transactor, s.activities)
+			})
+			t.Run("CommitHooks", func(t *testing.T) { testCommitHooks(t, b.open(t).transactor) })
+			t.Run("Projects", func(t *testing.T) { testProjectsRepository(t, b.open(t)) })
+			t.Run("Invoices", func(t *testing.T) { testInvoicesRepository(t, b.open(t)) })
+			t.Run("Owners", func(t *testing.T) { testActivityOwners(t, b.open(t)) })
+			t.Run("Tokens", func(t *testing.T) { testTokensRepository(t, b.open(t)) })
+			t.Run("Roles", func(t *testing.T) { testUserRoles(t, b.open(t)) })
+			t.Run("Teams", func(t *testing.T) { testTeamsRepository(t, b.open(t)) })
+			t.Run("Events", func(t *testing.T) { testActivityEventsRepository(t, b.open(t)) })
+			t.Run("Webhooks", func(t *testing.T) { testWebhooksRepository(t, b.open(t)) })
+		})
+	}
+}
+
+func uniqueTerm(prefix string) string {
+	return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
+}
+
+func mustCreateActivity(t *testing.T, repo ActivitiesRepository, category, description string) int64 {
+	t.Helper()
+	id, err := repo.Create(context.Background(), &models.Activity{
+		Category:    category,
+		Description: description,
+		StartedAt:   time.Now().UTC(),
+		UpdatedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error on create activity: %s", err.Error())
+	}
+	t.Cleanup(func() { _, _ = repo.Delete(context.Background(), id) })
+	return id
+}
+
+func ids(activities []*models.Activity) []int64 {
+	out := make([]int64, 0, len(activities))
+	for _, activity := range activities {
+		out = append(out, activity.ID)
+	}
+	return out
+}
+
+func containsID(activities []*models.Activity, id int64) bool {
+	for _, activity := range activities {
+		if activity.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func testGetActivity(t *testing.T, repo ActivitiesRepository) {
+	ctx := context.Background()
+
+	id := mustCreateActivity(t, repo, "conformance", "get activity")
+
+	activity, err := repo.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("unexpected error on get activity: %s", err.Error())
+	}
+	if activity.ID != id {
+		t.Errorf("unexpected id on get activity: expected=%d, got=%d", id, activity.ID)
+	}
+	if activity.Status != models.StatusStarted {
+		t.Errorf("unexpected default status on get activity: expected=%v, got=%v", models.StatusStarted, activity.Status)
+	}
+	if activity.FinishedAt != nil {
+		t.Errorf("unexpected finished at on get activity: %v", activity.FinishedAt)
+	}
+
+	_, err = repo.Get(ctx, id+1_000_000)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("unexpected error on get missing activity: expected=%v, got=%v", ErrNotFound, err)
+	}
+}
+
+func testUpdateActivity(t *testing.T, repo ActivitiesRepository) {
+	ctx := context.Background()
+
+	id := mustCreateActivity(t, repo, "conformance", "update activity")
+
+	existing, err := repo.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("unexpected error on get activity: %s", err.Error())
+	}
+
+	existing.Category = "updated"
+	existing.Description = "updated activity"
+	existing.StartedAt = existing.StartedAt.Add(-time.Hour).Truncate(time.Second)
+	existing.Status = models.StatusFinished
+	existing.FinishedAt = pointer.New(time.Now().UTC())
+	existing.UpdatedAt = time.Now().UTC()
+
+	rows, err := repo.Update(ctx, existing)
+	if err != nil {
+		t.Fatalf("unexpected error on update activity: %s", err.Error())
+	}
+	if rows != 1 {
+		t.Errorf("unexpected affected rows on update activity: expected=1, got=%d", rows)
+	}
+
+	updated, err := repo.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("unexpected error on get updated activity: %s", err.Error())
+	}
+	if updated.Category != existing.Category || updated.Description != existing.Description {
+		t.Errorf("unexpected fields on updated activity: expected=%v, got=%v", existing, updated)
+	}
+	if updated.Status != models.StatusFinished || updated.FinishedAt == nil {
+		t.Errorf("unexpected status on updated activity: status=%v, finished_at=%v", updated.Status, updated.FinishedAt)
+	}
+	if !updated.StartedAt.Equal(existing.StartedAt) {
+		t.Errorf("unexpected started at on updated activity: expected=%s, got=%s", existing.StartedAt, updated.StartedAt)
+	}
+
+	existing.ID = id + 1_000_000
+	rows, err = repo.Update(ctx, existing)
+	if err != nil {
+		t.Errorf("unexpected error on update missing activity: %s", err.Error())
+	}
+	if rows != 0 {
+		t.Errorf("unexpected affected rows on update missing activity: expected=0, got=%d", rows)
+	}
+}
+
+func testDeleteActivity(t *testing.T, repo ActivitiesRepository) {
+	ctx := context.Background()
+
+	id := mustCreateActivity(t, repo, "conformance", "delete activity")
+
+	rows, err := repo.Delete(ctx, id)
+	if err != nil {
+		t.Fatalf("unexpected error on delete activity: %s", err.Error())
+	}
+	if rows != 1 {
+		t.Errorf("unexpected affected rows on delete activity: expected=1, got=%d", rows)
+	}
+
+	rows, err = repo.Delete(ctx, id)
+	if err != nil {
+		t.Errorf("unexpected error on delete deleted activity: %s", err.Error())
+	}
+	if rows != 0 {
+		t.Errorf("unexpected affected rows on delete deleted activity: expected=0, got=%d", rows)
+	}
+
+	if _, err = repo.Get(ctx, id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unexpected error on get deleted activity: expected=%v, got=%v", ErrNotFound, err)
+	}
+}
+
+func testActivitiesTrash(t *testing.T, repo ActivitiesRepository) {
+	var (
+		ctx      = context.Background()
+		category = uniqueTerm("trash")
+		kept     = mustCreateActivity(t, repo, category, "kept activity")
+		trashed  = mustCreateActivity(t, repo, category, "trashed activity")
+		purged   = mustCreateActivity(t, repo, category, "purged activity")
+	)
+
+	if rows, err := repo.Purge(ctx, kept); err != nil || rows != 0 {
+		t.Errorf("expected activity out of the trash not purged, got rows=%d, err=%v", rows, err)
+	}
+	if rows, err := repo.Restore(ctx, kept); err != nil || rows != 0 {
+		t.Errorf("expected activity out of the trash not restored, got rows=%d, err=%v", rows, err)
+	}
+
+	for _, id := range []int64{trashed, purged} {
+		if rows, err := repo.Delete(ctx, id); err != nil || rows != 1 {
+			t.Fatalf("unexpected delete: rows=%d, err=%v", rows, err)
+		}
+	}
+
+	items, err := repo.Find(ctx, &ActivitiesQuery{Category: category})
+	if err != nil {
+		t.Fatalf("unexpected error on find activities: %s", err.Error())
+	}
+	if fmt.Sprint(ids(items)) != fmt.Sprint([]int64{kept}) {
+		t.Errorf("expected activities in the trash hidden from find, got %v", ids(items))
+	}
+	if items, err = repo.Search(ctx, category); err != nil || containsID(items, trashed) {
+		t.Errorf("expected activities in the trash hidden from search, got %v, err=%v", ids(items), err)
+	}
+	if items, err = repo.GetByStatus(ctx, models.StatusStarted); err != nil || containsID(items, trashed) {
+		t.Errorf("expected activities in the trash hidden from get by status, got %v, err=%v", ids(items), err)
+	}
+
+	trash, err := repo.Trash(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error on trash: %s", err.Error())
+	}
+	if !containsID(trash, trashed) || !containsID(trash, purged) || containsID(trash, kept) {
+		t.Errorf("unexpected activities in the trash: %v", ids(trash))
+	}
+
+	deleted, err := repo.GetDeleted(ctx, trashed)
+	if err != nil {
+		t.Fatalf("unexpected error on get deleted activity: %s", err.Error())
+	}
+	if deleted.DeletedAt == nil || deleted.Description != "trashed activity" {
+		t.Errorf("unexpected deleted activity: %+v", deleted)
+	}
+	if _, err = repo.GetDeleted(ctx, kept); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected activity out of the trash missing from get deleted, got %v", err)
+	}
+
+	deleted.Category = "updated"
+	if rows, err := repo.Update(ctx, deleted); err != nil || rows != 0 {
+		t.Errorf("expected activity in the trash not updated, got rows=%d, err=%v", rows, err)
+	}
+
+	if rows, err := repo.Restore(ctx, trashed); err != nil || rows != 1 {
+		t.Errorf("expected activity restored, got rows=%d, err=%v", rows, err)
+	}
+	restored, err := repo.Get(ctx, trashed)
+	if err != nil {
+		t.Fatalf("unexpected error on get restored activity: %s", err.Error())
+	}
+	if restored.DeletedAt != nil || restored.Category != category {
+		t.Errorf("unexpected restored activity: %+v", restored)
+	}
+
+	if rows, err := repo.Purge(ctx, purged); err != nil || rows != 1 {
+		t.Errorf("expected activity purged, got rows=%d, err=%v", rows, err)
+	}
+	if _, err = repo.GetDeleted(ctx, purged); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected purged activity gone, got %v", err)
+	}
+	if rows, err := repo.Restore(ctx, purged); err != nil || rows != 0 {
+		t.Errorf("expected purged activity not restored, got rows=%d, err=%v", rows, err)
+	}
+}
+
+func testSearchActivities(t *testing.T, repo ActivitiesRepository) {
+	ctx := context.Background()
+
+	term := uniqueTerm("search")
+
+	var (
+		byCategory    = mustCreateActivity(t, repo, term, "matches by category")
+		byDescription = mustCreateActivity(t, repo, "conformance", "matches "+term+" by description")
+		byCase        = mustCreateActivity(t, repo, "conformance", "MATCHES "+term)
+		other         = mustCreateActivity(t, repo, "conformance", "does not match")
+	)
+
+	items, err := repo.Search(ctx, "MATCHES "+term[:6])
+	if err != nil {
+		t.Fatalf("unexpected error on search activities: %s", err.Error())
+	}
+	if !containsID(items, byCase) || !containsID(items, byDescription) {
+		t.Errorf("unexpected items on case insensitive search: got=%v", ids(items))
+	}
+
+	items, err = repo.Search(ctx, term)
+	if err != nil {
+		t.Fatalf("unexpected error on search activities: %s", err.Error())
+	}
+	if len(items) != 3 {
+		t.Errorf("unexpected length items on search activities: expected=3, got=%d", len(items))
+	}
+	for _, id := range []int64{byCategory, byDescription, byCase} {
+		if !containsID(items, id) {
+			t.Errorf("missing item on search activities: id=%d, got=%v", id, ids(items))
+		}
+	}
+	if containsID(items, other) {
+		t.Errorf("unexpected item on search activities: id=%d", other)
+	}
+}
+
+func testGetActivitiesByStatus(t *testing.T, repo ActivitiesRepository) {
+	ctx := context.Background()
+
+	var (
+		started  = mustCreateActivity(t, repo, "conformance", "started activity")
+		finished = mustCreateActivity(t, repo, "conformance", "finished activity")
+	)
+
+	activity, err := repo.Get(ctx, finished)
+	if err != nil {
+		t.Fatalf("unexpected error on get activity: %s", err.Error())
+	}
+	activity.Status = models.StatusFinished
+	activity.FinishedAt = pointer.New(time.Now().UTC())
+	if _, err = repo.Update(ctx, activity); err != nil {
+		t.Fatalf("unexpected error on update activity: %s", err.Error())
+	}
+
+	for status, expected := range map[models.Status]int64{models.StatusStarted: started, models.StatusFinished: finished} {
+		items, err := repo.GetByStatus(ctx, status)
+		if err != nil {
+			t.Fatalf("unexpected error on get activities by status: %s", err.Error())
+		}
+		if !containsID(items, expected) {
+			t.Errorf("missing item on get activities by status %v: id=%d", status, expected)
+		}
+		for i, item := range items {
+			if item.Status != status {
+				t.Errorf("unexpected status on get activities by status: expected=%v, got=%v", status, item.Status)
+			}
+			if i > 0 && items[i-1].ID >= item.ID {
+				t.Errorf("unexpected order on get activities by status: %v", ids(items))
+			}
+		}
+	}
+}
+
+func testConcurrentCreateActivities(t *testing.T, repo ActivitiesRepository) {
+	const workers = 20
+
+	var (
+		ctx   = context.Background()
+		wg    sync.WaitGroup
+		mutex sync.Mutex
+		seen  = make(map[int64]bool, workers)
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id, err := repo.Create(ctx, &models.Activity{
+				Category:    "conformance",
+				Description: fmt.Sprintf("concurrent activity %d", i),
+				StartedAt:   time.Now().UTC(),
+				UpdatedAt:   time.Now().UTC(),
+			})
+			if err != nil {
+				t.Errorf("unexpected error on concurrent create activity: %s", err.Error())
+				return
+			}
+			mutex.Lock()
+			seen[id] = true
+			mutex.Unlock()
+		}(i)
+	}
+
+	wg.Wait()
+
+	for id := range seen {
+		_, _ = repo.Delete(ctx, id)
+	}
+
+	if len(seen) != workers {
+		t.Errorf("unexpected unique ids on concurrent create activities: expected=%d, got=%d", workers, len(seen))
+	}
+}
+
+// testConcurrentStartActivities starts activities of a user concurrently as the service does, stopping the started
+// ones in the transaction creating the new one, leaving a single activity started.
+func testConcurrentStartActivities(t *testing.T, s *stores) {
+	const workers = 10
+
+	var (
+		user = mustCreateUser(t, s.users, "starts")
+		ctx  = WithOwner(context.Background(), user.ID)
+		wg   sync.WaitGroup
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+				if err := s.activities.LockStarted(ctx); err != nil {
+					return err
+				}
+				started, err := s.activities.GetByStatus(ctx, models.StatusStarted)
+				if err != nil {
+					return err
+				}
+				now := time.Now().UTC()
+				for _, activity := range started {
+					activity.Finish(now)
+					activity.UpdatedAt = now
+					if _, err = s.activities.Update(ctx, activity); err != nil {
+						return err
+					}
+				}
+				_, err = s.activities.Create(ctx, &models.Activity{
+					Category:    "conformance",
+					Description: fmt.Sprintf("concurrent start %d", i),
+					StartedAt:   now,
+					UpdatedAt:   now,
+					Intervals:   []*models.Interval{{StartedAt: now}},
+				})
+				return err
+			})
+			if err != nil {
+				t.Errorf("unexpected error on concurrent start activity: %s", err.Error())
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	started, err := s.activities.GetByStatus(ctx, models.StatusStarted)
+	if err != nil {
+		t.Fatalf("unexpected error on get started activities: %s", err.Error())
+	}
+	if len(started) != 1 {
+		t.Errorf("expected a single started activity after concurrent starts, got %d", len(started))
+	}
+	all, _ := s.activities.GetAll(ctx)
+	if len(all) != workers {
+		t.Errorf("expected every start to create an activity, got %d", len(all))
+	}
+}
+
+func testCreateFinishedActivity(t *testing.T, repo ActivitiesRepository) {
+	var (
+		ctx      = context.Background()
+		started  = time.Now().UTC().Add(-time.Hour * 3).Truncate(time.Second)
+		finished = started.Add(time.Hour)
+	)
+
+	id, err := repo.Create(ctx, &models.Activity{
+		Category:    "conformance",
+		Description: "finished activity",
+		Status:      models.StatusFinished,
+		StartedAt:   started,
+		UpdatedAt:   finished,
+		FinishedAt:  pointer.New(finished),
+		Intervals:   []*models.Interval{{StartedAt: started, FinishedAt: pointer.New(finished)}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error on create activity: %s", err.Error())
+	}
+	t.Cleanup(func() { _, _ = repo.Delete(ctx, id) })
+
+	existing, err := repo.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("unexpected error on get activity: %s", err.Error())
+	}
+	if existing.Status != models.StatusFinished || existing.FinishedAt == nil || !existing.FinishedAt.Equal(finished) {
+		t.Errorf("unexpected finished activity: status=%v, finished_at=%v", existing.Status, existing.FinishedAt)
+	}
+	if duration := existing.Duration(time.Now()); duration != time.Hour {
+		t.Errorf("unexpected duration: expected=%s, got=%s", time.Hour, duration)
+	}
+}
+
+func testActivityIntervals(t *testing.T, repo ActivitiesRepository) {
+	var (
+		ctx     = context.Background()
+		started = time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+		paused  = started.Add(time.Minute * 20)
+		resumed = started.Add(time.Minute * 30)
+	)
+
+	activity := &models.Activity{
+		Category:    "conformance",
+		Description: "activity with intervals",
+		StartedAt:   started,
+		UpdatedAt:   started,
+		Intervals:   []*models.Interval{{StartedAt: started}},
+	}
+
+	id, err := repo.Create(ctx, activity)
+	if err != nil {
+		t.Fatalf("unexpected error on create activity: %s", err.Error())
+	}
+	t.Cleanup(func() { _, _ = repo.Delete(ctx, id) })
+
+	if activity.Intervals[0].ID == 0 {
+		t.Errorf("expected interval id to be assigned on create activity")
+	}
+
+	existing, err := repo.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("unexpected error on get activity: %s", err.Error())
+	}
+	if len(existing.Intervals) != 1 || existing.OpenInterval() == nil {
+		t.Fatalf("unexpected intervals on get activity: %v", existing.Intervals)
+	}
+
+	existing.Pause(paused)
+	existing.Resume(resumed)
+
+	if _, err = repo.Update(ctx, existing); err != nil {
+		t.Fatalf("unexpected error on update activity: %s", err.Error())
+	}
+
+	updated, err := repo.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("unexpected error on get updated activity: %s", err.Error())
+	}
+	if len(updated.Intervals) != 2 {
+		t.Fatalf("unexpected intervals length on get updated activity: expected=2, got=%d", len(updated.Intervals))
+	}
+	if updated.Intervals[0].FinishedAt == nil || !updated.Intervals[0].FinishedAt.Equal(paused) {
+		t.Errorf("unexpected first interval finished at: expected=%s, got=%v", paused, updated.Intervals[0].FinishedAt)
+	}
+	if !updated.Intervals[1].StartedAt.Equal(resumed) || updated.Intervals[1].FinishedAt != nil {
+		t.Errorf("unexpected second interval: started_at=%s, finished_at=%v", updated.Intervals[1].StartedAt, updated.Intervals[1].FinishedAt)
+	}
+
+	finished := resumed.Add(time.Minute * 10)
+	updated.Finish(finished)
+	if _, err = repo.Update(ctx, updated); err != nil {
+		t.Fatalf("unexpected error on finish activity: %s", err.Error())
+	}
+
+	updated, err = repo.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("unexpected error on get finished activity: %s", err.Error())
+	}
+	if duration := updated.Duration(time.Now()); duration != time.Minute*30 {
+		t.Errorf("unexpected duration on get finished activity: expected=%s, got=%s", time.Minute*30, duration)
+	}
+
+	updated.Intervals = updated.Intervals[1:]
+	if _, err = repo.Update(ctx, updated); err != nil {
+		t.Fatalf("unexpected error on remove interval: %s", err.Error())
+	}
+
+	updated, err = repo.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("unexpected error on get activity: %s", err.Error())
+	}
+	if len(updated.Intervals) != 1 || !updated.Intervals[0].StartedAt.Equal(resumed) {
+		t.Errorf("unexpected intervals after remove interval: %v", updated.Intervals)
+	}
+}
+
+func testFindActivities(t *testing.T, repo ActivitiesRepository) {
+	var (
+		ctx      = context.Background()
+		category = uniqueTerm("find")
+		base     = time.Now().UTC().Add(-time.Hour * 24).Truncate(time.Second)
+		created  = make([]int64, 0, 5)
+	)
+
+	// five one hour activities starting every two hours, the last one still running
+	for i := 0; i < 5; i++ {
+		startedAt := base.Add(time.Hour * time.Duration(i*2))
+		activity := &models.Activity{
+			Category:    category,
+			Description: fmt.Sprintf("find activity %d", i),
+			StartedAt:   startedAt,
+			UpdatedAt:   startedAt,
+			Intervals:   []*models.Interval{{StartedAt: startedAt}},
+		}
+		id, err := repo.Create(ctx, activity)
+		if err != nil {
+			t.Fatalf("unexpected error on create activity: %s", err.Error())
+		}
+		t.Cleanup(func() { _, _ = repo.Delete(ctx, id) })
+		created = append(created, id)
+		if i < 4 {
+			activity.Finish(startedAt.Add(time.Hour))
+			if _, err = repo.Update(ctx, activity); err != nil {
+				t.Fatalf("unexpected error on finish activity: %s", err.Error())
+			}
+		}
+	}
+
+	find := func(q *ActivitiesQuery) []int64 {
+		t.Helper()
+		q.Category = category
+		items, err := repo.Find(ctx, q)
+		if err != nil {
+			t.Fatalf("unexpected error on find activities: %s", err.Error())
+		}
+		return ids(items)
+	}
+
+	assertIDs := func(name string, expected, got []int64) {
+		t.Helper()
+		if fmt.Sprint(expected) != fmt.Sprint(got) {
+			t.Errorf("unexpected activities on %s: expected=%v, got=%v", name, expected, got)
+		}
+	}
+
+	assertIDs("find by category", created, find(&ActivitiesQuery{}))
+
+	running := models.StatusStarted
+	assertIDs("find by status", created[4:], find(&ActivitiesQuery{Status: &running}))
+
+	var (
+		from = base.Add(time.Minute * 150)
+		to   = base.Add(time.Minute * 270)
+	)
+	assertIDs("find by time range", created[1:3], find(&ActivitiesQuery{From: &from, To: &to}))
+	assertIDs("find from time", created[2:], find(&ActivitiesQuery{From: &to}))
+
+	reversed := []int64{created[4], created[3], created[2], created[1], created[0]}
+	assertIDs("find sorted by started_at desc", reversed, find(&ActivitiesQuery{Sort: SortStartedAt, Order: OrderDesc}))
+
+	for _, sort := range []string{SortID, SortStartedAt, SortUpdatedAt, SortCategory} {
+		var (
+			pages []int64
+			after *Cursor
+		)
+		for page := 0; page < 10; page++ {
+			q := &ActivitiesQuery{Category: category, Sort: sort, Order: OrderDesc, Limit: 2, After: after}
+			items, err := repo.Find(ctx, q)
+			if err != nil {
+				t.Fatalf("unexpected error on find page sorted by %s: %s", sort, err.Error())
+			}
+			pages = append(pages, ids(items)...)
+			if len(items) < 2 {
+				break
+			}
+			after, err = DecodeCursor(NewCursor(sort, items[len(items)-1]).Encode())
+			if err != nil {
+				t.Fatalf("unexpected error on decode cursor: %s", err.Error())
+			}
+		}
+		assertIDs("find pages sorted by "+sort, reversed, pages)
+	}
+
+	if _, err := repo.Find(ctx, &ActivitiesQuery{Sort: "description"}); err == nil {
+		t.Errorf("expected error on find sorted by invalid field")
+	}
+}
+
+func testActivityTags(t *testing.T, repo ActivitiesRepository) {
+	var (
+		ctx      = context.Background()
+		category = uniqueTerm("tags")
+		billable = uniqueTerm("billable")
+		meeting  = uniqueTerm("meeting")
+		oncall   = uniqueTerm("oncall")
+		now      = time.Now().UTC().Truncate(time.Second)
+	)
+
+	create := func(tags ...string) *models.Activity {
+		t.Helper()
+		activity := &models.Activity{
+			Category:    category,
+			Description: "tagged activity",
+			StartedAt:   now,
+			UpdatedAt:   now,
+			Intervals:   []*models.Interval{{StartedAt: now}},
+			Tags:        tags,
+		}
+		id, err := repo.Create(ctx, activity)
+		if err != nil {
+			t.Fatalf("unexpected error on create tagged activity: %s", err.Error())
+		}
+		t.Cleanup(func() { _, _ = repo.Delete(ctx, id) })
+		return activity
+	}
+
+	assertTags := func(id int64, expected ...string) {
+		t.Helper()
+		activity, err := repo.Get(ctx, id)
+		if err != nil {
+			t.Fatalf("unexpected error on get tagged activity: %s", err.Error())
+		}
+		if fmt.Sprint(activity.Tags) != fmt.Sprint(expected) {
+			t.Errorf("unexpected tags: expected=%v, got=%v", expected, activity.Tags)
+		}
+	}
+
+	find := func(tags ...string) []int64 {
+		t.Helper()
+		items, err := repo.Find(ctx, &ActivitiesQuery{Category: category, Tags: tags})
+		if err != nil {
+			t.Fatalf("unexpected error on find tagged activities: %s", err.Error())
+		}
+		return ids(items)
+	}
+
+	first := create(meeting, billable)
+	second := create(billable)
+	untagged := create()
+
+	assertTags(first.ID, billable, meeting)
+	assertTags(untagged.ID)
+
+	if got := find(billable); fmt.Sprint(got) != fmt.Sprint([]int64{first.ID, second.ID}) {
+		t.Errorf("unexpected activities tagged %s: %v", billable, got)
+	}
+	if got := find(billable, meeting); fmt.Sprint(got) != fmt.Sprint([]int64{first.ID}) {
+		t.Errorf("unexpected activities tagged %s and %s: %v", billable, meeting, got)
+	}
+
+	first.Tags = []string{oncall, billable}
+	if _, err := repo.Update(ctx, first); err != nil {
+		t.Fatalf("unexpected error on update tags: %s", err.Error())
+	}
+	assertTags(first.ID, billable, oncall)
+
+	if got := find(meeting); len(got) != 0 {
+		t.Errorf("expected no activities tagged %s, got %v", meeting, got)
+	}
+
+	first.Tags = nil
+	if _, err := repo.Update(ctx, first); err != nil {
+		t.Fatalf("unexpected error on remove tags: %s", err.Error())
+	}
+	assertTags(first.ID)
+}
+
+func testActivitiesTransaction(t *testing.T, transactor Transactor, repo ActivitiesRepository) {
+	var (
+		ctx      = context.Background()
+		rollback = errors.New("rollback")
+		kept     = mustCreateActivity(t, repo, "conformance", "kept by rolled back transaction")
+		created  int64
+	)
+
+	err := transactor.WithinTx(ctx, func(ctx context.Context) error {
+		existing, err := repo.Get(ctx, kept)
+		if err != nil {
+			return err
+		}
+		existing.Category = "rolled back"
+		existing.Finish(time.Now().UTC())
+		if _, err = repo.Update(ctx, existing); err != nil {
+			return err
+		}
+		if created, err = repo.Create(ctx, &models.Activity{Category: "conformance", StartedAt: time.Now().UTC(), UpdatedAt: time.Now().UTC()}); err != nil {
+			return err
+		}
+		if _, err = repo.Get(ctx, created); err != nil {
+			return fmt.Errorf("created activity not visible inside transaction: %w", err)
+		}
+		return rollback
+	})
+	if err != rollback {
+		t.Fatalf("unexpected error on rolled back transaction: %v", err)
+	}
+
+	existing, err := repo.Get(ctx, kept)
+	if err != nil {
+		t.Fatalf("unexpected error on get activity: %s", err.Error())
+	}
+	if existing.Category != "conformance" || existing.Status != models.StatusStarted || len(existing.Intervals) != 0 {
+		t.Errorf("expected rolled back update to be undone: %+v", existing)
+	}
+	if _, err = repo.Get(ctx, created); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected rolled back create to be undone, got %v", err)
+	}
+
+	err = transactor.WithinTx(ctx, func(ctx context.Context) error {
+		return transactor.WithinTx(ctx, func(ctx context.Context) error {
+			_, err := repo.Delete(ctx, kept)
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error on committed transaction: %s", err.Error())
+	}
+	if _, err = repo.Get(ctx, kept); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected committed delete, got %v", err)
+	}
+}
+
+func testCommitHooks(t *testing.T, transactor Transactor) {
+	var (
+		ctx      = context.Background()
+		rollback = errors.New("rollback")
+		ran      []string
+	)
+
+	err := transactor.WithinTx(ctx, func(ctx context.Context) error {
+		OnCommit(ctx, func() { ran = append(ran, "rolled back") })
+		return rollback
+	})
+	if err != rollback {
+		t.Fatalf("unexpected error on rolled back transaction: %v", err)
+	}
+
+	err = transactor.WithinTx(ctx, func(ctx context.Context) error {
+		OnCommit(ctx, func() { ran = append(ran, "outer") })
+		return transactor.WithinTx(ctx, func(ctx context.Context) error {
+			OnCommit(ctx, func() { ran = append(ran, "nested") })
+			if len(ran) != 0 {
+				t.Errorf("expected hooks to wait for the commit, got %v", ran)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error on committed transaction: %s", err.Error())
+	}
+
+	OnCommit(ctx, func() { ran = append(ran, "no transaction") })
+
+	if fmt.Sprint(ran) != fmt.Sprint([]string{"outer", "nested", "no transaction"}) {
+		t.Errorf("unexpected hooks run: %v", ran)
+	}
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"github.com/ungame/command-time-track/app/models"
+)
+
+const eachBatchSize = 500
+
+// Each calls fn for every activity matching the query, fetching them from the repository in
+// batches instead of loading the whole result at once. The query limit and cursor are ignored.
+func Each(ctx context.Context, repo ActivitiesRepository, query ActivitiesQuery, fn func(activity *models.Activity) error) error {
+	query.Limit = eachBatchSize
+	query.After = nil
+
+	if err := query.Validate(); err != nil {
+		return err
+	}
+
+	for {
+		activities, err := repo.Find(ctx, &query)
+		if err != nil {
+			return err
+		}
+		for _, activity := range activities {
+			if err = fn(activity); err != nil {
+				return err
+			}
+		}
+		if len(activities) < eachBatchSize {
+			return nil
+		}
+		query.After = NewCursor(query.Sort, activities[len(activities)-1])
+	}
+}
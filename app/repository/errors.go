@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/ungame/command-time-track/app/apperr"
+)
+
+// ErrNotFound is returned for missing rows, it matches sql.ErrNoRows for the callers checking it.
+var ErrNotFound = apperr.Wrap(apperr.CodeNotFound, sql.ErrNoRows, apperr.ErrNotFound.Message)
+
+// notFound replaces sql.ErrNoRows with ErrNotFound, keeping the driver errors out of the domain.
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
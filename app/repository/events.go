@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/ungame/command-time-track/app/ioext"
+	"github.com/ungame/command-time-track/app/models"
+)
+
+// ActivityEventsRepository stores the history of the activities, events are only appended.
+type ActivityEventsRepository interface {
+	Create(ctx context.Context, event *models.ActivityEvent) (int64, error)
+	// GetByActivity returns the events of the activity, oldest first.
+	GetByActivity(ctx context.Context, activityID int64) ([]*models.ActivityEvent, error)
+}
+
+type activityEventsRepository struct {
+	conn *sql.DB
+}
+
+func NewActivityEventsRepository(conn *sql.DB) ActivityEventsRepository {
+	return &activityEventsRepository{conn: conn}
+}
+
+func (r *activityEventsRepository) Create(ctx context.Context, event *models.ActivityEvent) (int64, error) {
+	result, err := queryerFrom(ctx, r.conn).ExecContext(ctx, insertActivityEventQuery, event.ActivityID, event.OwnerID, event.ActorID, event.Action, event.Before, event.After, event.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	if event.ID, err = result.LastInsertId(); err != nil {
+		return 0, err
+	}
+	return event.ID, nil
+}
+
+func (r *activityEventsRepository) GetByActivity(ctx context.Context, activityID int64) ([]*models.ActivityEvent, error) {
+	rows, err := queryerFrom(ctx, r.conn).QueryContext(ctx, `select `+activityEventColumns+` from activity_events where activity_id = ? order by id`, activityID)
+	if err != nil {
+		return nil, err
+	}
+	defer ioext.Close(rows)
+	events := make([]*models.ActivityEvent, 0, 10)
+	for rows.Next() {
+		event := new(models.ActivityEvent)
+		if err = scanActivityEvent(rows, event); err != nil {
+			return events, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func scanActivityEvent(row scanner, event *models.ActivityEvent) error {
+	return row.Scan(&event.ID, &event.ActivityID, &event.OwnerID, &event.ActorID, &event.Action, &event.Before, &event.After, &event.CreatedAt)
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"github.com/ungame/command-time-track/app/models"
+	"sync"
+)
+
+type memoryActivityEventsRepository struct {
+	mutex    sync.RWMutex
+	sequence int64
+	events   []*models.ActivityEvent
+}
+
+func NewMemoryActivityEventsRepository() ActivityEventsRepository {
+	return &memoryActivityEventsRepository{}
+}
+
+func (r *memoryActivityEventsRepository) Create(ctx context.Context, event *models.ActivityEvent) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.sequence++
+	event.ID = r.sequence
+	r.events = append(r.events, cloneActivityEvent(event))
+
+	id := event.ID
+	onRollback(ctx, func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		for i, event := range r.events {
+			if event.ID == id {
+				r.events = append(r.events[:i], r.events[i+1:]...)
+				return
+			}
+		}
+	})
+
+	return event.ID, nil
+}
+
+func (r *memoryActivityEventsRepository) GetByActivity(_ context.Context, activityID int64) ([]*models.ActivityEvent, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	events := make([]*models.ActivityEvent, 0)
+	for _, event := range r.events {
+		if event.ActivityID == activityID {
+			events = append(events, cloneActivityEvent(event))
+		}
+	}
+	return events, nil
+}
+
+func cloneActivityEvent(event *models.ActivityEvent) *models.ActivityEvent {
+	clone := *event
+	return &clone
+}
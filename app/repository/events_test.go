@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/pointer"
+	"testing"
+	"time"
+)
+
+func testActivityEventsRepository(t *testing.T, s *stores) {
+	var (
+		ctx      = context.Background()
+		id       = mustCreateActivity(t, s.activities, "conformance", "activity with history")
+		now      = time.Now().UTC().Truncate(time.Second)
+		rollback = errors.New("rollback")
+	)
+
+	record := func(ctx context.Context, action string, before, after *string) *models.ActivityEvent {
+		t.Helper()
+		event := &models.ActivityEvent{
+			ActivityID: id,
+			OwnerID:    models.DefaultUserID,
+			ActorID:    models.DefaultUserID,
+			Action:     action,
+			Before:     before,
+			After:      after,
+			CreatedAt:  now,
+		}
+		if _, err := s.events.Create(ctx, event); err != nil {
+			t.Fatalf("unexpected error on create event: %s", err.Error())
+		}
+		return event
+	}
+
+	created := record(ctx, models.ActionCreated, nil, pointer.New(`{"category":"conformance"}`))
+	updated := record(ctx, models.ActionCategoryUpdated, created.After, pointer.New(`{"category":"updated"}`))
+
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		record(ctx, models.ActionDeleted, updated.After, nil)
+		return rollback
+	})
+	if err != rollback {
+		t.Fatalf("unexpected error on rolled back transaction: %v", err)
+	}
+
+	events, err := s.events.GetByActivity(ctx, id)
+	if err != nil {
+		t.Fatalf("unexpected error on get events: %s", err.Error())
+	}
+	if len(events) != 2 || events[0].ID != created.ID || events[1].ID != updated.ID {
+		t.Fatalf("expected created and updated events, got %+v", events)
+	}
+
+	event := events[1]
+	if event.Action != models.ActionCategoryUpdated || event.ActorID != models.DefaultUserID || !event.CreatedAt.Equal(now) {
+		t.Errorf("unexpected event: %+v", event)
+	}
+	if event.Before == nil || *event.Before != `{"category":"conformance"}` || event.After == nil || *event.After != `{"category":"updated"}` {
+		t.Errorf("unexpected values: before=%v, after=%v", event.Before, event.After)
+	}
+	if events[0].Before != nil {
+		t.Errorf("expected no value before created, got %s", *events[0].Before)
+	}
+
+	if events, err = s.events.GetByActivity(ctx, id+1_000_000); err != nil || len(events) != 0 {
+		t.Errorf("expected no events of missing activity, got %v, err=%v", events, err)
+	}
+}
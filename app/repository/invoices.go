@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/ungame/command-time-track/app/ioext"
+	"github.com/ungame/command-time-track/app/models"
+	"time"
+)
+
+type InvoicesRepository interface {
+	Create(ctx context.Context, invoice *models.Invoice) (int64, error)
+	Get(ctx context.Context, id int64) (*models.Invoice, error)
+	GetAll(ctx context.Context) ([]*models.Invoice, error)
+}
+
+type invoicesRepository struct {
+	conn       *sql.DB
+	transactor Transactor
+}
+
+func NewInvoicesRepository(conn *sql.DB) InvoicesRepository {
+	return &invoicesRepository{conn: conn, transactor: NewTransactor(conn)}
+}
+
+// Create saves the invoice and its items in a single transaction.
+func (r *invoicesRepository) Create(ctx context.Context, invoice *models.Invoice) (int64, error) {
+	err := r.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		result, err := queryerFrom(ctx, r.conn).ExecContext(
+			ctx,
+			insertInvoiceQuery,
+			invoice.Client,
+			invoice.From,
+			invoice.To,
+			invoice.HourlyRate,
+			int64(invoice.Rounding/time.Minute),
+			invoice.Total,
+			invoice.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		if invoice.ID, err = result.LastInsertId(); err != nil {
+			return err
+		}
+		for _, item := range invoice.Items {
+			item.InvoiceID = invoice.ID
+			result, err = queryerFrom(ctx, r.conn).ExecContext(
+				ctx,
+				insertInvoiceItemQuery,
+				item.InvoiceID,
+				item.Category,
+				item.Activities,
+				int64(item.Duration/time.Second),
+				item.Amount,
+			)
+			if err != nil {
+				return err
+			}
+			if item.ID, err = result.LastInsertId(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return invoice.ID, err
+}
+
+func (r *invoicesRepository) Get(ctx context.Context, id int64) (*models.Invoice, error) {
+	invoice := new(models.Invoice)
+	row := queryerFrom(ctx, r.conn).QueryRowContext(ctx, `select `+invoiceColumns+` from invoices where id = ?`, id)
+	if err := scanInvoice(row, invoice); err != nil {
+		return invoice, notFound(err)
+	}
+	return invoice, r.loadItems(ctx, map[int64]*models.Invoice{invoice.ID: invoice})
+}
+
+func (r *invoicesRepository) GetAll(ctx context.Context) ([]*models.Invoice, error) {
+	rows, err := queryerFrom(ctx, r.conn).QueryContext(ctx, `select `+invoiceColumns+` from invoices order by id`)
+	if err != nil {
+		return nil, err
+	}
+	invoices := make([]*models.Invoice, 0, 10)
+	byID := make(map[int64]*models.Invoice)
+	for rows.Next() {
+		invoice := new(models.Invoice)
+		if err = scanInvoice(rows, invoice); err != nil {
+			ioext.Close(rows)
+			return invoices, err
+		}
+		invoices = append(invoices, invoice)
+		byID[invoice.ID] = invoice
+	}
+	ioext.Close(rows)
+	if err = rows.Err(); err != nil {
+		return invoices, err
+	}
+	return invoices, r.loadItems(ctx, byID)
+}
+
+// loadItems loads the items of the invoices, in the order they were added.
+func (r *invoicesRepository) loadItems(ctx context.Context, byID map[int64]*models.Invoice) error {
+	if len(byID) == 0 {
+		return nil
+	}
+	args := make([]any, 0, len(byID))
+	for id, invoice := range byID {
+		invoice.Items = make([]*models.InvoiceItem, 0, 1)
+		args = append(args, id)
+	}
+	query := `select ` + invoiceItemColumns + ` from invoice_items where invoice_id in (` + placeholders(len(args)) + `) order by id`
+	rows, err := queryerFrom(ctx, r.conn).QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer ioext.Close(rows)
+	for rows.Next() {
+		var (
+			item     = new(models.InvoiceItem)
+			duration int64
+		)
+		if err = rows.Scan(&item.ID, &item.InvoiceID, &item.Category, &item.Activities, &duration, &item.Amount); err != nil {
+			return err
+		}
+		item.Duration = time.Duration(duration) * time.Second
+		invoice := byID[item.InvoiceID]
+		invoice.Items = append(invoice.Items, item)
+	}
+	return rows.Err()
+}
+
+func scanInvoice(row scanner, invoice *models.Invoice) error {
+	var rounding int64
+	err := row.Scan(
+		&invoice.ID,
+		&invoice.Client,
+		&invoice.From,
+		&invoice.To,
+		&invoice.HourlyRate,
+		&rounding,
+		&invoice.Total,
+		&invoice.CreatedAt,
+	)
+	invoice.Rounding = time.Duration(rounding) * time.Minute
+	return err
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"github.com/ungame/command-time-track/app/models"
+	"sort"
+	"sync"
+)
+
+type memoryInvoicesRepository struct {
+	mutex        sync.RWMutex
+	sequence     int64
+	itemSequence int64
+	invoices     map[int64]*models.Invoice
+}
+
+func NewMemoryInvoicesRepository() InvoicesRepository {
+	return &memoryInvoicesRepository{invoices: make(map[int64]*models.Invoice)}
+}
+
+func (r *memoryInvoicesRepository) Create(ctx context.Context, invoice *models.Invoice) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.sequence++
+	invoice.ID = r.sequence
+	for _, item := range invoice.Items {
+		r.itemSequence++
+		item.ID = r.itemSequence
+		item.InvoiceID = invoice.ID
+	}
+	r.invoices[invoice.ID] = cloneInvoice(invoice)
+
+	id := invoice.ID
+	onRollback(ctx, func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		delete(r.invoices, id)
+	})
+
+	return invoice.ID, nil
+}
+
+func (r *memoryInvoicesRepository) Get(_ context.Context, id int64) (*models.Invoice, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	invoice, ok := r.invoices[id]
+	if !ok {
+		return new(models.Invoice), ErrNotFound
+	}
+	return cloneInvoice(invoice), nil
+}
+
+func (r *memoryInvoicesRepository) GetAll(_ context.Context) ([]*models.Invoice, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	invoices := make([]*models.Invoice, 0, len(r.invoices))
+	for _, invoice := range r.invoices {
+		invoices = append(invoices, cloneInvoice(invoice))
+	}
+	sort.Slice(invoices, func(i, j int) bool {
+		return invoices[i].ID < invoices[j].ID
+	})
+	return invoices, nil
+}
+
+func cloneInvoice(invoice *models.Invoice) *models.Invoice {
+	clone := *invoice
+	clone.Items = make([]*models.InvoiceItem, 0, len(invoice.Items))
+	for _, item := range invoice.Items {
+		itemClone := *item
+		clone.Items = append(clone.Items, &itemClone)
+	}
+	return &clone
+}
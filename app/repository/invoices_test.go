@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"github.com/ungame/command-time-track/app/models"
+	"testing"
+	"time"
+)
+
+func testInvoicesRepository(t *testing.T, s *stores) {
+	var (
+		ctx      = context.Background()
+		now      = time.Now().UTC().Truncate(time.Second)
+		category = uniqueTerm("invoice")
+		first    = mustCreateActivity(t, s.activities, category, "first")
+		second   = mustCreateActivity(t, s.activities, category, "second")
+	)
+
+	invoice := &models.Invoice{
+		Client:     "Acme",
+		From:       now.Add(-time.Hour * 24),
+		To:         now,
+		HourlyRate: 10000,
+		Rounding:   time.Minute * 15,
+		CreatedAt:  now,
+	}
+	invoice.Add(category, time.Minute*50)
+	invoice.Add(category, time.Minute*10)
+	invoice.Add("other", time.Hour)
+
+	if _, err := s.invoices.Create(ctx, invoice); err != nil {
+		t.Fatalf("unexpected error on create invoice: %s", err.Error())
+	}
+
+	existing, err := s.invoices.Get(ctx, invoice.ID)
+	if err != nil {
+		t.Fatalf("unexpected error on get invoice: %s", err.Error())
+	}
+	if existing.Client != "Acme" || existing.Rounding != time.Minute*15 || existing.Total != 20000 || !existing.To.Equal(now) {
+		t.Errorf("unexpected invoice: %+v", existing)
+	}
+	if len(existing.Items) != 2 || existing.Items[0].Category != category || existing.Items[0].Activities != 2 ||
+		existing.Items[0].Duration != time.Hour || existing.Items[0].Amount != 10000 {
+		t.Errorf("unexpected invoice items: %+v", existing.Items)
+	}
+
+	rows, err := s.activities.SetInvoice(ctx, invoice.ID, []int64{first, second})
+	if err != nil || rows != 2 {
+		t.Fatalf("unexpected set invoice: rows=%d, err=%v", rows, err)
+	}
+	if rows, err = s.activities.SetInvoice(ctx, invoice.ID, []int64{first}); err != nil || rows != 0 {
+		t.Errorf("expected invoiced activity to keep its invoice: rows=%d, err=%v", rows, err)
+	}
+
+	activity, err := s.activities.Get(ctx, first)
+	if err != nil {
+		t.Fatalf("unexpected error on get activity: %s", err.Error())
+	}
+	if activity.InvoiceID == nil || *activity.InvoiceID != invoice.ID {
+		t.Errorf("unexpected invoice of activity: %v", activity.InvoiceID)
+	}
+
+	activity.InvoiceID = nil
+	activity.Description = "updated"
+	if _, err = s.activities.Update(ctx, activity); err != nil {
+		t.Fatalf("unexpected error on update activity: %s", err.Error())
+	}
+	if activity, err = s.activities.Get(ctx, first); err != nil || activity.InvoiceID == nil {
+		t.Errorf("expected update to keep the invoice: %+v, err=%v", activity, err)
+	}
+
+	invoiced := false
+	found, err := s.activities.Find(ctx, &ActivitiesQuery{Category: category, Invoiced: &invoiced})
+	if err != nil {
+		t.Fatalf("unexpected error on find activities: %s", err.Error())
+	}
+	if len(found) != 0 {
+		t.Errorf("unexpected activities not invoiced: %v", ids(found))
+	}
+
+	invoices, err := s.invoices.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error on get invoices: %s", err.Error())
+	}
+	if last := invoices[len(invoices)-1]; last.ID != invoice.ID || len(last.Items) != 2 {
+		t.Errorf("unexpected last invoice: %+v", last)
+	}
+}
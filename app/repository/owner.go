@@ -0,0 +1,22 @@
+package repository
+
+import "context"
+
+type ownerKey struct{}
+
+// WithOwner scopes the activities repositories called with the returned context to the activities of the user,
+// activities they create belong to the user. Zero removes the scope, letting callers see every activity.
+func WithOwner(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, ownerKey{}, userID)
+}
+
+// AllOwners removes the owner scope of the context, for checks that must consider the activities of every user.
+func AllOwners(ctx context.Context) context.Context {
+	return WithOwner(ctx, 0)
+}
+
+// OwnerFrom returns the user the context is scoped to, zero when not scoped.
+func OwnerFrom(ctx context.Context) int64 {
+	userID, _ := ctx.Value(ownerKey{}).(int64)
+	return userID
+}
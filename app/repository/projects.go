@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/ungame/command-time-track/app/ioext"
+	"github.com/ungame/command-time-track/app/models"
+)
+
+type ProjectsRepository interface {
+	Create(ctx context.Context, project *models.Project) (int64, error)
+	Update(ctx context.Context, project *models.Project) (int64, error)
+	Delete(ctx context.Context, id int64) (int64, error)
+	Get(ctx context.Context, id int64) (*models.Project, error)
+	GetAll(ctx context.Context) ([]*models.Project, error)
+	GetByClient(ctx context.Context, clientID int64) ([]*models.Project, error)
+}
+
+type projectsRepository struct {
+	conn *sql.DB
+}
+
+func NewProjectsRepository(conn *sql.DB) ProjectsRepository {
+	return &projectsRepository{conn: conn}
+}
+
+func (r *projectsRepository) Create(ctx context.Context, project *models.Project) (int64, error) {
+	result, err := queryerFrom(ctx, r.conn).ExecContext(ctx, insertProjectQuery, project.ClientID, project.Name, project.HourlyRate, project.CreatedAt, project.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	if project.ID, err = result.LastInsertId(); err != nil {
+		return 0, err
+	}
+	return project.ID, nil
+}
+
+func (r *projectsRepository) Update(ctx context.Context, project *models.Project) (int64, error) {
+	result, err := queryerFrom(ctx, r.conn).ExecContext(ctx, updateProjectQuery, project.ClientID, project.Name, project.HourlyRate, project.UpdatedAt, project.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *projectsRepository) Delete(ctx context.Context, id int64) (int64, error) {
+	result, err := queryerFrom(ctx, r.conn).ExecContext(ctx, deleteProjectQuery, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *projectsRepository) Get(ctx context.Context, id int64) (*models.Project, error) {
+	project := new(models.Project)
+	row := queryerFrom(ctx, r.conn).QueryRowContext(ctx, `select `+projectColumns+` from projects where id = ?`, id)
+	return project, notFound(scanProject(row, project))
+}
+
+func (r *projectsRepository) GetAll(ctx context.Context) ([]*models.Project, error) {
+	return r.queryProjects(ctx, `select `+projectColumns+` from projects order by name, id`)
+}
+
+func (r *projectsRepository) GetByClient(ctx context.Context, clientID int64) ([]*models.Project, error) {
+	return r.queryProjects(ctx, `select `+projectColumns+` from projects where client_id = ? order by name, id`, clientID)
+}
+
+func (r *projectsRepository) queryProjects(ctx context.Context, query string, args ...any) ([]*models.Project, error) {
+	rows, err := queryerFrom(ctx, r.conn).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer ioext.Close(rows)
+	projects := make([]*models.Project, 0, 10)
+	for rows.Next() {
+		project := new(models.Project)
+		if err = scanProject(rows, project); err != nil {
+			return projects, err
+		}
+		projects = append(projects, project)
+	}
+	return projects, rows.Err()
+}
+
+func scanProject(row scanner, project *models.Project) error {
+	return row.Scan(&project.ID, &project.ClientID, &project.Name, &project.HourlyRate, &project.CreatedAt, &project.UpdatedAt)
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/pointer"
+	"sort"
+	"sync"
+)
+
+type memoryProjectsRepository struct {
+	mutex    sync.RWMutex
+	sequence int64
+	projects map[int64]*models.Project
+}
+
+func NewMemoryProjectsRepository() ProjectsRepository {
+	return &memoryProjectsRepository{projects: make(map[int64]*models.Project)}
+}
+
+func (r *memoryProjectsRepository) Create(_ context.Context, project *models.Project) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.sequence++
+	project.ID = r.sequence
+	r.projects[project.ID] = cloneProject(project)
+
+	return project.ID, nil
+}
+
+func (r *memoryProjectsRepository) Update(_ context.Context, project *models.Project) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existing, ok := r.projects[project.ID]
+	if !ok {
+		return 0, nil
+	}
+
+	updated := cloneProject(project)
+	updated.CreatedAt = existing.CreatedAt
+	r.projects[project.ID] = updated
+
+	return 1, nil
+}
+
+func (r *memoryProjectsRepository) Delete(_ context.Context, id int64) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.projects[id]; !ok {
+		return 0, nil
+	}
+	delete(r.projects, id)
+
+	return 1, nil
+}
+
+func (r *memoryProjectsRepository) Get(_ context.Context, id int64) (*models.Project, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	project, ok := r.projects[id]
+	if !ok {
+		return new(models.Project), ErrNotFound
+	}
+	return cloneProject(project), nil
+}
+
+func (r *memoryProjectsRepository) GetAll(_ context.Context) ([]*models.Project, error) {
+	return r.filter(func(*models.Project) bool { return true }), nil
+}
+
+func (r *memoryProjectsRepository) GetByClient(_ context.Context, clientID int64) ([]*models.Project, error) {
+	return r.filter(func(project *models.Project) bool { return project.ClientID == clientID }), nil
+}
+
+func (r *memoryProjectsRepository) filter(match func(project *models.Project) bool) []*models.Project {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	projects := make([]*models.Project, 0, 10)
+	for _, project := range r.projects {
+		if match(project) {
+			projects = append(projects, cloneProject(project))
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].Name != projects[j].Name {
+			return projects[i].Name < projects[j].Name
+		}
+		return projects[i].ID < projects[j].ID
+	})
+	return projects
+}
+
+func cloneProject(project *models.Project) *models.Project {
+	clone := *project
+	if project.HourlyRate != nil {
+		clone.HourlyRate = pointer.New(*project.HourlyRate)
+	}
+	return &clone
+}
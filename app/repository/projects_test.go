@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/pointer"
+	"testing"
+	"time"
+)
+
+func testProjectsRepository(t *testing.T, s *stores) {
+	var (
+		ctx = context.Background()
+		now = time.Now().UTC().Truncate(time.Second)
+	)
+
+	client := &models.Client{Name: uniqueTerm("client"), HourlyRate: pointer.New(models.Cents(12050)), CreatedAt: now, UpdatedAt: now}
+	if _, err := s.clients.Create(ctx, client); err != nil {
+		t.Fatalf("unexpected error on create client: %s", err.Error())
+	}
+	t.Cleanup(func() { _, _ = s.clients.Delete(ctx, client.ID) })
+
+	project := &models.Project{ClientID: client.ID, Name: uniqueTerm("project"), CreatedAt: now, UpdatedAt: now}
+	if _, err := s.projects.Create(ctx, project); err != nil {
+		t.Fatalf("unexpected error on create project: %s", err.Error())
+	}
+	t.Cleanup(func() { _, _ = s.projects.Delete(ctx, project.ID) })
+
+	existingClient, err := s.clients.Get(ctx, client.ID)
+	if err != nil {
+		t.Fatalf("unexpected error on get client: %s", err.Error())
+	}
+	if existingClient.Name != client.Name || existingClient.HourlyRate == nil || *existingClient.HourlyRate != 12050 {
+		t.Errorf("unexpected client: %+v", existingClient)
+	}
+
+	project.HourlyRate = pointer.New(models.Cents(9000))
+	project.UpdatedAt = now.Add(time.Minute)
+	if rows, err := s.projects.Update(ctx, project); err != nil || rows != 1 {
+		t.Fatalf("unexpected update project: rows=%d, err=%v", rows, err)
+	}
+
+	existingProject, err := s.projects.Get(ctx, project.ID)
+	if err != nil {
+		t.Fatalf("unexpected error on get project: %s", err.Error())
+	}
+	if existingProject.ClientID != client.ID || existingProject.HourlyRate == nil || *existingProject.HourlyRate != 9000 ||
+		!existingProject.UpdatedAt.Equal(project.UpdatedAt) || !existingProject.CreatedAt.Equal(now) {
+		t.Errorf("unexpected project: %+v", existingProject)
+	}
+
+	byClient, err := s.projects.GetByClient(ctx, client.ID)
+	if err != nil || len(byClient) != 1 || byClient[0].ID != project.ID {
+		t.Errorf("unexpected projects by client: projects=%v, err=%v", byClient, err)
+	}
+
+	clients, err := s.clients.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error on get all clients: %s", err.Error())
+	}
+	found := false
+	for _, c := range clients {
+		found = found || c.ID == client.ID
+	}
+	if !found {
+		t.Errorf("expected client %d in all clients", client.ID)
+	}
+
+	activity := &models.Activity{
+		Category:   uniqueTerm("billed"),
+		StartedAt:  now,
+		UpdatedAt:  now,
+		ProjectID:  pointer.New(project.ID),
+		HourlyRate: pointer.New(models.Cents(15000)),
+	}
+	if _, err = s.activities.Create(ctx, activity); err != nil {
+		t.Fatalf("unexpected error on create activity with project: %s", err.Error())
+	}
+	t.Cleanup(func() { _, _ = s.activities.Delete(ctx, activity.ID) })
+
+	byProject, err := s.activities.Find(ctx, &ActivitiesQuery{ProjectID: pointer.New(project.ID)})
+	if err != nil {
+		t.Fatalf("unexpected error on find activities by project: %s", err.Error())
+	}
+	if len(byProject) != 1 || byProject[0].ID != activity.ID || *byProject[0].ProjectID != project.ID || *byProject[0].HourlyRate != 15000 {
+		t.Errorf("unexpected activities by project: %v", ids(byProject))
+	}
+
+	activity.ProjectID, activity.HourlyRate = nil, nil
+	if _, err = s.activities.Update(ctx, activity); err != nil {
+		t.Fatalf("unexpected error on remove activity project: %s", err.Error())
+	}
+	if existing, _ := s.activities.Get(ctx, activity.ID); existing.ProjectID != nil || existing.HourlyRate != nil {
+		t.Errorf("expected activity without project and rate, got project=%v, rate=%v", existing.ProjectID, existing.HourlyRate)
+	}
+
+	if rows, err := s.projects.Delete(ctx, project.ID); err != nil || rows != 1 {
+		t.Errorf("unexpected delete project: rows=%d, err=%v", rows, err)
+	}
+	if _, err = s.projects.Get(ctx, project.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected no rows on get deleted project, got %v", err)
+	}
+	if rows, err := s.clients.Delete(ctx, client.ID); err != nil || rows != 1 {
+		t.Errorf("unexpected delete client: rows=%d, err=%v", rows, err)
+	}
+}
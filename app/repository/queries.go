@@ -1,7 +1,73 @@
 package repository
 
 const (
-	insertActivityQuery = `insert into activities (category, description, started_at, updated_at) values (?, ?, ?, ?)`
-	updateActivityQuery = `update activities set category = ?, description = ?, status = ?, updated_at = ?, finished_at = ? where id = ?`
-	deleteActivityQuery = `delete from activities where id = ?`
+	activityColumns     = `id, category, description, status, started_at, updated_at, finished_at, project_id, hourly_rate, invoice_id, owner_id, deleted_at`
+	insertActivityQuery = `insert into activities (category, description, status, started_at, updated_at, finished_at, project_id, hourly_rate, invoice_id, owner_id) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	// updates and deletes take the owner twice, zero matches activities of every owner, activities in the trash
+	// are only restored or purged
+	updateActivityQuery  = `update activities set category = ?, description = ?, status = ?, started_at = ?, updated_at = ?, finished_at = ?, project_id = ?, hourly_rate = ? where id = ? and deleted_at is null and (? = 0 or owner_id = ?)`
+	deleteActivityQuery  = `update activities set deleted_at = ? where id = ? and deleted_at is null and (? = 0 or owner_id = ?)`
+	restoreActivityQuery = `update activities set deleted_at = null where id = ? and deleted_at is not null and (? = 0 or owner_id = ?)`
+	purgeActivityQuery   = `delete from activities where id = ? and deleted_at is not null and (? = 0 or owner_id = ?)`
+	// the invoice of an activity is only set once, updates never change it
+	setActivityInvoiceQuery = `update activities set invoice_id = ? where invoice_id is null and deleted_at is null and (? = 0 or owner_id = ?) and id in `
+
+	activityEventColumns     = `id, activity_id, owner_id, actor_id, action, before_value, after_value, created_at`
+	insertActivityEventQuery = `insert into activity_events (activity_id, owner_id, actor_id, action, before_value, after_value, created_at) values (?, ?, ?, ?, ?, ?, ?)`
+
+	userColumns         = `id, name, role, created_at`
+	insertUserQuery     = `insert into users (name, role, created_at) values (?, ?, ?)`
+	updateUserRoleQuery = `update users set role = ? where id = ?`
+	// lockUserQuery changes nothing, it locks the row of the user until the transaction ends
+	lockUserQuery = `update users set id = id where id = ?`
+
+	teamColumns           = `id, name, created_at`
+	insertTeamQuery       = `insert into teams (name, created_at) values (?, ?)`
+	insertTeamMemberQuery = `insert into team_members (team_id, user_id) values (?, ?)`
+	deleteTeamMemberQuery = `delete from team_members where team_id = ? and user_id = ?`
+	// teammates are the users sharing a team with the user, the user included
+	selectTeammatesQuery = `select distinct m.user_id from team_members m join team_members t on t.team_id = m.team_id where t.user_id = ? order by m.user_id`
+
+	tokenColumns     = `id, user_id, name, prefix, hash, created_at, revoked_at`
+	insertTokenQuery = `insert into api_tokens (user_id, name, prefix, hash, created_at) values (?, ?, ?, ?, ?)`
+	revokeTokenQuery = `update api_tokens set revoked_at = ? where id = ? and user_id = ? and revoked_at is null`
+
+	webhookColumns     = `id, owner_id, url, secret, events, active, created_at, updated_at`
+	insertWebhookQuery = `insert into webhooks (owner_id, url, secret, events, active, created_at, updated_at) values (?, ?, ?, ?, ?, ?, ?)`
+	updateWebhookQuery = `update webhooks set url = ?, events = ?, active = ?, updated_at = ? where id = ?`
+	deleteWebhookQuery = `delete from webhooks where id = ?`
+
+	webhookDeliveryColumns     = `id, webhook_id, event_id, event_type, payload, status, attempts, response_status, last_error, next_attempt_at, created_at, updated_at`
+	insertWebhookDeliveryQuery = `insert into webhook_deliveries (webhook_id, event_id, event_type, payload, status, attempts, response_status, last_error, next_attempt_at, created_at, updated_at) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	updateWebhookDeliveryQuery = `update webhook_deliveries set status = ?, attempts = ?, response_status = ?, last_error = ?, next_attempt_at = ?, updated_at = ? where id = ?`
+
+	webhookEventColumns     = `event_id, event_type, owner_id, data, created_at`
+	insertWebhookEventQuery = `insert into webhook_outbox (event_id, event_type, owner_id, data, created_at) values (?, ?, ?, ?, ?)`
+	deleteWebhookEventQuery = `delete from webhook_outbox where event_id = ?`
+
+	invoiceColumns         = `id, client, period_from, period_to, hourly_rate, rounding_minutes, total, created_at`
+	insertInvoiceQuery     = `insert into invoices (client, period_from, period_to, hourly_rate, rounding_minutes, total, created_at) values (?, ?, ?, ?, ?, ?, ?)`
+	invoiceItemColumns     = `id, invoice_id, category, activities, duration_seconds, amount`
+	insertInvoiceItemQuery = `insert into invoice_items (invoice_id, category, activities, duration_seconds, amount) values (?, ?, ?, ?, ?)`
+
+	intervalColumns      = `id, activity_id, started_at, finished_at`
+	insertIntervalQuery  = `insert into activity_intervals (activity_id, started_at, finished_at) values (?, ?, ?)`
+	updateIntervalQuery  = `update activity_intervals set started_at = ?, finished_at = ? where id = ? and activity_id = ?`
+	deleteIntervalsQuery = `delete from activity_intervals where activity_id = ?`
+
+	selectTagQuery          = `select id from tags where name = ?`
+	insertTagQuery          = `insert into tags (name) values (?)`
+	insertActivityTagQuery  = `insert into activity_tags (activity_id, tag_id) values (?, ?)`
+	deleteActivityTagsQuery = `delete from activity_tags where activity_id = ?`
+	activityHasTagCondition = `exists (select 1 from activity_tags at join tags t on t.id = at.tag_id where at.activity_id = activities.id and t.name = ?)`
+
+	clientColumns     = `id, name, hourly_rate, created_at, updated_at`
+	insertClientQuery = `insert into clients (name, hourly_rate, created_at, updated_at) values (?, ?, ?, ?)`
+	updateClientQuery = `update clients set name = ?, hourly_rate = ?, updated_at = ? where id = ?`
+	deleteClientQuery = `delete from clients where id = ?`
+
+	projectColumns     = `id, client_id, name, hourly_rate, created_at, updated_at`
+	insertProjectQuery = `insert into projects (client_id, name, hourly_rate, created_at, updated_at) values (?, ?, ?, ?, ?)`
+	updateProjectQuery = `update projects set client_id = ?, name = ?, hourly_rate = ?, updated_at = ? where id = ?`
+	deleteProjectQuery = `delete from projects where id = ?`
 )
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/ungame/command-time-track/app/models"
+	"strings"
+	"time"
+)
+
+const (
+	SortID        = "id"
+	SortStartedAt = "started_at"
+	SortUpdatedAt = "updated_at"
+	SortCategory  = "category"
+
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+type ActivitiesQuery struct {
+	From      *time.Time
+	To        *time.Time
+	Category  string
+	ProjectID *int64
+	Tags      []string
+	Status    *models.Status
+	Invoiced  *bool
+	Sort      string
+	Order     string
+	Limit     int
+	After     *Cursor
+}
+
+func (q *ActivitiesQuery) Validate() error {
+	switch q.Sort {
+	case "":
+		q.Sort = SortID
+	case SortID, SortStartedAt, SortUpdatedAt, SortCategory:
+	default:
+		return fmt.Errorf("invalid sort field: %s", q.Sort)
+	}
+	switch strings.ToLower(q.Order) {
+	case "":
+		q.Order = OrderAsc
+	case OrderAsc, OrderDesc:
+		q.Order = strings.ToLower(q.Order)
+	default:
+		return fmt.Errorf("invalid sort order: %s", q.Order)
+	}
+	if q.Limit < 0 {
+		return fmt.Errorf("invalid limit: %d", q.Limit)
+	}
+	if q.From != nil && q.To != nil && !q.From.Before(*q.To) {
+		return fmt.Errorf("invalid time range: from must be before to")
+	}
+	if q.After != nil && q.After.Sort != q.Sort {
+		return fmt.Errorf("invalid cursor: sorted by %s, not %s", q.After.Sort, q.Sort)
+	}
+	return nil
+}
+
+// Cursor points to the last activity of a page, the next page starts right after it
+// in the query sort order.
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int64  `json:"id"`
+}
+
+func NewCursor(sort string, activity *models.Activity) *Cursor {
+	return &Cursor{Sort: sort, Value: sortKey(sort, activity), ID: activity.ID}
+}
+
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %s", s)
+	}
+	cursor := new(Cursor)
+	if err = json.Unmarshal(data, cursor); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %s", s)
+	}
+	if _, err = cursor.value(); err != nil {
+		return nil, err
+	}
+	return cursor, nil
+}
+
+func (c *Cursor) value() (any, error) {
+	switch c.Sort {
+	case SortStartedAt, SortUpdatedAt:
+		t, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor value: %s", c.Value)
+		}
+		return t.UTC(), nil
+	case SortCategory:
+		return c.Value, nil
+	case SortID:
+		return c.ID, nil
+	default:
+		return nil, fmt.Errorf("invalid cursor sort field: %s", c.Sort)
+	}
+}
+
+func sortKey(sort string, activity *models.Activity) string {
+	switch sort {
+	case SortStartedAt:
+		return activity.StartedAt.UTC().Format(time.RFC3339Nano)
+	case SortUpdatedAt:
+		return activity.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	case SortCategory:
+		return activity.Category
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/ungame/command-time-track/app/ioext"
+	"github.com/ungame/command-time-track/app/models"
+)
+
+type TeamsRepository interface {
+	Create(ctx context.Context, team *models.Team) (int64, error)
+	Get(ctx context.Context, id int64) (*models.Team, error)
+	GetAll(ctx context.Context) ([]*models.Team, error)
+	AddMember(ctx context.Context, teamID, userID int64) error
+	// RemoveMember removes the user from the team, returning the number of memberships removed.
+	RemoveMember(ctx context.Context, teamID, userID int64) (int64, error)
+	// Teammates returns the ids of the users sharing a team with the user, the user included when in a team.
+	Teammates(ctx context.Context, userID int64) ([]int64, error)
+}
+
+type teamsRepository struct {
+	conn *sql.DB
+}
+
+func NewTeamsRepository(conn *sql.DB) TeamsRepository {
+	return &teamsRepository{conn: conn}
+}
+
+func (r *teamsRepository) Create(ctx context.Context, team *models.Team) (int64, error) {
+	result, err := queryerFrom(ctx, r.conn).ExecContext(ctx, insertTeamQuery, team.Name, team.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	if team.ID, err = result.LastInsertId(); err != nil {
+		return 0, err
+	}
+	return team.ID, nil
+}
+
+func (r *teamsRepository) Get(ctx context.Context, id int64) (*models.Team, error) {
+	team := new(models.Team)
+	row := queryerFrom(ctx, r.conn).QueryRowContext(ctx, `select `+teamColumns+` from teams where id = ?`, id)
+	if err := row.Scan(&team.ID, &team.Name, &team.CreatedAt); err != nil {
+		return team, notFound(err)
+	}
+	return team, r.loadMembers(ctx, map[int64]*models.Team{team.ID: team})
+}
+
+func (r *teamsRepository) GetAll(ctx context.Context) ([]*models.Team, error) {
+	rows, err := queryerFrom(ctx, r.conn).QueryContext(ctx, `select `+teamColumns+` from teams order by id`)
+	if err != nil {
+		return nil, err
+	}
+	defer ioext.Close(rows)
+	var (
+		teams = make([]*models.Team, 0, 10)
+		byID  = make(map[int64]*models.Team)
+	)
+	for rows.Next() {
+		team := new(models.Team)
+		if err = rows.Scan(&team.ID, &team.Name, &team.CreatedAt); err != nil {
+			return teams, err
+		}
+		teams = append(teams, team)
+		byID[team.ID] = team
+	}
+	if err = rows.Err(); err != nil {
+		return teams, err
+	}
+	return teams, r.loadMembers(ctx, byID)
+}
+
+func (r *teamsRepository) loadMembers(ctx context.Context, teams map[int64]*models.Team) error {
+	if len(teams) == 0 {
+		return nil
+	}
+	rows, err := queryerFrom(ctx, r.conn).QueryContext(ctx, `select team_id, user_id from team_members order by team_id, user_id`)
+	if err != nil {
+		return err
+	}
+	defer ioext.Close(rows)
+	for rows.Next() {
+		var teamID, userID int64
+		if err = rows.Scan(&teamID, &userID); err != nil {
+			return err
+		}
+		if team, ok := teams[teamID]; ok {
+			team.Members = append(team.Members, userID)
+		}
+	}
+	return rows.Err()
+}
+
+func (r *teamsRepository) AddMember(ctx context.Context, teamID, userID int64) error {
+	_, err := queryerFrom(ctx, r.conn).ExecContext(ctx, insertTeamMemberQuery, teamID, userID)
+	return err
+}
+
+func (r *teamsRepository) RemoveMember(ctx context.Context, teamID, userID int64) (int64, error) {
+	result, err := queryerFrom(ctx, r.conn).ExecContext(ctx, deleteTeamMemberQuery, teamID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *teamsRepository) Teammates(ctx context.Context, userID int64) ([]int64, error) {
+	rows, err := queryerFrom(ctx, r.conn).QueryContext(ctx, selectTeammatesQuery, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer ioext.Close(rows)
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return ids, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
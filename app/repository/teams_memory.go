@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/ungame/command-time-track/app/models"
+	"sort"
+	"sync"
+)
+
+type memoryTeamsRepository struct {
+	mutex    sync.RWMutex
+	sequence int64
+	teams    map[int64]*models.Team
+}
+
+func NewMemoryTeamsRepository() TeamsRepository {
+	return &memoryTeamsRepository{teams: make(map[int64]*models.Team)}
+}
+
+func (r *memoryTeamsRepository) Create(_ context.Context, team *models.Team) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.sequence++
+	team.ID = r.sequence
+	r.teams[team.ID] = cloneTeam(team)
+
+	return team.ID, nil
+}
+
+func (r *memoryTeamsRepository) Get(_ context.Context, id int64) (*models.Team, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	team, ok := r.teams[id]
+	if !ok {
+		return new(models.Team), ErrNotFound
+	}
+	return cloneTeam(team), nil
+}
+
+func (r *memoryTeamsRepository) GetAll(_ context.Context) ([]*models.Team, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	teams := make([]*models.Team, 0, len(r.teams))
+	for _, team := range r.teams {
+		teams = append(teams, cloneTeam(team))
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		return teams[i].ID < teams[j].ID
+	})
+	return teams, nil
+}
+
+func (r *memoryTeamsRepository) AddMember(_ context.Context, teamID, userID int64) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	team, ok := r.teams[teamID]
+	if !ok {
+		return fmt.Errorf("team %d does not exist", teamID)
+	}
+	for _, member := range team.Members {
+		if member == userID {
+			return fmt.Errorf("user %d is already a member of team %d", userID, teamID)
+		}
+	}
+	team.Members = append(team.Members, userID)
+	sort.Slice(team.Members, func(i, j int) bool {
+		return team.Members[i] < team.Members[j]
+	})
+	return nil
+}
+
+func (r *memoryTeamsRepository) RemoveMember(_ context.Context, teamID, userID int64) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	team, ok := r.teams[teamID]
+	if !ok {
+		return 0, nil
+	}
+	for i, member := range team.Members {
+		if member == userID {
+			team.Members = append(team.Members[:i], team.Members[i+1:]...)
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (r *memoryTeamsRepository) Teammates(_ context.Context, userID int64) ([]int64, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	seen := make(map[int64]bool)
+	for _, team := range r.teams {
+		for _, member := range team.Members {
+			if member != userID {
+				continue
+			}
+			for _, teammate := range team.Members {
+				seen[teammate] = true
+			}
+			break
+		}
+	}
+	ids := make([]int64, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	return ids, nil
+}
+
+func cloneTeam(team *models.Team) *models.Team {
+	clone := *team
+	clone.Members = append([]int64(nil), team.Members...)
+	return &clone
+}
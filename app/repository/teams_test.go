@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/ungame/command-time-track/app/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testTeamsRepository(t *testing.T, s *stores) {
+	var (
+		ctx   = context.Background()
+		alice = mustCreateUser(t, s.users, "alice")
+		bob   = mustCreateUser(t, s.users, "bob")
+		carol = mustCreateUser(t, s.users, "carol")
+		team  = &models.Team{Name: uniqueTerm("team"), CreatedAt: time.Now().UTC().Truncate(time.Second)}
+	)
+
+	if _, err := s.teams.Create(ctx, team); err != nil {
+		t.Fatalf("unexpected error on create team: %s", err.Error())
+	}
+	for _, user := range []*models.User{alice, bob} {
+		if err := s.teams.AddMember(ctx, team.ID, user.ID); err != nil {
+			t.Fatalf("unexpected error on add member: %s", err.Error())
+		}
+	}
+	if err := s.teams.AddMember(ctx, team.ID, alice.ID); err == nil {
+		t.Errorf("expected error adding a member twice")
+	}
+
+	found, err := s.teams.Get(ctx, team.ID)
+	if err != nil {
+		t.Fatalf("unexpected error on get team: %s", err.Error())
+	}
+	if found.Name != team.Name || !reflect.DeepEqual(found.Members, []int64{alice.ID, bob.ID}) {
+		t.Errorf("unexpected team: %+v", found)
+	}
+	if _, err = s.teams.Get(ctx, team.ID+1000); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected missing team, got %v", err)
+	}
+
+	if teammates, err := s.teams.Teammates(ctx, alice.ID); err != nil || !reflect.DeepEqual(teammates, []int64{alice.ID, bob.ID}) {
+		t.Errorf("unexpected teammates: %v, err=%v", teammates, err)
+	}
+	if teammates, err := s.teams.Teammates(ctx, carol.ID); err != nil || len(teammates) != 0 {
+		t.Errorf("expected no teammates, got %v, err=%v", teammates, err)
+	}
+
+	if rows, err := s.teams.RemoveMember(ctx, team.ID, bob.ID); err != nil || rows != 1 {
+		t.Errorf("unexpected remove member: rows=%d, err=%v", rows, err)
+	}
+	if rows, err := s.teams.RemoveMember(ctx, team.ID, bob.ID); err != nil || rows != 0 {
+		t.Errorf("expected removed member not removed again: rows=%d, err=%v", rows, err)
+	}
+
+	teams, err := s.teams.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error on get teams: %s", err.Error())
+	}
+	for _, listed := range teams {
+		if listed.ID == team.ID && !reflect.DeepEqual(listed.Members, []int64{alice.ID}) {
+			t.Errorf("unexpected listed team: %+v", listed)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/ungame/command-time-track/app/ioext"
+	"github.com/ungame/command-time-track/app/models"
+	"time"
+)
+
+type TokensRepository interface {
+	Create(ctx context.Context, token *models.Token) (int64, error)
+	GetByHash(ctx context.Context, hash string) (*models.Token, error)
+	GetByUser(ctx context.Context, userID int64) ([]*models.Token, error)
+	// Revoke revokes a token of the user not revoked yet, returning the number of tokens revoked.
+	Revoke(ctx context.Context, userID, id int64, at time.Time) (int64, error)
+}
+
+type tokensRepository struct {
+	conn *sql.DB
+}
+
+func NewTokensRepository(conn *sql.DB) TokensRepository {
+	return &tokensRepository{conn: conn}
+}
+
+func (r *tokensRepository) Create(ctx context.Context, token *models.Token) (int64, error) {
+	result, err := queryerFrom(ctx, r.conn).ExecContext(ctx, insertTokenQuery, token.UserID, token.Name, token.Prefix, token.Hash, token.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	if token.ID, err = result.LastInsertId(); err != nil {
+		return 0, err
+	}
+	return token.ID, nil
+}
+
+func (r *tokensRepository) GetByHash(ctx context.Context, hash string) (*models.Token, error) {
+	token := new(models.Token)
+	row := queryerFrom(ctx, r.conn).QueryRowContext(ctx, `select `+tokenColumns+` from api_tokens where hash = ?`, hash)
+	return token, notFound(scanToken(row, token))
+}
+
+func (r *tokensRepository) GetByUser(ctx context.Context, userID int64) ([]*models.Token, error) {
+	rows, err := queryerFrom(ctx, r.conn).QueryContext(ctx, `select `+tokenColumns+` from api_tokens where user_id = ? order by id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer ioext.Close(rows)
+	tokens := make([]*models.Token, 0, 10)
+	for rows.Next() {
+		token := new(models.Token)
+		if err = scanToken(rows, token); err != nil {
+			return tokens, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+func (r *tokensRepository) Revoke(ctx context.Context, userID, id int64, at time.Time) (int64, error) {
+	result, err := queryerFrom(ctx, r.conn).ExecContext(ctx, revokeTokenQuery, at, id, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func scanToken(row scanner, token *models.Token) error {
+	return row.Scan(&token.ID, &token.UserID, &token.Name, &token.Prefix, &token.Hash, &token.CreatedAt, &token.RevokedAt)
+}
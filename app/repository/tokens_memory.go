@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"github.com/ungame/command-time-track/app/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+type memoryTokensRepository struct {
+	mutex    sync.RWMutex
+	sequence int64
+	tokens   map[int64]*models.Token
+}
+
+func NewMemoryTokensRepository() TokensRepository {
+	return &memoryTokensRepository{tokens: make(map[int64]*models.Token)}
+}
+
+func (r *memoryTokensRepository) Create(_ context.Context, token *models.Token) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.sequence++
+	token.ID = r.sequence
+	r.tokens[token.ID] = cloneToken(token)
+
+	return token.ID, nil
+}
+
+func (r *memoryTokensRepository) GetByHash(_ context.Context, hash string) (*models.Token, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, token := range r.tokens {
+		if token.Hash == hash {
+			return cloneToken(token), nil
+		}
+	}
+	return new(models.Token), ErrNotFound
+}
+
+func (r *memoryTokensRepository) GetByUser(_ context.Context, userID int64) ([]*models.Token, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	tokens := make([]*models.Token, 0)
+	for _, token := range r.tokens {
+		if token.UserID == userID {
+			tokens = append(tokens, cloneToken(token))
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].ID < tokens[j].ID
+	})
+	return tokens, nil
+}
+
+func (r *memoryTokensRepository) Revoke(_ context.Context, userID, id int64, at time.Time) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	token, ok := r.tokens[id]
+	if !ok || token.UserID != userID || token.Revoked() {
+		return 0, nil
+	}
+	token.RevokedAt = &at
+	return 1, nil
+}
+
+func cloneToken(token *models.Token) *models.Token {
+	clone := *token
+	if token.RevokedAt != nil {
+		revokedAt := *token.RevokedAt
+		clone.RevokedAt = &revokedAt
+	}
+	return &clone
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/ungame/command-time-track/app/models"
+	"testing"
+	"time"
+)
+
+func testTokensRepository(t *testing.T, s *stores) {
+	var (
+		ctx  = context.Background()
+		user = mustCreateUser(t, s.users, "tokens")
+	)
+
+	token, secret, err := models.NewToken(user.ID, "laptop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token.CreatedAt = token.CreatedAt.Truncate(time.Second)
+	if _, err = s.tokens.Create(ctx, token); err != nil {
+		t.Fatalf("unexpected error on create token: %s", err.Error())
+	}
+
+	found, err := s.tokens.GetByHash(ctx, models.HashToken(secret))
+	if err != nil {
+		t.Fatalf("unexpected error on get token: %s", err.Error())
+	}
+	if found.ID != token.ID || found.UserID != user.ID || found.Name != "laptop" || found.Prefix != token.Prefix || found.Revoked() {
+		t.Errorf("unexpected token: %+v", found)
+	}
+	if _, err = s.tokens.GetByHash(ctx, models.HashToken(secret+"x")); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected missing token, got %v", err)
+	}
+
+	if rows, err := s.tokens.Revoke(ctx, models.DefaultUserID, token.ID, time.Now().UTC()); err != nil || rows != 0 {
+		t.Errorf("expected token of another user not revoked, got rows=%d, err=%v", rows, err)
+	}
+	if rows, err := s.tokens.Revoke(ctx, user.ID, token.ID, time.Now().UTC()); err != nil || rows != 1 {
+		t.Errorf("expected token revoked, got rows=%d, err=%v", rows, err)
+	}
+	if rows, err := s.tokens.Revoke(ctx, user.ID, token.ID, time.Now().UTC()); err != nil || rows != 0 {
+		t.Errorf("expected revoked token not revoked again, got rows=%d, err=%v", rows, err)
+	}
+
+	tokens, err := s.tokens.GetByUser(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("unexpected error on get tokens: %s", err.Error())
+	}
+	if len(tokens) != 1 || tokens[0].ID != token.ID || !tokens[0].Revoked() {
+		t.Errorf("expected revoked token listed, got %+v", tokens)
+	}
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// Transactor runs fn in a transaction, repositories called with the context given to fn take part in it.
+// Calls nested in a running transaction join it.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type sqlTxKey struct{}
+
+type sqlTransactor struct {
+	conn *sql.DB
+}
+
+func NewTransactor(conn *sql.DB) Transactor {
+	return &sqlTransactor{conn: conn}
+}
+
+func (t *sqlTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if txFrom(ctx) != nil {
+		return fn(ctx)
+	}
+	tx, err := t.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	ctx, hooks := withCommitHooks(ctx)
+	if err = fn(context.WithValue(ctx, sqlTxKey{}, tx)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	hooks.run()
+	return nil
+}
+
+func txFrom(ctx context.Context) *sql.Tx {
+	tx, _ := ctx.Value(sqlTxKey{}).(*sql.Tx)
+	return tx
+}
+
+// queryer is implemented by both *sql.DB and *sql.Tx.
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// queryerFrom returns the transaction running in the context, if any, otherwise the connection.
+func queryerFrom(ctx context.Context, conn *sql.DB) queryer {
+	if tx := txFrom(ctx); tx != nil {
+		return tx
+	}
+	return conn
+}
+
+type memoryTxKey struct{}
+
+// memoryTx keeps the changes made by memory repositories to undo them on rollback.
+type memoryTx struct {
+	undo []func()
+}
+
+type memoryTransactor struct {
+	mutex sync.Mutex
+}
+
+// NewMemoryTransactor returns a transactor for memory repositories, transactions run one at a time
+// and their changes are undone when fn fails.
+func NewMemoryTransactor() Transactor {
+	return &memoryTransactor{}
+}
+
+func (t *memoryTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(memoryTxKey{}).(*memoryTx); ok {
+		return fn(ctx)
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	tx := new(memoryTx)
+	ctx, hooks := withCommitHooks(ctx)
+	if err := fn(context.WithValue(ctx, memoryTxKey{}, tx)); err != nil {
+		for i := len(tx.undo) - 1; i >= 0; i-- {
+			tx.undo[i]()
+		}
+		return err
+	}
+	hooks.run()
+	return nil
+}
+
+// onRollback registers undo to run if the transaction in the context is rolled back.
+func onRollback(ctx context.Context, undo func()) {
+	if tx, ok := ctx.Value(memoryTxKey{}).(*memoryTx); ok {
+		tx.undo = append(tx.undo, undo)
+	}
+}
+
+type commitHooksKey struct{}
+
+// commitHooks run once the transaction they were registered in commits.
+type commitHooks struct {
+	hooks []func()
+}
+
+func withCommitHooks(ctx context.Context) (context.Context, *commitHooks) {
+	hooks := new(commitHooks)
+	return context.WithValue(ctx, commitHooksKey{}, hooks), hooks
+}
+
+func (h *commitHooks) run() {
+	for _, hook := range h.hooks {
+		hook()
+	}
+}
+
+// OnCommit registers fn to run once the transaction in the context commits, it runs right away without transaction.
+// Rolled back transactions never run it.
+func OnCommit(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(commitHooksKey{}).(*commitHooks); ok {
+		hooks.hooks = append(hooks.hooks, fn)
+		return
+	}
+	fn()
+}
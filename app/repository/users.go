@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/ungame/command-time-track/app/ioext"
+	"github.com/ungame/command-time-track/app/models"
+)
+
+type UsersRepository interface {
+	Create(ctx context.Context, user *models.User) (int64, error)
+	Get(ctx context.Context, id int64) (*models.User, error)
+	GetByName(ctx context.Context, name string) (*models.User, error)
+	GetAll(ctx context.Context) ([]*models.User, error)
+	SetRole(ctx context.Context, id int64, role models.Role) (int64, error)
+}
+
+type usersRepository struct {
+	conn *sql.DB
+}
+
+func NewUsersRepository(conn *sql.DB) UsersRepository {
+	return &usersRepository{conn: conn}
+}
+
+func (r *usersRepository) Create(ctx context.Context, user *models.User) (int64, error) {
+	result, err := queryerFrom(ctx, r.conn).ExecContext(ctx, insertUserQuery, user.Name, user.Role, user.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	if user.ID, err = result.LastInsertId(); err != nil {
+		return 0, err
+	}
+	return user.ID, nil
+}
+
+func (r *usersRepository) Get(ctx context.Context, id int64) (*models.User, error) {
+	user := new(models.User)
+	row := queryerFrom(ctx, r.conn).QueryRowContext(ctx, `select `+userColumns+` from users where id = ?`, id)
+	return user, notFound(scanUser(row, user))
+}
+
+func (r *usersRepository) GetByName(ctx context.Context, name string) (*models.User, error) {
+	user := new(models.User)
+	row := queryerFrom(ctx, r.conn).QueryRowContext(ctx, `select `+userColumns+` from users where name = ?`, name)
+	return user, notFound(scanUser(row, user))
+}
+
+func (r *usersRepository) GetAll(ctx context.Context) ([]*models.User, error) {
+	rows, err := queryerFrom(ctx, r.conn).QueryContext(ctx, `select `+userColumns+` from users order by id`)
+	if err != nil {
+		return nil, err
+	}
+	defer ioext.Close(rows)
+	users := make([]*models.User, 0, 10)
+	for rows.Next() {
+		user := new(models.User)
+		if err = scanUser(rows, user); err != nil {
+			return users, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+func (r *usersRepository) SetRole(ctx context.Context, id int64, role models.Role) (int64, error) {
+	result, err := queryerFrom(ctx, r.conn).ExecContext(ctx, updateUserRoleQuery, role, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func scanUser(row scanner, user *models.User) error {
+	return row.Scan(&user.ID, &user.Name, &user.Role, &user.CreatedAt)
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"github.com/ungame/command-time-track/app/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+type memoryUsersRepository struct {
+	mutex    sync.RWMutex
+	sequence int64
+	users    map[int64]*models.User
+}
+
+// NewMemoryUsersRepository returns a repository holding the default admin user, like the migrated databases.
+func NewMemoryUsersRepository() UsersRepository {
+	return &memoryUsersRepository{
+		sequence: models.DefaultUserID,
+		users: map[int64]*models.User{
+			models.DefaultUserID: {ID: models.DefaultUserID, Name: "default", Role: models.RoleAdmin, CreatedAt: time.Now().UTC()},
+		},
+	}
+}
+
+func (r *memoryUsersRepository) Create(_ context.Context, user *models.User) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.sequence++
+	user.ID = r.sequence
+	clone := *user
+	r.users[user.ID] = &clone
+
+	return user.ID, nil
+}
+
+func (r *memoryUsersRepository) Get(_ context.Context, id int64) (*models.User, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return new(models.User), ErrNotFound
+	}
+	clone := *user
+	return &clone, nil
+}
+
+func (r *memoryUsersRepository) GetByName(_ context.Context, name string) (*models.User, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, user := range r.users {
+		if user.Name == name {
+			clone := *user
+			return &clone, nil
+		}
+	}
+	return new(models.User), ErrNotFound
+}
+
+func (r *memoryUsersRepository) GetAll(_ context.Context) ([]*models.User, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	users := make([]*models.User, 0, len(r.users))
+	for _, user := range r.users {
+		clone := *user
+		users = append(users, &clone)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID < users[j].ID
+	})
+	return users, nil
+}
+
+func (r *memoryUsersRepository) SetRole(_ context.Context, id int64, role models.Role) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return 0, nil
+	}
+	user.Role = role
+	return 1, nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/ungame/command-time-track/app/models"
+	"testing"
+	"time"
+)
+
+func mustCreateUser(t *testing.T, repo UsersRepository, name string) *models.User {
+	t.Helper()
+	user := &models.User{Name: uniqueTerm(name), Role: models.RoleMember, CreatedAt: time.Now().UTC().Truncate(time.Second)}
+	if _, err := repo.Create(context.Background(), user); err != nil {
+		t.Fatalf("unexpected error on create user: %s", err.Error())
+	}
+	return user
+}
+
+func testActivityOwners(t *testing.T, s *stores) {
+	var (
+		background = context.Background()
+		alice      = mustCreateUser(t, s.users, "alice")
+		bob        = mustCreateUser(t, s.users, "bob")
+		asAlice    = WithOwner(background, alice.ID)
+		asBob      = WithOwner(background, bob.ID)
+		category   = uniqueTerm("owners")
+	)
+
+	existing, err := s.users.GetByName(background, alice.Name)
+	if err != nil || existing.ID != alice.ID {
+		t.Fatalf("unexpected user by name: %+v, err=%v", existing, err)
+	}
+	if _, err = s.users.GetByName(background, uniqueTerm("missing")); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected missing user, got %v", err)
+	}
+	if defaultUser, err := s.users.Get(background, models.DefaultUserID); err != nil || defaultUser.Name != "default" || defaultUser.Role != models.RoleAdmin {
+		t.Errorf("unexpected default user: %+v, err=%v", defaultUser, err)
+	}
+
+	defaultID := mustCreateActivity(t, s.activities, category, "unscoped")
+	activity := &models.Activity{Category: category, Description: "alice", StartedAt: time.Now().UTC(), UpdatedAt: time.Now().UTC()}
+	if _, err = s.activities.Create(asAlice, activity); err != nil {
+		t.Fatalf("unexpected error on create activity: %s", err.Error())
+	}
+	t.Cleanup(func() { _, _ = s.activities.Delete(background, activity.ID) })
+
+	if unscoped, err := s.activities.Get(background, defaultID); err != nil || unscoped.OwnerID != models.DefaultUserID {
+		t.Errorf("expected activity of the default user, got %+v, err=%v", unscoped, err)
+	}
+	if owned, err := s.activities.Get(asAlice, activity.ID); err != nil || owned.OwnerID != alice.ID {
+		t.Errorf("unexpected activity of owner: %+v, err=%v", owned, err)
+	}
+	if _, err = s.activities.Get(asBob, activity.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected activity of another owner to be missing, got %v", err)
+	}
+
+	found, err := s.activities.Find(asBob, &ActivitiesQuery{Category: category})
+	if err != nil {
+		t.Fatalf("unexpected error on find activities: %s", err.Error())
+	}
+	if len(found) != 0 {
+		t.Errorf("unexpected activities of another owner: %v", ids(found))
+	}
+	if started, err := s.activities.GetByStatus(asBob, models.StatusStarted); err != nil || containsID(started, activity.ID) {
+		t.Errorf("unexpected started activities of another owner: %v, err=%v", ids(started), err)
+	}
+	if searched, err := s.activities.Search(asBob, category); err != nil || len(searched) != 0 {
+		t.Errorf("unexpected searched activities of another owner: %v, err=%v", ids(searched), err)
+	}
+	if all, err := s.activities.GetAll(asAlice); err != nil || !containsID(all, activity.ID) || containsID(all, defaultID) {
+		t.Errorf("unexpected activities of owner: %v, err=%v", ids(all), err)
+	}
+
+	activity.Description = "bob"
+	if rows, err := s.activities.Update(asBob, activity); err != nil || rows != 0 {
+		t.Errorf("expected update of another owner to change nothing: rows=%d, err=%v", rows, err)
+	}
+	if rows, err := s.activities.Delete(asBob, activity.ID); err != nil || rows != 0 {
+		t.Errorf("expected delete of another owner to change nothing: rows=%d, err=%v", rows, err)
+	}
+	if rows, err := s.activities.Update(asAlice, activity); err != nil || rows != 1 {
+		t.Errorf("unexpected update of owner: rows=%d, err=%v", rows, err)
+	}
+	if updated, err := s.activities.Get(background, activity.ID); err != nil || updated.OwnerID != alice.ID || updated.Description != "bob" {
+		t.Errorf("unexpected updated activity: %+v, err=%v", updated, err)
+	}
+}
+
+func testUserRoles(t *testing.T, s *stores) {
+	var (
+		ctx  = context.Background()
+		user = mustCreateUser(t, s.users, "roles")
+	)
+
+	if rows, err := s.users.SetRole(ctx, user.ID, models.RoleViewer); err != nil || rows != 1 {
+		t.Fatalf("unexpected set role: rows=%d, err=%v", rows, err)
+	}
+	if updated, err := s.users.Get(ctx, user.ID); err != nil || updated.Role != models.RoleViewer {
+		t.Errorf("expected viewer, got %+v, err=%v", updated, err)
+	}
+	if rows, err := s.users.SetRole(ctx, user.ID+1000, models.RoleViewer); err != nil || rows != 0 {
+		t.Errorf("expected missing user unchanged: rows=%d, err=%v", rows, err)
+	}
+}
@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/ungame/command-time-track/app/ioext"
+	"github.com/ungame/command-time-track/app/models"
+	"strings"
+	"time"
+)
+
+// WebhooksRepository stores the webhooks and their deliveries, deleting a webhook deletes its deliveries.
+// The events queued are the outbox of the activity events, dispatched to the webhooks once committed.
+type WebhooksRepository interface {
+	Create(ctx context.Context, webhook *models.Webhook) (int64, error)
+	Update(ctx context.Context, webhook *models.Webhook) (int64, error)
+	Delete(ctx context.Context, id int64) (int64, error)
+	Get(ctx context.Context, id int64) (*models.Webhook, error)
+	GetByOwner(ctx context.Context, ownerID int64) ([]*models.Webhook, error)
+	GetActive(ctx context.Context) ([]*models.Webhook, error)
+	CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) (int64, error)
+	UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) (int64, error)
+	GetDelivery(ctx context.Context, id int64) (*models.WebhookDelivery, error)
+	// GetDeliveries returns the last deliveries of the webhook with the status, of any status when empty,
+	// the last one first.
+	GetDeliveries(ctx context.Context, webhookID int64, status string, limit int) ([]*models.WebhookDelivery, error)
+	// GetDueDeliveries returns the pending deliveries to attempt at the time, the longest due first.
+	GetDueDeliveries(ctx context.Context, at time.Time, limit int) ([]*models.WebhookDelivery, error)
+	// QueueEvent queues the event to dispatch, in the transaction recording the activity event.
+	QueueEvent(ctx context.Context, event *models.WebhookEvent) error
+	// GetQueuedEvents returns the events queued, the oldest first.
+	GetQueuedEvents(ctx context.Context, limit int) ([]*models.WebhookEvent, error)
+	DequeueEvent(ctx context.Context, eventID int64) (int64, error)
+}
+
+type webhooksRepository struct {
+	conn *sql.DB
+}
+
+func NewWebhooksRepository(conn *sql.DB) WebhooksRepository {
+	return &webhooksRepository{conn: conn}
+}
+
+func (r *webhooksRepository) Create(ctx context.Context, webhook *models.Webhook) (int64, error) {
+	result, err := queryerFrom(ctx, r.conn).ExecContext(ctx, insertWebhookQuery, webhook.OwnerID, webhook.URL, webhook.Secret, joinEvents(webhook.Events), webhook.Active, webhook.CreatedAt, webhook.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	if webhook.ID, err = result.LastInsertId(); err != nil {
+		return 0, err
+	}
+	return webhook.ID, nil
+}
+
+func (r *webhooksRepository) Update(ctx context.Context, webhook *models.Webhook) (int64, error) {
+	result, err := queryerFrom(ctx, r.conn).ExecContext(ctx, updateWebhookQuery, webhook.URL, joinEvents(webhook.Events), webhook.Active, webhook.UpdatedAt, webhook.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *webhooksRepository) Delete(ctx context.Context, id int64) (int64, error) {
+	result, err := queryerFrom(ctx, r.conn).ExecContext(ctx, deleteWebhookQuery, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *webhooksRepository) Get(ctx context.Context, id int64) (*models.Webhook, error) {
+	webhook := new(models.Webhook)
+	row := queryerFrom(ctx, r.conn).QueryRowContext(ctx, `select `+webhookColumns+` from webhooks where id = ?`, id)
+	return webhook, notFound(scanWebhook(row, webhook))
+}
+
+func (r *webhooksRepository) GetByOwner(ctx context.Context, ownerID int64) ([]*models.Webhook, error) {
+	return r.queryWebhooks(ctx, `select `+webhookColumns+` from webhooks where owner_id = ? order by id`, ownerID)
+}
+
+func (r *webhooksRepository) GetActive(ctx context.Context) ([]*models.Webhook, error) {
+	return r.queryWebhooks(ctx, `select `+webhookColumns+` from webhooks where active = ? order by id`, true)
+}
+
+func (r *webhooksRepository) queryWebhooks(ctx context.Context, query string, args ...any) ([]*models.Webhook, error) {
+	rows, err := queryerFrom(ctx, r.conn).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer ioext.Close(rows)
+	webhooks := make([]*models.Webhook, 0, 10)
+	for rows.Next() {
+		webhook := new(models.Webhook)
+		if err = scanWebhook(rows, webhook); err != nil {
+			return webhooks, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+func (r *webhooksRepository) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) (int64, error) {
+	result, err := queryerFrom(ctx, r.conn).ExecContext(
+		ctx,
+		insertWebhookDeliveryQuery,
+		delivery.WebhookID,
+		delivery.EventID,
+		delivery.EventType,
+		delivery.Payload,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.ResponseStatus,
+		delivery.LastError,
+		delivery.NextAttemptAt,
+		delivery.CreatedAt,
+		delivery.UpdatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	if delivery.ID, err = result.LastInsertId(); err != nil {
+		return 0, err
+	}
+	return delivery.ID, nil
+}
+
+func (r *webhooksRepository) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) (int64, error) {
+	result, err := queryerFrom(ctx, r.conn).ExecContext(
+		ctx,
+		updateWebhookDeliveryQuery,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.ResponseStatus,
+		delivery.LastError,
+		delivery.NextAttemptAt,
+		delivery.UpdatedAt,
+		delivery.ID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *webhooksRepository) GetDelivery(ctx context.Context, id int64) (*models.WebhookDelivery, error) {
+	delivery := new(models.WebhookDelivery)
+	row := queryerFrom(ctx, r.conn).QueryRowContext(ctx, `select `+webhookDeliveryColumns+` from webhook_deliveries where id = ?`, id)
+	return delivery, notFound(scanWebhookDelivery(row, delivery))
+}
+
+func (r *webhooksRepository) GetDeliveries(ctx context.Context, webhookID int64, status string, limit int) ([]*models.WebhookDelivery, error) {
+	return r.queryDeliveries(ctx, `select `+webhookDeliveryColumns+` from webhook_deliveries where webhook_id = ? and (? = '' or status = ?) order by id desc limit ?`, webhookID, status, status, limit)
+}
+
+func (r *webhooksRepository) GetDueDeliveries(ctx context.Context, at time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	return r.queryDeliveries(ctx, `select `+webhookDeliveryColumns+` from webhook_deliveries where status = ? and next_attempt_at <= ? order by next_attempt_at, id limit ?`, models.DeliveryPending, at, limit)
+}
+
+func (r *webhooksRepository) queryDeliveries(ctx context.Context, query string, args ...any) ([]*models.WebhookDelivery, error) {
+	rows, err := queryerFrom(ctx, r.conn).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer ioext.Close(rows)
+	deliveries := make([]*models.WebhookDelivery, 0, 10)
+	for rows.Next() {
+		delivery := new(models.WebhookDelivery)
+		if err = scanWebhookDelivery(rows, delivery); err != nil {
+			return deliveries, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *webhooksRepository) QueueEvent(ctx context.Context, event *models.WebhookEvent) error {
+	_, err := queryerFrom(ctx, r.conn).ExecContext(ctx, insertWebhookEventQuery, event.EventID, event.EventType, event.OwnerID, event.Data, event.CreatedAt)
+	return err
+}
+
+func (r *webhooksRepository) GetQueuedEvents(ctx context.Context, limit int) ([]*models.WebhookEvent, error) {
+	rows, err := queryerFrom(ctx, r.conn).QueryContext(ctx, `select `+webhookEventColumns+` from webhook_outbox order by event_id limit ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer ioext.Close(rows)
+	events := make([]*models.WebhookEvent, 0, 10)
+	for rows.Next() {
+		event := new(models.WebhookEvent)
+		if err = rows.Scan(&event.EventID, &event.EventType, &event.OwnerID, &event.Data, &event.CreatedAt); err != nil {
+			return events, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func (r *webhooksRepository) DequeueEvent(ctx context.Context, eventID int64) (int64, error) {
+	result, err := queryerFrom(ctx, r.conn).ExecContext(ctx, deleteWebhookEventQuery, eventID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// joinEvents returns the events of a webhook as stored, comma separated.
+func joinEvents(events []string) string {
+	return strings.Join(events, ",")
+}
+
+func scanWebhook(row scanner, webhook *models.Webhook) error {
+	var events string
+	if err := row.Scan(&webhook.ID, &webhook.OwnerID, &webhook.URL, &webhook.Secret, &events, &webhook.Active, &webhook.CreatedAt, &webhook.UpdatedAt); err != nil {
+		return err
+	}
+	webhook.Events = make([]string, 0)
+	if events != "" {
+		webhook.Events = strings.Split(events, ",")
+	}
+	return nil
+}
+
+func scanWebhookDelivery(row scanner, delivery *models.WebhookDelivery) error {
+	return row.Scan(
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.ResponseStatus,
+		&delivery.LastError,
+		&delivery.NextAttemptAt,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	)
+}
@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/ungame/command-time-track/app/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+type memoryWebhooksRepository struct {
+	mutex              sync.RWMutex
+	sequence           int64
+	deliveriesSequence int64
+	webhooks           map[int64]*models.Webhook
+	deliveries         map[int64]*models.WebhookDelivery
+	outbox             map[int64]*models.WebhookEvent
+}
+
+func NewMemoryWebhooksRepository() WebhooksRepository {
+	return &memoryWebhooksRepository{
+		webhooks:   make(map[int64]*models.Webhook),
+		deliveries: make(map[int64]*models.WebhookDelivery),
+		outbox:     make(map[int64]*models.WebhookEvent),
+	}
+}
+
+func (r *memoryWebhooksRepository) Create(_ context.Context, webhook *models.Webhook) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.sequence++
+	webhook.ID = r.sequence
+	r.webhooks[webhook.ID] = cloneWebhook(webhook)
+
+	return webhook.ID, nil
+}
+
+func (r *memoryWebhooksRepository) Update(_ context.Context, webhook *models.Webhook) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored, ok := r.webhooks[webhook.ID]
+	if !ok {
+		return 0, nil
+	}
+	updated := cloneWebhook(webhook)
+	updated.OwnerID = stored.OwnerID
+	updated.Secret = stored.Secret
+	updated.CreatedAt = stored.CreatedAt
+	r.webhooks[webhook.ID] = updated
+
+	return 1, nil
+}
+
+func (r *memoryWebhooksRepository) Delete(_ context.Context, id int64) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.webhooks[id]; !ok {
+		return 0, nil
+	}
+	delete(r.webhooks, id)
+	for deliveryID, delivery := range r.deliveries {
+		if delivery.WebhookID == id {
+			delete(r.deliveries, deliveryID)
+		}
+	}
+	return 1, nil
+}
+
+func (r *memoryWebhooksRepository) Get(_ context.Context, id int64) (*models.Webhook, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	webhook, ok := r.webhooks[id]
+	if !ok {
+		return new(models.Webhook), ErrNotFound
+	}
+	return cloneWebhook(webhook), nil
+}
+
+func (r *memoryWebhooksRepository) GetByOwner(_ context.Context, ownerID int64) ([]*models.Webhook, error) {
+	return r.filterWebhooks(func(webhook *models.Webhook) bool {
+		return webhook.OwnerID == ownerID
+	}), nil
+}
+
+func (r *memoryWebhooksRepository) GetActive(_ context.Context) ([]*models.Webhook, error) {
+	return r.filterWebhooks(func(webhook *models.Webhook) bool {
+		return webhook.Active
+	}), nil
+}
+
+func (r *memoryWebhooksRepository) filterWebhooks(match func(webhook *models.Webhook) bool) []*models.Webhook {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	webhooks := make([]*models.Webhook, 0)
+	for _, webhook := range r.webhooks {
+		if match(webhook) {
+			webhooks = append(webhooks, cloneWebhook(webhook))
+		}
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].ID < webhooks[j].ID
+	})
+	return webhooks
+}
+
+func (r *memoryWebhooksRepository) CreateDelivery(ctx context.Context, delivery *models.WebhookDelivery) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.deliveriesSequence++
+	delivery.ID = r.deliveriesSequence
+	r.deliveries[delivery.ID] = cloneWebhookDelivery(delivery)
+
+	id := delivery.ID
+	onRollback(ctx, func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		delete(r.deliveries, id)
+	})
+
+	return delivery.ID, nil
+}
+
+func (r *memoryWebhooksRepository) UpdateDelivery(_ context.Context, delivery *models.WebhookDelivery) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored, ok := r.deliveries[delivery.ID]
+	if !ok {
+		return 0, nil
+	}
+	updated := cloneWebhookDelivery(delivery)
+	updated.WebhookID = stored.WebhookID
+	updated.EventID = stored.EventID
+	updated.EventType = stored.EventType
+	updated.Payload = stored.Payload
+	updated.CreatedAt = stored.CreatedAt
+	r.deliveries[delivery.ID] = updated
+
+	return 1, nil
+}
+
+func (r *memoryWebhooksRepository) GetDelivery(_ context.Context, id int64) (*models.WebhookDelivery, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return new(models.WebhookDelivery), ErrNotFound
+	}
+	return cloneWebhookDelivery(delivery), nil
+}
+
+func (r *memoryWebhooksRepository) GetDeliveries(_ context.Context, webhookID int64, status string, limit int) ([]*models.WebhookDelivery, error) {
+	deliveries := r.filterDeliveries(func(delivery *models.WebhookDelivery) bool {
+		return delivery.WebhookID == webhookID && (status == "" || delivery.Status == status)
+	})
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].ID > deliveries[j].ID
+	})
+	return limitDeliveries(deliveries, limit), nil
+}
+
+func (r *memoryWebhooksRepository) GetDueDeliveries(_ context.Context, at time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	deliveries := r.filterDeliveries(func(delivery *models.WebhookDelivery) bool {
+		return delivery.Status == models.DeliveryPending && delivery.NextAttemptAt != nil && !delivery.NextAttemptAt.After(at)
+	})
+	sort.Slice(deliveries, func(i, j int) bool {
+		if !deliveries[i].NextAttemptAt.Equal(*deliveries[j].NextAttemptAt) {
+			return deliveries[i].NextAttemptAt.Before(*deliveries[j].NextAttemptAt)
+		}
+		return deliveries[i].ID < deliveries[j].ID
+	})
+	return limitDeliveries(deliveries, limit), nil
+}
+
+func (r *memoryWebhooksRepository) filterDeliveries(match func(delivery *models.WebhookDelivery) bool) []*models.WebhookDelivery {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	deliveries := make([]*models.WebhookDelivery, 0)
+	for _, delivery := range r.deliveries {
+		if match(delivery) {
+			deliveries = append(deliveries, cloneWebhookDelivery(delivery))
+		}
+	}
+	return deliveries
+}
+
+func (r *memoryWebhooksRepository) QueueEvent(ctx context.Context, event *models.WebhookEvent) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.outbox[event.EventID]; ok {
+		return fmt.Errorf("event already queued: %d", event.EventID)
+	}
+	queued := *event
+	r.outbox[event.EventID] = &queued
+
+	onRollback(ctx, func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		delete(r.outbox, queued.EventID)
+	})
+
+	return nil
+}
+
+func (r *memoryWebhooksRepository) GetQueuedEvents(_ context.Context, limit int) ([]*models.WebhookEvent, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	events := make([]*models.WebhookEvent, 0, len(r.outbox))
+	for _, event := range r.outbox {
+		clone := *event
+		events = append(events, &clone)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].EventID < events[j].EventID
+	})
+	if limit > 0 && len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+func (r *memoryWebhooksRepository) DequeueEvent(ctx context.Context, eventID int64) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	event, ok := r.outbox[eventID]
+	if !ok {
+		return 0, nil
+	}
+	delete(r.outbox, eventID)
+
+	onRollback(ctx, func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		r.outbox[eventID] = event
+	})
+
+	return 1, nil
+}
+
+func limitDeliveries(deliveries []*models.WebhookDelivery, limit int) []*models.WebhookDelivery {
+	if limit > 0 && len(deliveries) > limit {
+		return deliveries[:limit]
+	}
+	return deliveries
+}
+
+func cloneWebhook(webhook *models.Webhook) *models.Webhook {
+	clone := *webhook
+	clone.Events = make([]string, len(webhook.Events))
+	copy(clone.Events, webhook.Events)
+	return &clone
+}
+
+func cloneWebhookDelivery(delivery *models.WebhookDelivery) *models.WebhookDelivery {
+	clone := *delivery
+	if delivery.ResponseStatus != nil {
+		responseStatus := *delivery.ResponseStatus
+		clone.ResponseStatus = &responseStatus
+	}
+	if delivery.LastError != nil {
+		lastError := *delivery.LastError
+		clone.LastError = &lastError
+	}
+	if delivery.NextAttemptAt != nil {
+		nextAttemptAt := *delivery.NextAttemptAt
+		clone.NextAttemptAt = &nextAttemptAt
+	}
+	return &clone
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/ungame/command-time-track/app/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testWebhooksRepository(t *testing.T, s *stores) {
+	var (
+		ctx  = context.Background()
+		user = mustCreateUser(t, s.users, "webhooks")
+		now  = time.Now().UTC().Truncate(time.Second)
+	)
+
+	webhook := &models.Webhook{OwnerID: user.ID, URL: "https://example.com/hook", Secret: "whsec_test", Events: []string{"started", "stopped"}, Active: true, CreatedAt: now, UpdatedAt: now}
+	if _, err := s.webhooks.Create(ctx, webhook); err != nil {
+		t.Fatalf("unexpected error on create webhook: %s", err.Error())
+	}
+	paused := &models.Webhook{OwnerID: user.ID, URL: "https://example.com/paused", Secret: "whsec_paused", Events: []string{}, CreatedAt: now, UpdatedAt: now}
+	if _, err := s.webhooks.Create(ctx, paused); err != nil {
+		t.Fatalf("unexpected error on create webhook: %s", err.Error())
+	}
+
+	found, err := s.webhooks.Get(ctx, webhook.ID)
+	if err != nil {
+		t.Fatalf("unexpected error on get webhook: %s", err.Error())
+	}
+	if !reflect.DeepEqual(found, webhook) {
+		t.Errorf("unexpected webhook: %+v, expected %+v", found, webhook)
+	}
+
+	owned, err := s.webhooks.GetByOwner(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("unexpected error on get webhooks: %s", err.Error())
+	}
+	if len(owned) != 2 || owned[0].ID != webhook.ID || owned[1].ID != paused.ID || len(owned[1].Events) != 0 {
+		t.Errorf("unexpected webhooks of owner: %+v", owned)
+	}
+
+	active, err := s.webhooks.GetActive(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error on get active webhooks: %s", err.Error())
+	}
+	if !containsWebhook(active, webhook.ID) || containsWebhook(active, paused.ID) {
+		t.Errorf("expected only active webhooks, got %+v", active)
+	}
+
+	webhook.URL = "https://example.com/updated"
+	webhook.Events = []string{"deleted"}
+	webhook.UpdatedAt = now.Add(time.Minute)
+	if rows, err := s.webhooks.Update(ctx, webhook); err != nil || rows != 1 {
+		t.Fatalf("expected webhook updated, got rows=%d, err=%v", rows, err)
+	}
+	if found, _ = s.webhooks.Get(ctx, webhook.ID); !reflect.DeepEqual(found, webhook) {
+		t.Errorf("unexpected updated webhook: %+v, expected %+v", found, webhook)
+	}
+
+	var (
+		due     = now.Add(-time.Minute)
+		later   = now.Add(time.Hour)
+		pending = &models.WebhookDelivery{WebhookID: webhook.ID, EventID: 1, EventType: "started", Payload: `{"event":"started"}`, Status: models.DeliveryPending, NextAttemptAt: &due, CreatedAt: now, UpdatedAt: now}
+		waiting = &models.WebhookDelivery{WebhookID: webhook.ID, EventID: 2, EventType: "stopped", Payload: `{"event":"stopped"}`, Status: models.DeliveryPending, NextAttemptAt: &later, CreatedAt: now, UpdatedAt: now}
+	)
+	for _, delivery := range []*models.WebhookDelivery{pending, waiting} {
+		if _, err = s.webhooks.CreateDelivery(ctx, delivery); err != nil {
+			t.Fatalf("unexpected error on create delivery: %s", err.Error())
+		}
+	}
+
+	dueDeliveries, err := s.webhooks.GetDueDeliveries(ctx, now, 100)
+	if err != nil {
+		t.Fatalf("unexpected error on get due deliveries: %s", err.Error())
+	}
+	if !containsDelivery(dueDeliveries, pending.ID) || containsDelivery(dueDeliveries, waiting.ID) {
+		t.Errorf("expected only due deliveries, got %+v", dueDeliveries)
+	}
+
+	pending.Fail(500, errors.New("unexpected status: 500"), now, nil)
+	if rows, err := s.webhooks.UpdateDelivery(ctx, pending); err != nil || rows != 1 {
+		t.Fatalf("expected delivery updated, got rows=%d, err=%v", rows, err)
+	}
+	delivery, err := s.webhooks.GetDelivery(ctx, pending.ID)
+	if err != nil {
+		t.Fatalf("unexpected error on get delivery: %s", err.Error())
+	}
+	if !reflect.DeepEqual(delivery, pending) {
+		t.Errorf("unexpected delivery: %+v, expected %+v", delivery, pending)
+	}
+
+	deliveries, err := s.webhooks.GetDeliveries(ctx, webhook.ID, "", 10)
+	if err != nil {
+		t.Fatalf("unexpected error on get deliveries: %s", err.Error())
+	}
+	if len(deliveries) != 2 || deliveries[0].ID != waiting.ID || deliveries[1].ID != pending.ID {
+		t.Errorf("expected the last delivery first, got %+v", deliveries)
+	}
+	if deliveries, _ = s.webhooks.GetDeliveries(ctx, webhook.ID, models.DeliveryFailed, 10); len(deliveries) != 1 || deliveries[0].ID != pending.ID {
+		t.Errorf("expected failed deliveries only, got %+v", deliveries)
+	}
+	if deliveries, _ = s.webhooks.GetDeliveries(ctx, webhook.ID, "", 1); len(deliveries) != 1 {
+		t.Errorf("expected deliveries limited, got %d", len(deliveries))
+	}
+
+	var (
+		first    = &models.WebhookEvent{EventID: 10, EventType: "started", OwnerID: user.ID, Data: `{"activity_id":1}`, CreatedAt: now}
+		second   = &models.WebhookEvent{EventID: 11, EventType: "stopped", OwnerID: user.ID, Data: `{"activity_id":1}`, CreatedAt: now}
+		rollback = errors.New("rollback")
+	)
+	err = s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.webhooks.QueueEvent(ctx, &models.WebhookEvent{EventID: 9, EventType: "started", OwnerID: user.ID, Data: "{}", CreatedAt: now}); err != nil {
+			t.Fatalf("unexpected error on queue event: %s", err.Error())
+		}
+		return rollback
+	})
+	if !errors.Is(err, rollback) {
+		t.Fatalf("expected rollback error, got %v", err)
+	}
+	for _, event := range []*models.WebhookEvent{second, first} {
+		if err = s.webhooks.QueueEvent(ctx, event); err != nil {
+			t.Fatalf("unexpected error on queue event: %s", err.Error())
+		}
+	}
+	if err = s.webhooks.QueueEvent(ctx, first); err == nil {
+		t.Errorf("expected event queued once")
+	}
+	queued, err := s.webhooks.GetQueuedEvents(ctx, 10)
+	if err != nil {
+		t.Fatalf("unexpected error on get queued events: %s", err.Error())
+	}
+	if len(queued) != 2 || !reflect.DeepEqual(queued[0], first) || !reflect.DeepEqual(queued[1], second) {
+		t.Errorf("expected the events queued and committed, oldest first, got %+v", queued)
+	}
+	if rows, err := s.webhooks.DequeueEvent(ctx, first.EventID); err != nil || rows != 1 {
+		t.Fatalf("expected event dequeued, got rows=%d, err=%v", rows, err)
+	}
+	if rows, _ := s.webhooks.DequeueEvent(ctx, first.EventID); rows != 0 {
+		t.Errorf("expected event dequeued once, got rows=%d", rows)
+	}
+	if queued, _ = s.webhooks.GetQueuedEvents(ctx, 10); len(queued) != 1 || queued[0].EventID != second.EventID {
+		t.Errorf("expected the event left queued, got %+v", queued)
+	}
+
+	if rows, err := s.webhooks.Delete(ctx, webhook.ID); err != nil || rows != 1 {
+		t.Fatalf("expected webhook deleted, got rows=%d, err=%v", rows, err)
+	}
+	if _, err = s.webhooks.Get(ctx, webhook.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected deleted webhook missing, got %v", err)
+	}
+	if _, err = s.webhooks.GetDelivery(ctx, pending.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected deliveries of deleted webhook deleted, got %v", err)
+	}
+}
+
+func containsWebhook(webhooks []*models.Webhook, id int64) bool {
+	for _, webhook := range webhooks {
+		if webhook.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsDelivery(deliveries []*models.WebhookDelivery, id int64) bool {
+	for _, delivery := range deliveries {
+		if delivery.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package rpc
+
+import (
+	"context"
+	"github.com/ungame/command-time-track/app/broker"
+	"github.com/ungame/command-time-track/app/rpc/pb"
+	"github.com/ungame/command-time-track/app/service"
+	"github.com/ungame/command-time-track/app/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// typeReset is the type of the event telling events were missed for good, like on GET /activities/stream.
+const typeReset = "reset"
+
+type activitiesServer struct {
+	pb.UnimplementedActivitiesServer
+	activitiesService service.ActivitiesService
+	streamService     service.StreamService
+}
+
+// NewServer returns the gRPC server of the activities API, authenticating the calls.
+func NewServer(authenticate Authenticator, activitiesService service.ActivitiesService, streamService service.StreamService) *grpc.Server {
+	server := grpc.NewServer(grpc.UnaryInterceptor(authenticate.unary), grpc.StreamInterceptor(authenticate.stream))
+	pb.RegisterActivitiesServer(server, &activitiesServer{activitiesService: activitiesService, streamService: streamService})
+	return server
+}
+
+// activity converts the activity returned by the service.
+func activity(output *types.ActivityOutput, err error) (*pb.Activity, error) {
+	if err != nil {
+		return nil, errorOf(err)
+	}
+	return activityOf(output), nil
+}
+
+func (s *activitiesServer) StartActivity(ctx context.Context, req *pb.StartActivityRequest) (*pb.StartActivityResponse, error) {
+	output, err := s.activitiesService.StartActivity(ctx, &types.StartActivityInput{
+		Category:    req.Category,
+		Description: req.Description,
+		Tags:        req.Tags,
+		StartedAt:   timeOf(req.StartedAt),
+		FinishedAt:  timeOf(req.FinishedAt),
+		Resolve:     req.Resolve,
+		ProjectID:   req.ProjectId,
+		HourlyRate:  req.HourlyRate,
+	})
+	if err != nil {
+		return nil, errorOf(err)
+	}
+	return &pb.StartActivityResponse{Activity: activityOf(output.ActivityOutput), Stopped: activitiesOf(output.Stopped)}, nil
+}
+
+func (s *activitiesServer) StopActivity(ctx context.Context, req *pb.ActivityRequest) (*pb.Activity, error) {
+	return activity(s.activitiesService.StopActivity(ctx, &types.UpdateActivityInput{ID: req.Id}))
+}
+
+func (s *activitiesServer) PauseActivity(ctx context.Context, req *pb.ActivityRequest) (*pb.Activity, error) {
+	return activity(s.activitiesService.PauseActivity(ctx, &types.UpdateActivityInput{ID: req.Id}))
+}
+
+func (s *activitiesServer) ResumeActivity(ctx context.Context, req *pb.ActivityRequest) (*pb.Activity, error) {
+	return activity(s.activitiesService.ResumeActivity(ctx, &types.UpdateActivityInput{ID: req.Id}))
+}
+
+func (s *activitiesServer) UpdateActivityCategory(ctx context.Context, req *pb.UpdateActivityRequest) (*pb.Activity, error) {
+	return activity(s.activitiesService.UpdateActivityCategory(ctx, &types.UpdateActivityInput{ID: req.Id, Category: req.Category}))
+}
+
+func (s *activitiesServer) UpdateActivityDescription(ctx context.Context, req *pb.UpdateActivityRequest) (*pb.Activity, error) {
+	return activity(s.activitiesService.UpdateActivityDescription(ctx, &types.UpdateActivityInput{ID: req.Id, Description: req.Description}))
+}
+
+func (s *activitiesServer) AddActivityTags(ctx context.Context, req *pb.TagActivityRequest) (*pb.Activity, error) {
+	return activity(s.activitiesService.AddActivityTags(ctx, &types.TagActivityInput{ID: req.Id, Tags: req.Tags}))
+}
+
+func (s *activitiesServer) RemoveActivityTags(ctx context.Context, req *pb.TagActivityRequest) (*pb.Activity, error) {
+	return activity(s.activitiesService.RemoveActivityTags(ctx, &types.TagActivityInput{ID: req.Id, Tags: req.Tags}))
+}
+
+func (s *activitiesServer) GetActivity(ctx context.Context, req *pb.ActivityRequest) (*pb.Activity, error) {
+	return activity(s.activitiesService.GetActivityByID(ctx, &types.GetActivityInput{ID: req.Id}))
+}
+
+func (s *activitiesServer) RestoreActivity(ctx context.Context, req *pb.ActivityRequest) (*pb.Activity, error) {
+	return activity(s.activitiesService.RestoreActivity(ctx, &types.RestoreActivityInput{ID: req.Id}))
+}
+
+func (s *activitiesServer) ListActivities(ctx context.Context, req *pb.ListActivitiesRequest) (*pb.ListActivitiesResponse, error) {
+	output, err := s.activitiesService.ListActivities(ctx, &types.ListActivitiesInput{
+		From:      timeOf(req.From),
+		To:        timeOf(req.To),
+		Category:  req.Category,
+		ProjectID: req.ProjectId,
+		Tags:      req.Tags,
+		Status:    statusFilterOf(req.Status),
+		Sort:      req.Sort,
+		Order:     req.Order,
+		Limit:     int(req.Limit),
+		Cursor:    req.Cursor,
+		UserID:    req.UserId,
+	})
+	if err != nil {
+		return nil, errorOf(err)
+	}
+	return &pb.ListActivitiesResponse{Activities: activitiesOf(output.Activities), NextCursor: output.NextCursor}, nil
+}
+
+func (s *activitiesServer) DeleteActivity(ctx context.Context, req *pb.ActivityRequest) (*pb.DeleteActivityResponse, error) {
+	id, err := s.activitiesService.DeleteActivityByID(ctx, &types.DeleteActivityInput{ID: req.Id})
+	if err != nil {
+		return nil, errorOf(err)
+	}
+	return &pb.DeleteActivityResponse{Id: id}, nil
+}
+
+// WatchActivities sends the missed events first, after a reset event when some were missed for good, then the next
+// events until the call is canceled. Calls too slow to keep up fail as unavailable, to be resumed.
+func (s *activitiesServer) WatchActivities(req *pb.WatchActivitiesRequest, stream pb.Activities_WatchActivitiesServer) error {
+	subscription, err := s.streamService.SubscribeActivities(stream.Context(), req.LastEventId)
+	if err != nil {
+		return errorOf(err)
+	}
+	defer subscription.Close()
+
+	if subscription.Reset {
+		if err = stream.Send(&pb.ActivityEvent{Type: typeReset}); err != nil {
+			return err
+		}
+	}
+	send := func(event *broker.Event) error {
+		converted, err := eventOf(event)
+		if err != nil {
+			return errorOf(err)
+		}
+		return stream.Send(converted)
+	}
+	for _, event := range subscription.Missed {
+		if err = send(event); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return status.Error(codes.Unavailable, "events dropped, resume with the last event id received")
+			}
+			if err = send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"github.com/ungame/command-time-track/app/broker"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/rpc/pb"
+	"github.com/ungame/command-time-track/app/service"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"net"
+	"testing"
+	"time"
+)
+
+type nopObserver struct{}
+
+func (nopObserver) Count(string, ...string)          {}
+func (nopObserver) DurationOf(string, time.Duration) {}
+
+func newTestClient(t *testing.T) pb.ActivitiesClient {
+	t.Helper()
+	var (
+		events            = broker.New(broker.DefaultBufferSize)
+		usersService      = service.NewUsersService(repository.NewMemoryUsersRepository(), repository.NewMemoryTeamsRepository())
+		activitiesService = service.NewActivitiesService(repository.NewMemoryTransactor(), repository.NewMemoryActivitiesRepository(), events.ActivityEvents(repository.NewMemoryActivityEventsRepository()), repository.NewMemoryProjectsRepository(), repository.NewMemoryClientsRepository(), nopObserver{})
+		server            = NewServer(OpenAuth(usersService), activitiesService, service.NewStreamService(events))
+		listener          = bufconn.Listen(1 << 20)
+	)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return pb.NewActivitiesClient(conn)
+}
+
+func TestActivitiesServer(t *testing.T) {
+	var (
+		client      = newTestClient(t)
+		ctx, cancel = context.WithTimeout(context.Background(), time.Second*5)
+	)
+	defer cancel()
+
+	started, err := client.StartActivity(ctx, &pb.StartActivityRequest{Category: "dev", Description: "grpc", Tags: []string{"api"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	activity := started.Activity
+	if activity.Id == 0 || activity.Status != pb.Status_STATUS_STARTED || activity.StartedAt == nil || activity.FinishedAt != nil || len(activity.Intervals) != 1 || activity.Tags[0] != "api" {
+		t.Errorf("unexpected started activity: %+v", activity)
+	}
+	if since := time.Since(activity.StartedAt.AsTime()); since < 0 || since > time.Minute {
+		t.Errorf("unexpected start time: %s", activity.StartedAt.AsTime())
+	}
+
+	watch, err := client.WatchActivities(ctx, &pb.WatchActivitiesRequest{LastEventId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stopped, err := client.StopActivity(ctx, &pb.ActivityRequest{Id: activity.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stopped.Status != pb.Status_STATUS_FINISHED || stopped.FinishedAt == nil || stopped.Intervals[0].FinishedAt == nil {
+		t.Errorf("unexpected stopped activity: %+v", stopped)
+	}
+
+	event, err := watch.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Id != 2 || event.Type != broker.TypeStopped || event.Action != models.ActionStopped || event.ActivityId != activity.Id || event.Activity.Status != pb.Status_STATUS_FINISHED || event.CreatedAt == nil {
+		t.Errorf("unexpected event: %+v", event)
+	}
+
+	listed, err := client.ListActivities(ctx, &pb.ListActivitiesRequest{Status: pb.Status_STATUS_FINISHED})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed.Activities) != 1 || listed.Activities[0].Id != activity.Id {
+		t.Errorf("unexpected activities: %+v", listed.Activities)
+	}
+	if !listed.Activities[0].StartedAt.AsTime().Equal(activity.StartedAt.AsTime()) || !event.Activity.FinishedAt.AsTime().Equal(stopped.FinishedAt.AsTime()) {
+		t.Errorf("expected times kept, got started at %s and %s, finished at %s and %s", listed.Activities[0].StartedAt.AsTime(), activity.StartedAt.AsTime(), event.Activity.FinishedAt.AsTime(), stopped.FinishedAt.AsTime())
+	}
+	finishedAt := stopped.FinishedAt.AsTime()
+	for _, period := range []struct {
+		from, to time.Time
+		listed   int
+	}{
+		{finishedAt.Add(-time.Minute), finishedAt.Add(time.Minute), 1},
+		{finishedAt.Add(time.Nanosecond), finishedAt.Add(time.Minute), 0},
+	} {
+		listed, err = client.ListActivities(ctx, &pb.ListActivitiesRequest{From: timestamppb.New(period.from), To: timestamppb.New(period.to)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(listed.Activities) != period.listed {
+			t.Errorf("expected %d activities listed from %s to %s, got %d", period.listed, period.from, period.to, len(listed.Activities))
+		}
+	}
+
+	if _, err = client.AddActivityTags(ctx, &pb.TagActivityRequest{Id: activity.Id, Tags: []string{"a,b"}}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected invalid argument, got %v", err)
+	}
+	if _, err = client.DeleteActivity(ctx, &pb.ActivityRequest{Id: activity.Id}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.GetActivity(ctx, &pb.ActivityRequest{Id: activity.Id}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected deleted activity not found, got %v", err)
+	}
+
+	named := metadata.AppendToOutgoingContext(ctx, keyUser, "default")
+	if _, err = client.GetActivity(named, &pb.ActivityRequest{Id: activity.Id}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected call naming a user unauthenticated, got %v", err)
+	}
+	watch, err = client.WatchActivities(named, &pb.WatchActivitiesRequest{})
+	if err == nil {
+		_, err = watch.Recv()
+	}
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected watch naming a user unauthenticated, got %v", err)
+	}
+}
+
+func TestStartActivityOverlaps(t *testing.T) {
+	var (
+		client      = newTestClient(t)
+		ctx, cancel = context.WithTimeout(context.Background(), time.Second*5)
+		base        = time.Date(2023, 3, 1, 9, 0, 0, 0, time.UTC)
+		at          = func(minutes int) *timestamppb.Timestamp {
+			return timestamppb.New(base.Add(time.Minute * time.Duration(minutes)))
+		}
+	)
+	defer cancel()
+
+	recorded, err := client.StartActivity(ctx, &pb.StartActivityRequest{Category: "dev", StartedAt: at(0), FinishedAt: at(60)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	existing := recorded.Activity
+	if existing.Status != pb.Status_STATUS_FINISHED || !existing.StartedAt.AsTime().Equal(base) || !existing.FinishedAt.AsTime().Equal(at(60).AsTime()) {
+		t.Fatalf("unexpected recorded activity: %+v", existing)
+	}
+
+	_, err = client.StartActivity(ctx, &pb.StartActivityRequest{Category: "review", StartedAt: at(30), FinishedAt: at(90)})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected overlap failed precondition, got %v", err)
+	}
+	var failure *errdetails.PreconditionFailure
+	for _, detail := range status.Convert(err).Details() {
+		if d, ok := detail.(*errdetails.PreconditionFailure); ok {
+			failure = d
+		}
+	}
+	if failure == nil || len(failure.Violations) != 1 || failure.Violations[0].Type != ViolationConflict || failure.Violations[0].Subject != fmt.Sprintf("activities/%d", existing.Id) {
+		t.Fatalf("expected conflicting activity in the details, got %+v", failure)
+	}
+
+	if _, err = client.StartActivity(ctx, &pb.StartActivityRequest{Category: "review", StartedAt: at(30), FinishedAt: at(90), Resolve: "trim"}); err != nil {
+		t.Fatal(err)
+	}
+	trimmed, err := client.GetActivity(ctx, &pb.ActivityRequest{Id: existing.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !trimmed.FinishedAt.AsTime().Equal(at(30).AsTime()) {
+		t.Errorf("expected overlapping activity trimmed, got %+v", trimmed)
+	}
+}
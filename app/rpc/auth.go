@@ -0,0 +1,93 @@
+package rpc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+const bearer = "Bearer "
+
+// Metadata keys of the calls, the headers of the REST API.
+var (
+	keyUser          = strings.ToLower(httpext.HeaderUser)
+	keyAuthorization = strings.ToLower(httpext.HeaderAuthorization)
+)
+
+// Authenticator returns the context of a call acting as its user, from the metadata of the call.
+type Authenticator func(ctx context.Context, md metadata.MD) (context.Context, error)
+
+// OpenAuth makes the calls of a server without authentication act as the default user never being an admin,
+// calls naming a user in the x-user metadata are refused, like the Open middleware.
+func OpenAuth(usersService service.UsersService) Authenticator {
+	return func(ctx context.Context, md metadata.MD) (context.Context, error) {
+		if names := md.Get(keyUser); len(names) > 0 && names[0] != "" {
+			return nil, status.Error(codes.Unauthenticated, "the x-user metadata is not trusted, start the server with -auth and use an api token")
+		}
+		ctx, err := usersService.Identify(ctx, models.DefaultUserID)
+		if err != nil {
+			return nil, errorOf(err)
+		}
+		return service.WithoutAdmin(ctx), nil
+	}
+}
+
+// TokenAuth requires a personal api token in the authorization metadata and makes the calls act as the user
+// owning it, like the Auth middleware.
+func TokenAuth(tokensRepository repository.TokensRepository, usersService service.UsersService) Authenticator {
+	return func(ctx context.Context, md metadata.MD) (context.Context, error) {
+		values := md.Get(keyAuthorization)
+		if len(values) == 0 || !strings.HasPrefix(values[0], bearer) {
+			return nil, status.Error(codes.Unauthenticated, "missing, invalid or revoked api token")
+		}
+		token, err := tokensRepository.GetByHash(ctx, models.HashToken(strings.TrimSpace(values[0][len(bearer):])))
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && token.Revoked()) {
+			return nil, status.Error(codes.Unauthenticated, "missing, invalid or revoked api token")
+		}
+		if err != nil {
+			return nil, errorOf(err)
+		}
+		ctx, err = usersService.Identify(ctx, token.UserID)
+		if err != nil {
+			return nil, errorOf(err)
+		}
+		return ctx, nil
+	}
+}
+
+func (a Authenticator) unary(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx, err := a(ctx, md)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a Authenticator) stream(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	ctx, err := a(stream.Context(), md)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &identifiedStream{ServerStream: stream, ctx: ctx})
+}
+
+// identifiedStream replaces the context of a stream with the context acting as its user.
+type identifiedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identifiedStream) Context() context.Context {
+	return s.ctx
+}
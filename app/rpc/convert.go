@@ -0,0 +1,165 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ungame/command-time-track/app/broker"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/rpc/pb"
+	"github.com/ungame/command-time-track/app/types"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+// timestampOf returns the timestamp of the time, nil when not set.
+func timestampOf(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// parseTime parses a time of the outputs read from json, nil when missing.
+func parseTime(s *string) (*time.Time, error) {
+	if s == nil || *s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(types.TimeLayout, *s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// parseTimes sets the times of an output read from json, the json of the events only holds their text.
+func parseTimes(output *types.ActivityOutput) error {
+	var (
+		err error
+		t   *time.Time
+	)
+	if t, err = parseTime(&output.StartedAt); err != nil {
+		return err
+	}
+	output.StartedTime = *t
+	if t, err = parseTime(&output.UpdatedAt); err != nil {
+		return err
+	}
+	output.UpdatedTime = *t
+	if output.FinishedTime, err = parseTime(output.FinishedAt); err != nil {
+		return err
+	}
+	if output.DeletedTime, err = parseTime(output.DeletedAt); err != nil {
+		return err
+	}
+	for _, interval := range output.Intervals {
+		if t, err = parseTime(&interval.StartedAt); err != nil {
+			return err
+		}
+		interval.StartedTime = *t
+		if interval.FinishedTime, err = parseTime(interval.FinishedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// timeOf formats a time of the requests like the times of the REST requests, empty when not set.
+func timeOf(timestamp *timestamppb.Timestamp) string {
+	if timestamp == nil {
+		return ""
+	}
+	return timestamp.AsTime().Format(time.RFC3339Nano)
+}
+
+func statusOf(s string) pb.Status {
+	status, err := models.ParseStatus(s)
+	if err != nil {
+		return pb.Status_STATUS_UNSPECIFIED
+	}
+	switch status {
+	case models.StatusStarted:
+		return pb.Status_STATUS_STARTED
+	case models.StatusPaused:
+		return pb.Status_STATUS_PAUSED
+	default:
+		return pb.Status_STATUS_FINISHED
+	}
+}
+
+// statusFilterOf returns the status filter of the activities listed, empty lists activities of any status.
+func statusFilterOf(status pb.Status) string {
+	switch status {
+	case pb.Status_STATUS_STARTED:
+		return models.StatusStarted.String()
+	case pb.Status_STATUS_PAUSED:
+		return models.StatusPaused.String()
+	case pb.Status_STATUS_FINISHED:
+		return models.StatusFinished.String()
+	default:
+		return ""
+	}
+}
+
+func activityOf(output *types.ActivityOutput) *pb.Activity {
+	activity := &pb.Activity{
+		Id:              output.ID,
+		Category:        output.Category,
+		Description:     output.Description,
+		Status:          statusOf(output.Status),
+		StartedAt:       timestamppb.New(output.StartedTime),
+		UpdatedAt:       timestamppb.New(output.UpdatedTime),
+		FinishedAt:      timestampOf(output.FinishedTime),
+		DurationSeconds: output.Duration,
+		Intervals:       make([]*pb.Interval, 0, len(output.Intervals)),
+		Tags:            output.Tags,
+		ProjectId:       output.ProjectID,
+		HourlyRate:      output.HourlyRate,
+		BillableAmount:  output.BillableAmount,
+		InvoiceId:       output.InvoiceID,
+		DeletedAt:       timestampOf(output.DeletedTime),
+	}
+	for _, output := range output.Intervals {
+		activity.Intervals = append(activity.Intervals, &pb.Interval{
+			Id:         output.ID,
+			StartedAt:  timestamppb.New(output.StartedTime),
+			FinishedAt: timestampOf(output.FinishedTime),
+		})
+	}
+	return activity
+}
+
+func activitiesOf(outputs []*types.ActivityOutput) []*pb.Activity {
+	activities := make([]*pb.Activity, 0, len(outputs))
+	for _, output := range outputs {
+		activities = append(activities, activityOf(output))
+	}
+	return activities
+}
+
+// eventOf returns the event of the activities stream, its data is the data of the event of GET /activities/stream.
+func eventOf(event *broker.Event) (*pb.ActivityEvent, error) {
+	data := new(types.ActivityStreamOutput)
+	if err := json.Unmarshal(event.Data, data); err != nil {
+		return nil, fmt.Errorf("invalid data of event %d: %w", event.ID, err)
+	}
+	output := new(types.ActivityOutput)
+	if err := json.Unmarshal(data.Activity, output); err != nil {
+		return nil, fmt.Errorf("invalid activity of event %d: %w", event.ID, err)
+	}
+	if err := parseTimes(output); err != nil {
+		return nil, fmt.Errorf("invalid activity of event %d: %w", event.ID, err)
+	}
+	createdAt, err := parseTime(&data.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data of event %d: %w", event.ID, err)
+	}
+	return &pb.ActivityEvent{
+		Id:         event.ID,
+		Type:       event.Type,
+		ActivityId: data.ActivityID,
+		ActorId:    data.ActorID,
+		Action:     data.Action,
+		Activity:   activityOf(output),
+		CreatedAt:  timestampOf(createdAt),
+	}, nil
+}
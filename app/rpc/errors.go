@@ -0,0 +1,64 @@
+package rpc
+
+import (
+	"errors"
+	"github.com/ungame/command-time-track/app/apperr"
+	"github.com/ungame/command-time-track/app/service"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log"
+	"strconv"
+)
+
+// ViolationConflict is the type of the violations of the conflicts, their subject is the conflicting activity,
+// activities/<id>.
+const ViolationConflict = "CONFLICT"
+
+// codeOf returns the code of the kind of the error, like the status of the REST handlers.
+func codeOf(err error) codes.Code {
+	switch apperr.CodeOf(err) {
+	case apperr.CodeValidation:
+		return codes.InvalidArgument
+	case apperr.CodeConflict:
+		return codes.FailedPrecondition
+	case apperr.CodeNotFound:
+		return codes.NotFound
+	case apperr.CodeForbidden:
+		return codes.PermissionDenied
+	default:
+		return codes.Internal
+	}
+}
+
+// errorOf returns the status error of the service error, internal errors are logged and returned without
+// their message.
+func errorOf(err error) error {
+	code := codeOf(err)
+	if code == codes.Internal {
+		log.Println("Internal error:", err.Error())
+		return status.Error(code, apperr.ErrInternal.Message)
+	}
+	st := status.New(code, err.Error())
+	var conflict *service.ConflictError
+	if errors.As(err, &conflict) {
+		if detailed, err := st.WithDetails(preconditionOf(conflict)); err == nil {
+			st = detailed
+		}
+	}
+	return st.Err()
+}
+
+// preconditionOf returns the conflicting activities of the conflict as the violations of a precondition failure
+// detail, like the conflicts of the REST responses.
+func preconditionOf(conflict *service.ConflictError) *errdetails.PreconditionFailure {
+	failure := &errdetails.PreconditionFailure{Violations: make([]*errdetails.PreconditionFailure_Violation, 0, len(conflict.IDs))}
+	for _, id := range conflict.IDs {
+		failure.Violations = append(failure.Violations, &errdetails.PreconditionFailure_Violation{
+			Type:        ViolationConflict,
+			Subject:     "activities/" + strconv.FormatInt(id, 10),
+			Description: conflict.Reason,
+		})
+	}
+	return failure
+}
@@ -0,0 +1,589 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: activities.proto
+
+// Activities API over gRPC, mirroring the activities endpoints of the REST API.
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Activities_StartActivity_FullMethodName             = "/ctt.activities.v1.Activities/StartActivity"
+	Activities_StopActivity_FullMethodName              = "/ctt.activities.v1.Activities/StopActivity"
+	Activities_PauseActivity_FullMethodName             = "/ctt.activities.v1.Activities/PauseActivity"
+	Activities_ResumeActivity_FullMethodName            = "/ctt.activities.v1.Activities/ResumeActivity"
+	Activities_UpdateActivityCategory_FullMethodName    = "/ctt.activities.v1.Activities/UpdateActivityCategory"
+	Activities_UpdateActivityDescription_FullMethodName = "/ctt.activities.v1.Activities/UpdateActivityDescription"
+	Activities_AddActivityTags_FullMethodName           = "/ctt.activities.v1.Activities/AddActivityTags"
+	Activities_RemoveActivityTags_FullMethodName        = "/ctt.activities.v1.Activities/RemoveActivityTags"
+	Activities_GetActivity_FullMethodName               = "/ctt.activities.v1.Activities/GetActivity"
+	Activities_ListActivities_FullMethodName            = "/ctt.activities.v1.Activities/ListActivities"
+	Activities_DeleteActivity_FullMethodName            = "/ctt.activities.v1.Activities/DeleteActivity"
+	Activities_RestoreActivity_FullMethodName           = "/ctt.activities.v1.Activities/RestoreActivity"
+	Activities_WatchActivities_FullMethodName           = "/ctt.activities.v1.Activities/WatchActivities"
+)
+
+// ActivitiesClient is the client API for Activities service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ActivitiesClient interface {
+	// StartActivity starts an activity, stopping the running activity of the user.
+	StartActivity(ctx context.Context, in *StartActivityRequest, opts ...grpc.CallOption) (*StartActivityResponse, error)
+	StopActivity(ctx context.Context, in *ActivityRequest, opts ...grpc.CallOption) (*Activity, error)
+	PauseActivity(ctx context.Context, in *ActivityRequest, opts ...grpc.CallOption) (*Activity, error)
+	ResumeActivity(ctx context.Context, in *ActivityRequest, opts ...grpc.CallOption) (*Activity, error)
+	UpdateActivityCategory(ctx context.Context, in *UpdateActivityRequest, opts ...grpc.CallOption) (*Activity, error)
+	UpdateActivityDescription(ctx context.Context, in *UpdateActivityRequest, opts ...grpc.CallOption) (*Activity, error)
+	AddActivityTags(ctx context.Context, in *TagActivityRequest, opts ...grpc.CallOption) (*Activity, error)
+	RemoveActivityTags(ctx context.Context, in *TagActivityRequest, opts ...grpc.CallOption) (*Activity, error)
+	GetActivity(ctx context.Context, in *ActivityRequest, opts ...grpc.CallOption) (*Activity, error)
+	ListActivities(ctx context.Context, in *ListActivitiesRequest, opts ...grpc.CallOption) (*ListActivitiesResponse, error)
+	// DeleteActivity moves the activity to the trash.
+	DeleteActivity(ctx context.Context, in *ActivityRequest, opts ...grpc.CallOption) (*DeleteActivityResponse, error)
+	RestoreActivity(ctx context.Context, in *ActivityRequest, opts ...grpc.CallOption) (*Activity, error)
+	// WatchActivities streams the changes of the activities the caller can read, like GET /activities/stream.
+	WatchActivities(ctx context.Context, in *WatchActivitiesRequest, opts ...grpc.CallOption) (Activities_WatchActivitiesClient, error)
+}
+
+type activitiesClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewActivitiesClient(cc grpc.ClientConnInterface) ActivitiesClient {
+	return &activitiesClient{cc}
+}
+
+func (c *activitiesClient) StartActivity(ctx context.Context, in *StartActivityRequest, opts ...grpc.CallOption) (*StartActivityResponse, error) {
+	out := new(StartActivityResponse)
+	err := c.cc.Invoke(ctx, Activities_StartActivity_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *activitiesClient) StopActivity(ctx context.Context, in *ActivityRequest, opts ...grpc.CallOption) (*Activity, error) {
+	out := new(Activity)
+	err := c.cc.Invoke(ctx, Activities_StopActivity_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *activitiesClient) PauseActivity(ctx context.Context, in *ActivityRequest, opts ...grpc.CallOption) (*Activity, error) {
+	out := new(Activity)
+	err := c.cc.Invoke(ctx, Activities_PauseActivity_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *activitiesClient) ResumeActivity(ctx context.Context, in *ActivityRequest, opts ...grpc.CallOption) (*Activity, error) {
+	out := new(Activity)
+	err := c.cc.Invoke(ctx, Activities_ResumeActivity_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *activitiesClient) UpdateActivityCategory(ctx context.Context, in *UpdateActivityRequest, opts ...grpc.CallOption) (*Activity, error) {
+	out := new(Activity)
+	err := c.cc.Invoke(ctx, Activities_UpdateActivityCategory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *activitiesClient) UpdateActivityDescription(ctx context.Context, in *UpdateActivityRequest, opts ...grpc.CallOption) (*Activity, error) {
+	out := new(Activity)
+	err := c.cc.Invoke(ctx, Activities_UpdateActivityDescription_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *activitiesClient) AddActivityTags(ctx context.Context, in *TagActivityRequest, opts ...grpc.CallOption) (*Activity, error) {
+	out := new(Activity)
+	err := c.cc.Invoke(ctx, Activities_AddActivityTags_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *activitiesClient) RemoveActivityTags(ctx context.Context, in *TagActivityRequest, opts ...grpc.CallOption) (*Activity, error) {
+	out := new(Activity)
+	err := c.cc.Invoke(ctx, Activities_RemoveActivityTags_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *activitiesClient) GetActivity(ctx context.Context, in *ActivityRequest, opts ...grpc.CallOption) (*Activity, error) {
+	out := new(Activity)
+	err := c.cc.Invoke(ctx, Activities_GetActivity_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *activitiesClient) ListActivities(ctx context.Context, in *ListActivitiesRequest, opts ...grpc.CallOption) (*ListActivitiesResponse, error) {
+	out := new(ListActivitiesResponse)
+	err := c.cc.Invoke(ctx, Activities_ListActivities_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *activitiesClient) DeleteActivity(ctx context.Context, in *ActivityRequest, opts ...grpc.CallOption) (*DeleteActivityResponse, error) {
+	out := new(DeleteActivityResponse)
+	err := c.cc.Invoke(ctx, Activities_DeleteActivity_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *activitiesClient) RestoreActivity(ctx context.Context, in *ActivityRequest, opts ...grpc.CallOption) (*Activity, error) {
+	out := new(Activity)
+	err := c.cc.Invoke(ctx, Activities_RestoreActivity_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *activitiesClient) WatchActivities(ctx context.Context, in *WatchActivitiesRequest, opts ...grpc.CallOption) (Activities_WatchActivitiesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Activities_ServiceDesc.Streams[0], Activities_WatchActivities_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &activitiesWatchActivitiesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Activities_WatchActivitiesClient interface {
+	Recv() (*ActivityEvent, error)
+	grpc.ClientStream
+}
+
+type activitiesWatchActivitiesClient struct {
+	grpc.ClientStream
+}
+
+func (x *activitiesWatchActivitiesClient) Recv() (*ActivityEvent, error) {
+	m := new(ActivityEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ActivitiesServer is the server API for Activities service.
+// All implementations must embed UnimplementedActivitiesServer
+// for forward compatibility
+type ActivitiesServer interface {
+	// StartActivity starts an activity, stopping the running activity of the user.
+	StartActivity(context.Context, *StartActivityRequest) (*StartActivityResponse, error)
+	StopActivity(context.Context, *ActivityRequest) (*Activity, error)
+	PauseActivity(context.Context, *ActivityRequest) (*Activity, error)
+	ResumeActivity(context.Context, *ActivityRequest) (*Activity, error)
+	UpdateActivityCategory(context.Context, *UpdateActivityRequest) (*Activity, error)
+	UpdateActivityDescription(context.Context, *UpdateActivityRequest) (*Activity, error)
+	AddActivityTags(context.Context, *TagActivityRequest) (*Activity, error)
+	RemoveActivityTags(context.Context, *TagActivityRequest) (*Activity, error)
+	GetActivity(context.Context, *ActivityRequest) (*Activity, error)
+	ListActivities(context.Context, *ListActivitiesRequest) (*ListActivitiesResponse, error)
+	// DeleteActivity moves the activity to the trash.
+	DeleteActivity(context.Context, *ActivityRequest) (*DeleteActivityResponse, error)
+	RestoreActivity(context.Context, *ActivityRequest) (*Activity, error)
+	// WatchActivities streams the changes of the activities the caller can read, like GET /activities/stream.
+	WatchActivities(*WatchActivitiesRequest, Activities_WatchActivitiesServer) error
+	mustEmbedUnimplementedActivitiesServer()
+}
+
+// UnimplementedActivitiesServer must be embedded to have forward compatible implementations.
+type UnimplementedActivitiesServer struct {
+}
+
+func (UnimplementedActivitiesServer) StartActivity(context.Context, *StartActivityRequest) (*StartActivityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartActivity not implemented")
+}
+func (UnimplementedActivitiesServer) StopActivity(context.Context, *ActivityRequest) (*Activity, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopActivity not implemented")
+}
+func (UnimplementedActivitiesServer) PauseActivity(context.Context, *ActivityRequest) (*Activity, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseActivity not implemented")
+}
+func (UnimplementedActivitiesServer) ResumeActivity(context.Context, *ActivityRequest) (*Activity, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeActivity not implemented")
+}
+func (UnimplementedActivitiesServer) UpdateActivityCategory(context.Context, *UpdateActivityRequest) (*Activity, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateActivityCategory not implemented")
+}
+func (UnimplementedActivitiesServer) UpdateActivityDescription(context.Context, *UpdateActivityRequest) (*Activity, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateActivityDescription not implemented")
+}
+func (UnimplementedActivitiesServer) AddActivityTags(context.Context, *TagActivityRequest) (*Activity, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddActivityTags not implemented")
+}
+func (UnimplementedActivitiesServer) RemoveActivityTags(context.Context, *TagActivityRequest) (*Activity, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveActivityTags not implemented")
+}
+func (UnimplementedActivitiesServer) GetActivity(context.Context, *ActivityRequest) (*Activity, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetActivity not implemented")
+}
+func (UnimplementedActivitiesServer) ListActivities(context.Context, *ListActivitiesRequest) (*ListActivitiesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListActivities not implemented")
+}
+func (UnimplementedActivitiesServer) DeleteActivity(context.Context, *ActivityRequest) (*DeleteActivityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteActivity not implemented")
+}
+func (UnimplementedActivitiesServer) RestoreActivity(context.Context, *ActivityRequest) (*Activity, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreActivity not implemented")
+}
+func (UnimplementedActivitiesServer) WatchActivities(*WatchActivitiesRequest, Activities_WatchActivitiesServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchActivities not implemented")
+}
+func (UnimplementedActivitiesServer) mustEmbedUnimplementedActivitiesServer() {}
+
+// UnsafeActivitiesServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ActivitiesServer will
+// result in compilation errors.
+type UnsafeActivitiesServer interface {
+	mustEmbedUnimplementedActivitiesServer()
+}
+
+func RegisterActivitiesServer(s grpc.ServiceRegistrar, srv ActivitiesServer) {
+	s.RegisterService(&Activities_ServiceDesc, srv)
+}
+
+func _Activities_StartActivity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartActivityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ActivitiesServer).StartActivity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Activities_StartActivity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ActivitiesServer).StartActivity(ctx, req.(*StartActivityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Activities_StopActivity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActivityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ActivitiesServer).StopActivity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Activities_StopActivity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ActivitiesServer).StopActivity(ctx, req.(*ActivityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Activities_PauseActivity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActivityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ActivitiesServer).PauseActivity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Activities_PauseActivity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ActivitiesServer).PauseActivity(ctx, req.(*ActivityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Activities_ResumeActivity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActivityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ActivitiesServer).ResumeActivity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Activities_ResumeActivity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ActivitiesServer).ResumeActivity(ctx, req.(*ActivityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Activities_UpdateActivityCategory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateActivityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ActivitiesServer).UpdateActivityCategory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Activities_UpdateActivityCategory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ActivitiesServer).UpdateActivityCategory(ctx, req.(*UpdateActivityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Activities_UpdateActivityDescription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateActivityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ActivitiesServer).UpdateActivityDescription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Activities_UpdateActivityDescription_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ActivitiesServer).UpdateActivityDescription(ctx, req.(*UpdateActivityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Activities_AddActivityTags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TagActivityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ActivitiesServer).AddActivityTags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Activities_AddActivityTags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ActivitiesServer).AddActivityTags(ctx, req.(*TagActivityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Activities_RemoveActivityTags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TagActivityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ActivitiesServer).RemoveActivityTags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Activities_RemoveActivityTags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ActivitiesServer).RemoveActivityTags(ctx, req.(*TagActivityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Activities_GetActivity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActivityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ActivitiesServer).GetActivity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Activities_GetActivity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ActivitiesServer).GetActivity(ctx, req.(*ActivityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Activities_ListActivities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListActivitiesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ActivitiesServer).ListActivities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Activities_ListActivities_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ActivitiesServer).ListActivities(ctx, req.(*ListActivitiesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Activities_DeleteActivity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActivityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ActivitiesServer).DeleteActivity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Activities_DeleteActivity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ActivitiesServer).DeleteActivity(ctx, req.(*ActivityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Activities_RestoreActivity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActivityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ActivitiesServer).RestoreActivity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Activities_RestoreActivity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ActivitiesServer).RestoreActivity(ctx, req.(*ActivityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Activities_WatchActivities_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchActivitiesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ActivitiesServer).WatchActivities(m, &activitiesWatchActivitiesServer{stream})
+}
+
+type Activities_WatchActivitiesServer interface {
+	Send(*ActivityEvent) error
+	grpc.ServerStream
+}
+
+type activitiesWatchActivitiesServer struct {
+	grpc.ServerStream
+}
+
+func (x *activitiesWatchActivitiesServer) Send(m *ActivityEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Activities_ServiceDesc is the grpc.ServiceDesc for Activities service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Activities_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ctt.activities.v1.Activities",
+	HandlerType: (*ActivitiesServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "StartActivity",
+			Handler:    _Activities_StartActivity_Handler,
+		},
+		{
+			MethodName: "StopActivity",
+			Handler:    _Activities_StopActivity_Handler,
+		},
+		{
+			MethodName: "PauseActivity",
+			Handler:    _Activities_PauseActivity_Handler,
+		},
+		{
+			MethodName: "ResumeActivity",
+			Handler:    _Activities_ResumeActivity_Handler,
+		},
+		{
+			MethodName: "UpdateActivityCategory",
+			Handler:    _Activities_UpdateActivityCategory_Handler,
+		},
+		{
+			MethodName: "UpdateActivityDescription",
+			Handler:    _Activities_UpdateActivityDescription_Handler,
+		},
+		{
+			MethodName: "AddActivityTags",
+			Handler:    _Activities_AddActivityTags_Handler,
+		},
+		{
+			MethodName: "RemoveActivityTags",
+			Handler:    _Activities_RemoveActivityTags_Handler,
+		},
+		{
+			MethodName: "GetActivity",
+			Handler:    _Activities_GetActivity_Handler,
+		},
+		{
+			MethodName: "ListActivities",
+			Handler:    _Activities_ListActivities_Handler,
+		},
+		{
+			MethodName: "DeleteActivity",
+			Handler:    _Activities_DeleteActivity_Handler,
+		},
+		{
+			MethodName: "RestoreActivity",
+			Handler:    _Activities_RestoreActivity_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchActivities",
+			Handler:       _Activities_WatchActivities_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "activities.proto",
+}
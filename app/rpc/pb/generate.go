@@ -0,0 +1,6 @@
+package pb
+
+// Regenerate the messages and stubs after changing activities.proto, with protoc 23.4, protoc-gen-go v1.30.0 and
+// protoc-gen-go-grpc v1.3.0 on the PATH. Other versions of protoc are refused, they change the generated code.
+//go:generate sh -c "protoc --version | grep -qx 'libprotoc 23.4' || { echo 'protoc 23.4 required, got' $(protoc --version) >&2; exit 1; }"
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative activities.proto
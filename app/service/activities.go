@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/ungame/command-time-track/app/models"
 	"github.com/ungame/command-time-track/app/observer"
@@ -10,72 +11,307 @@ import (
 	"github.com/ungame/command-time-track/app/repository"
 	"github.com/ungame/command-time-track/app/types"
 	"log"
-	"sync"
 	"time"
 )
 
 type ActivitiesService interface {
-	StartActivity(ctx context.Context, input *types.StartActivityInput) (*types.ActivityOutput, error)
+	StartActivity(ctx context.Context, input *types.StartActivityInput) (*types.StartActivityOutput, error)
+	CreateManualActivity(ctx context.Context, input *types.StartActivityInput) (*types.ActivityOutput, error)
 	StopActivity(ctx context.Context, input *types.UpdateActivityInput) (*types.ActivityOutput, error)
+	PauseActivity(ctx context.Context, input *types.UpdateActivityInput) (*types.ActivityOutput, error)
+	ResumeActivity(ctx context.Context, input *types.UpdateActivityInput) (*types.ActivityOutput, error)
 	UpdateActivityCategory(ctx context.Context, input *types.UpdateActivityInput) (*types.ActivityOutput, error)
 	UpdateActivityDescription(ctx context.Context, input *types.UpdateActivityInput) (*types.ActivityOutput, error)
+	UpdateActivityProject(ctx context.Context, input *types.UpdateActivityProjectInput) (*types.ActivityOutput, error)
+	UpdateActivityRate(ctx context.Context, input *types.UpdateActivityRateInput) (*types.ActivityOutput, error)
+	AddActivityTags(ctx context.Context, input *types.TagActivityInput) (*types.ActivityOutput, error)
+	RemoveActivityTags(ctx context.Context, input *types.TagActivityInput) (*types.ActivityOutput, error)
 	GetActivityByID(ctx context.Context, input *types.GetActivityInput) (*types.ActivityOutput, error)
-	ListActivities(ctx context.Context) ([]*types.ActivityOutput, error)
-	SearchActivities(ctx context.Context, term string) ([]*types.ActivityOutput, error)
+	GetActivityHistory(ctx context.Context, input *types.GetActivityInput) ([]*types.ActivityEventOutput, error)
+	ListActivities(ctx context.Context, input *types.ListActivitiesInput) (*types.ListActivitiesOutput, error)
+	ExportActivities(ctx context.Context, input *types.ListActivitiesInput, fn func(output *types.ExportActivityOutput) error) error
+	SearchActivities(ctx context.Context, input *types.SearchActivitiesInput) ([]*types.ActivityOutput, error)
 	DeleteActivityByID(ctx context.Context, input *types.DeleteActivityInput) (int64, error)
-	Close()
+	RestoreActivity(ctx context.Context, input *types.RestoreActivityInput) (*types.ActivityOutput, error)
+	ListTrash(ctx context.Context, input *types.ListTrashInput) ([]*types.ActivityOutput, error)
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
 }
 
 type activitiesService struct {
+	transactor           repository.Transactor
 	activitiesRepository repository.ActivitiesRepository
+	projectsRepository   repository.ProjectsRepository
+	clientsRepository    repository.ClientsRepository
 	activitiesObserver   observer.ActivitiesObserver
-	waitGroup            *sync.WaitGroup
+	audit                *audit
 }
 
-func NewActivitiesService(activitiesRepository repository.ActivitiesRepository, activitiesObserver observer.ActivitiesObserver) ActivitiesService {
+func NewActivitiesService(
+	transactor repository.Transactor,
+	activitiesRepository repository.ActivitiesRepository,
+	activityEventsRepository repository.ActivityEventsRepository,
+	projectsRepository repository.ProjectsRepository,
+	clientsRepository repository.ClientsRepository,
+	activitiesObserver observer.ActivitiesObserver,
+) ActivitiesService {
 	return &activitiesService{
+		transactor:           transactor,
 		activitiesRepository: activitiesRepository,
+		projectsRepository:   projectsRepository,
+		clientsRepository:    clientsRepository,
 		activitiesObserver:   activitiesObserver,
-		waitGroup:            &sync.WaitGroup{},
+		audit:                &audit{events: activityEventsRepository},
 	}
 }
 
-func (s *activitiesService) StartActivity(ctx context.Context, input *types.StartActivityInput) (*types.ActivityOutput, error) {
+func (s *activitiesService) billing() *billing {
+	return newBilling(s.projectsRepository, s.clientsRepository)
+}
 
-	started, err := s.activitiesRepository.GetByStatus(ctx, models.StatusStarted)
+// out returns the output of a single activity, see billing.out.
+func (s *activitiesService) out(ctx context.Context, activity *models.Activity) (*types.ActivityOutput, error) {
+	return s.billing().out(ctx, activity)
+}
+
+// update saves the changes made to the activity and appends the action to its history in a single transaction,
+// before is the snapshot of the activity taken ahead of the changes.
+func (s *activitiesService) update(ctx context.Context, action string, activity *models.Activity, before *string) error {
+	return s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.activitiesRepository.Update(ctx, activity); err != nil {
+			return err
+		}
+		return s.audit.record(ctx, action, activity, before)
+	})
+}
 
-	if err != nil && err != sql.ErrNoRows {
+// create saves the new activity and appends it to its history, it must run in a transaction.
+func (s *activitiesService) create(ctx context.Context, activity *models.Activity) error {
+	if _, err := s.activitiesRepository.Create(ctx, activity); err != nil {
+		return err
+	}
+	return s.audit.record(ctx, models.ActionCreated, activity, nil)
+}
+
+// StartActivity stops the started activities and creates the new one in a single transaction,
+// the stopped activities finish exactly when the new one starts.
+func (s *activitiesService) StartActivity(ctx context.Context, input *types.StartActivityInput) (*types.StartActivityOutput, error) {
+	if err := authorize(ctx, permWrite); err != nil {
 		return nil, err
 	}
 
-	if len(started) != 0 {
-		s.asyncStopActivities(started)
+	now := time.Now().UTC()
+
+	startedAt, finishedAt, err := activityPeriod(input, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = validResolve(input.Resolve); err != nil {
+		return nil, err
+	}
+
+	tags, err := models.ParseTags(input.Tags)
+	if err != nil {
+		return nil, invalidInput(err)
 	}
 
 	activity := &models.Activity{
 		Category:    input.Category,
 		Description: input.Description,
-		StartedAt:   time.Now().UTC(),
-		UpdatedAt:   time.Now().UTC(),
+		StartedAt:   *startedAt,
+		UpdatedAt:   now,
+		Intervals:   []*models.Interval{{StartedAt: *startedAt}},
 	}
+	activity.AddTags(tags...)
 
-	activity.ID, err = s.activitiesRepository.Create(ctx, activity)
-	if err != nil {
+	if err = s.checkProject(ctx, input.ProjectID); err != nil {
 		return nil, err
 	}
+	activity.ProjectID = input.ProjectID
 
-	s.activitiesObserver.Count(activity.Category)
+	if activity.HourlyRate, err = hourlyRate(input.HourlyRate); err != nil {
+		return nil, err
+	}
 
-	activity.Status = models.StatusStarted
+	if finishedAt != nil {
+		output, err := s.createFinishedActivity(ctx, activity, input.Resolve, *finishedAt, now)
+		if err != nil {
+			return nil, err
+		}
+		return &types.StartActivityOutput{ActivityOutput: output}, nil
+	}
+
+	var stopped []*models.Activity
+
+	err = s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		// a concurrent start of the user waits, then sees the activity it started running
+		if err := s.activitiesRepository.LockStarted(ctx); err != nil {
+			return err
+		}
+		// started activities stop when the new one starts, they only overlap if they started later
+		overlaps, err := checkOverlaps(ctx, s.activitiesRepository, activity, input.Resolve, startedAt)
+		if err != nil {
+			return err
+		}
+		if err = resolveOverlaps(ctx, s.activitiesRepository, s.audit, activity, input.Resolve, overlaps, now); err != nil {
+			return err
+		}
+		if stopped, err = s.stopStartedActivities(ctx, *startedAt, now); err != nil {
+			return err
+		}
+		return s.create(ctx, activity)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.activitiesObserver.Count(activity.Category, activity.Tags...)
 
 	log.Printf("Activity created: ID=%v\n", activity.ID)
 
-	return activity.Out(), nil
+	bill := s.billing()
+
+	activityOutput, err := bill.out(ctx, activity)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &types.StartActivityOutput{
+		ActivityOutput: activityOutput,
+		Stopped:        make([]*types.ActivityOutput, 0, len(stopped)),
+	}
+	for _, activity := range stopped {
+		s.activitiesObserver.DurationOf(activity.Category, activity.Duration(now))
+		stoppedOutput, err := bill.out(ctx, activity)
+		if err != nil {
+			return nil, err
+		}
+		output.Stopped = append(output.Stopped, stoppedOutput)
+	}
+
+	return output, nil
+
+}
+
+// stopStartedActivities finishes the started activities at the given time, it must run in a transaction holding
+// the lock of the started activities.
+func (s *activitiesService) stopStartedActivities(ctx context.Context, at, now time.Time) ([]*models.Activity, error) {
+	started, err := s.activitiesRepository.GetByStatus(ctx, models.StatusStarted)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	for _, activity := range started {
+		before := snapshot(activity)
+
+		activity.Finish(at)
+		activity.UpdatedAt = now
+
+		if _, err = s.activitiesRepository.Update(ctx, activity); err != nil {
+			return nil, err
+		}
+		if err = s.audit.record(ctx, models.ActionStopped, activity, before); err != nil {
+			return nil, err
+		}
+
+		log.Printf("Activity stopped: ID=%v\n", activity.ID)
+	}
+
+	return started, nil
+}
+
+// checkProject returns an invalid input error when the project of an activity does not exist.
+func (s *activitiesService) checkProject(ctx context.Context, projectID *int64) error {
+	if projectID == nil {
+		return nil
+	}
+	_, err := s.projectsRepository.Get(ctx, *projectID)
+	if err = notFound(err, "project", *projectID); errors.Is(err, ErrNotFound) {
+		return invalidInput(err)
+	}
+	return err
+}
+
+func (s *activitiesService) CreateManualActivity(ctx context.Context, input *types.StartActivityInput) (*types.ActivityOutput, error) {
+	if input.StartedAt == "" || input.FinishedAt == "" {
+		return nil, invalidInput(errors.New("started_at and finished_at are required"))
+	}
+	output, err := s.StartActivity(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return output.ActivityOutput, nil
+}
+
+// createFinishedActivity records a past activity, running activities are left untouched.
+func (s *activitiesService) createFinishedActivity(ctx context.Context, activity *models.Activity, resolve string, finishedAt, now time.Time) (*types.ActivityOutput, error) {
+	activity.Status = models.StatusFinished
+	activity.FinishedAt = pointer.New(finishedAt)
+	activity.Intervals[0].FinishedAt = pointer.New(finishedAt)
+
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		overlaps, err := checkOverlaps(ctx, s.activitiesRepository, activity, resolve, nil)
+		if err != nil {
+			return err
+		}
+		if err = resolveOverlaps(ctx, s.activitiesRepository, s.audit, activity, resolve, overlaps, now); err != nil {
+			return err
+		}
+		return s.create(ctx, activity)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.activitiesObserver.Count(activity.Category, activity.Tags...)
+	s.activitiesObserver.DurationOf(activity.Category, activity.Duration(now))
+
+	log.Printf("Activity created: ID=%v, finished\n", activity.ID)
+
+	return s.out(ctx, activity)
+}
+
+// activityPeriod returns the start and finish of the input, starting now when not given.
+// Neither may be in the future and the activity can't finish before it starts.
+func activityPeriod(input *types.StartActivityInput, now time.Time) (*time.Time, *time.Time, error) {
+	startedAt, err := parseTime(input.StartedAt)
+	if err != nil {
+		return nil, nil, invalidField("started_at", fmt.Errorf("invalid started_at: %w", err))
+	}
+	finishedAt, err := parseTime(input.FinishedAt)
+	if err != nil {
+		return nil, nil, invalidField("finished_at", fmt.Errorf("invalid finished_at: %w", err))
+	}
+
+	if startedAt == nil {
+		if finishedAt != nil {
+			return nil, nil, invalidField("started_at", errors.New("started_at is required with finished_at"))
+		}
+		startedAt = &now
+	}
+
+	if startedAt.After(now) {
+		return nil, nil, invalidField("started_at", errors.New("started_at is in the future"))
+	}
+	if finishedAt != nil {
+		if finishedAt.After(now) {
+			return nil, nil, invalidField("finished_at", errors.New("finished_at is in the future"))
+		}
+		if !finishedAt.After(*startedAt) {
+			return nil, nil, invalidField("finished_at", errors.New("finished_at must be after started_at"))
+		}
+	}
 
+	return startedAt, finishedAt, nil
 }
 
 func (s *activitiesService) StopActivity(ctx context.Context, input *types.UpdateActivityInput) (*types.ActivityOutput, error) {
 
+	ctx, err := accessActivity(ctx, s.activitiesRepository, input.ID, permWrite)
+	if err != nil {
+		return nil, err
+	}
+
 	existing, err := s.activitiesRepository.Get(ctx, input.ID)
 	if err != nil {
 		return nil, err
@@ -83,122 +319,456 @@ func (s *activitiesService) StopActivity(ctx context.Context, input *types.Updat
 
 	if existing.Status != models.StatusFinished {
 
-		existing.Status = models.StatusFinished
-		existing.FinishedAt = pointer.New(time.Now().UTC())
-		existing.UpdatedAt = time.Now().UTC()
+		now := time.Now().UTC()
+		before := snapshot(existing)
 
-		_, err := s.activitiesRepository.Update(ctx, existing)
-		if err != nil {
+		existing.Finish(now)
+
+		if err := s.update(ctx, models.ActionStopped, existing, before); err != nil {
 			return nil, err
 		}
 
-		s.activitiesObserver.DurationOf(existing.Category, existing.StartedAt)
+		s.activitiesObserver.DurationOf(existing.Category, existing.Duration(now))
 
 		log.Printf("Activity stopped: ID=%v\n", existing.ID)
 	}
 
-	return existing.Out(), nil
+	return s.out(ctx, existing)
 }
 
-func (s *activitiesService) asyncStopActivities(activities []*models.Activity) {
-	for _, activity := range activities {
-		s.waitGroup.Add(1)
-		go func(id int64) {
-			defer s.waitGroup.Done()
-			_, err := s.StopActivity(context.Background(), &types.UpdateActivityInput{ID: id})
-			if err != nil {
-				log.Println("Error on stop activity in background:", err.Error())
-			}
-		}(activity.ID)
+func (s *activitiesService) PauseActivity(ctx context.Context, input *types.UpdateActivityInput) (*types.ActivityOutput, error) {
+
+	ctx, err := accessActivity(ctx, s.activitiesRepository, input.ID, permWrite)
+	if err != nil {
+		return nil, err
 	}
+
+	existing, err := s.activitiesRepository.Get(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.Status == models.StatusFinished {
+		return nil, conflict("unable to pause finished activity: ID=%v", existing.ID)
+	}
+
+	if existing.Status != models.StatusPaused {
+
+		before := snapshot(existing)
+
+		existing.Pause(time.Now().UTC())
+
+		if err := s.update(ctx, models.ActionPaused, existing, before); err != nil {
+			return nil, err
+		}
+
+		log.Printf("Activity paused: ID=%v\n", existing.ID)
+	}
+
+	return s.out(ctx, existing)
+}
+
+// ResumeActivity stops the started activities and resumes the paused one in a single transaction.
+func (s *activitiesService) ResumeActivity(ctx context.Context, input *types.UpdateActivityInput) (*types.ActivityOutput, error) {
+	ctx, err := accessActivity(ctx, s.activitiesRepository, input.ID, permWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		existing *models.Activity
+		stopped  []*models.Activity
+		now      = time.Now().UTC()
+	)
+
+	err = s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		err := s.activitiesRepository.LockStarted(ctx)
+		if err != nil {
+			return err
+		}
+
+		existing, err = s.activitiesRepository.Get(ctx, input.ID)
+		if err != nil {
+			return err
+		}
+
+		if existing.Status == models.StatusFinished {
+			return conflict("unable to resume finished activity: ID=%v", existing.ID)
+		}
+
+		if existing.Status == models.StatusStarted {
+			return nil
+		}
+
+		if stopped, err = s.stopStartedActivities(ctx, now, now); err != nil {
+			return err
+		}
+
+		before := snapshot(existing)
+
+		existing.Resume(now)
+
+		if _, err = s.activitiesRepository.Update(ctx, existing); err != nil {
+			return err
+		}
+		if err = s.audit.record(ctx, models.ActionResumed, existing, before); err != nil {
+			return err
+		}
+
+		log.Printf("Activity resumed: ID=%v\n", existing.ID)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, activity := range stopped {
+		s.activitiesObserver.DurationOf(activity.Category, activity.Duration(now))
+	}
+
+	return s.out(ctx, existing)
 }
 
 func (s *activitiesService) UpdateActivityCategory(ctx context.Context, input *types.UpdateActivityInput) (*types.ActivityOutput, error) {
 
+	ctx, err := accessActivity(ctx, s.activitiesRepository, input.ID, permWrite)
+	if err != nil {
+		return nil, err
+	}
+
 	existing, err := s.activitiesRepository.Get(ctx, input.ID)
 	if err != nil {
 		return nil, err
 	}
 
 	if existing.Category != input.Category {
+		before := snapshot(existing)
 
 		existing.Category = input.Category
 		existing.UpdatedAt = time.Now().UTC()
 
-		_, err := s.activitiesRepository.Update(ctx, existing)
-		if err != nil {
+		if err := s.update(ctx, models.ActionCategoryUpdated, existing, before); err != nil {
 			return nil, err
 		}
 
 		log.Printf("Activity category updated: ID=%v\n", existing.ID)
 	}
 
-	return existing.Out(), nil
+	return s.out(ctx, existing)
 }
 
 func (s *activitiesService) UpdateActivityDescription(ctx context.Context, input *types.UpdateActivityInput) (*types.ActivityOutput, error) {
 
+	ctx, err := accessActivity(ctx, s.activitiesRepository, input.ID, permWrite)
+	if err != nil {
+		return nil, err
+	}
+
 	existing, err := s.activitiesRepository.Get(ctx, input.ID)
 	if err != nil {
 		return nil, err
 	}
 
 	if existing.Description != input.Description {
+		before := snapshot(existing)
+
 		existing.Description = input.Description
 		existing.UpdatedAt = time.Now().UTC()
 
-		_, err := s.activitiesRepository.Update(ctx, existing)
-		if err != nil {
+		if err := s.update(ctx, models.ActionDescriptionUpdated, existing, before); err != nil {
 			return nil, err
 		}
 
 		log.Printf("Activity description updated: ID=%v\n", existing.ID)
 	}
 
-	return existing.Out(), nil
+	return s.out(ctx, existing)
 }
 
-func (s *activitiesService) ListActivities(ctx context.Context) ([]*types.ActivityOutput, error) {
-	activities, err := s.activitiesRepository.GetAll(ctx)
+// UpdateActivityProject moves the activity to another project, nil removes it from its project.
+func (s *activitiesService) UpdateActivityProject(ctx context.Context, input *types.UpdateActivityProjectInput) (*types.ActivityOutput, error) {
+
+	ctx, err := accessActivity(ctx, s.activitiesRepository, input.ID, permWrite)
 	if err != nil {
 		return nil, err
 	}
-	output := make([]*types.ActivityOutput, 0, len(activities))
+
+	existing, err := s.activitiesRepository.Get(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.checkProject(ctx, input.ProjectID); err != nil {
+		return nil, err
+	}
+	before := snapshot(existing)
+	existing.ProjectID = input.ProjectID
+	existing.UpdatedAt = time.Now().UTC()
+
+	if err = s.update(ctx, models.ActionProjectUpdated, existing, before); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Activity project updated: ID=%v\n", existing.ID)
+
+	return s.out(ctx, existing)
+}
+
+// UpdateActivityRate overrides the hourly rate of the project on the activity, nil falls back to the project rate.
+func (s *activitiesService) UpdateActivityRate(ctx context.Context, input *types.UpdateActivityRateInput) (*types.ActivityOutput, error) {
+
+	ctx, err := accessActivity(ctx, s.activitiesRepository, input.ID, permWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.activitiesRepository.Get(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rate, err := hourlyRate(input.HourlyRate)
+	if err != nil {
+		return nil, err
+	}
+	before := snapshot(existing)
+	existing.HourlyRate = rate
+	existing.UpdatedAt = time.Now().UTC()
+
+	if err = s.update(ctx, models.ActionRateUpdated, existing, before); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Activity hourly rate updated: ID=%v\n", existing.ID)
+
+	return s.out(ctx, existing)
+}
+
+func (s *activitiesService) AddActivityTags(ctx context.Context, input *types.TagActivityInput) (*types.ActivityOutput, error) {
+	tags, err := models.ParseTags(input.Tags)
+	if err != nil {
+		return nil, invalidInput(err)
+	}
+	if len(tags) == 0 {
+		return nil, invalidField("tags", errors.New("tags are required"))
+	}
+
+	ctx, err = accessActivity(ctx, s.activitiesRepository, input.ID, permWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.activitiesRepository.Get(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := snapshot(existing)
+
+	if existing.AddTags(tags...) {
+		existing.UpdatedAt = time.Now().UTC()
+
+		if err := s.update(ctx, models.ActionTagged, existing, before); err != nil {
+			return nil, err
+		}
+
+		log.Printf("Activity tagged: ID=%v, tags=%v\n", existing.ID, tags)
+	}
+
+	return s.out(ctx, existing)
+}
+
+func (s *activitiesService) RemoveActivityTags(ctx context.Context, input *types.TagActivityInput) (*types.ActivityOutput, error) {
+	tags, err := models.ParseTags(input.Tags)
+	if err != nil {
+		return nil, invalidInput(err)
+	}
+
+	ctx, err = accessActivity(ctx, s.activitiesRepository, input.ID, permWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.activitiesRepository.Get(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := snapshot(existing)
+
+	if existing.RemoveTags(tags...) {
+		existing.UpdatedAt = time.Now().UTC()
+
+		if err := s.update(ctx, models.ActionUntagged, existing, before); err != nil {
+			return nil, err
+		}
+
+		log.Printf("Activity untagged: ID=%v, tags=%v\n", existing.ID, tags)
+	}
+
+	return s.out(ctx, existing)
+}
+
+func (s *activitiesService) ListActivities(ctx context.Context, input *types.ListActivitiesInput) (*types.ListActivitiesOutput, error) {
+	query, err := newActivitiesQuery(input)
+	if err != nil {
+		return nil, err
+	}
+	if ctx, err = scopeTo(ctx, input.UserID); err != nil {
+		return nil, err
+	}
+
+	limit := query.Limit
+	query.Limit++
+
+	activities, err := s.activitiesRepository.Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &types.ListActivitiesOutput{Activities: make([]*types.ActivityOutput, 0, len(activities))}
+
+	if len(activities) > limit {
+		activities = activities[:limit]
+		output.NextCursor = repository.NewCursor(query.Sort, activities[limit-1]).Encode()
+	}
+
+	bill := s.billing()
+
 	for _, activity := range activities {
-		output = append(output, activity.Out())
+		activityOutput, err := bill.out(ctx, activity)
+		if err != nil {
+			return nil, err
+		}
+		output.Activities = append(output.Activities, activityOutput)
 	}
+
 	return output, nil
 }
 
+// ExportActivities calls fn for every activity matching the input filters, limit and cursor are ignored.
+func (s *activitiesService) ExportActivities(ctx context.Context, input *types.ListActivitiesInput, fn func(output *types.ExportActivityOutput) error) error {
+	query, err := newActivitiesQuery(&types.ListActivitiesInput{
+		From:      input.From,
+		To:        input.To,
+		Category:  input.Category,
+		ProjectID: input.ProjectID,
+		Tags:      input.Tags,
+		Status:    input.Status,
+		Sort:      input.Sort,
+		Order:     input.Order,
+	})
+	if err != nil {
+		return err
+	}
+	if ctx, err = scopeTo(ctx, input.UserID); err != nil {
+		return err
+	}
+
+	var (
+		now  = time.Now().UTC()
+		bill = s.billing()
+	)
+
+	return repository.Each(ctx, s.activitiesRepository, *query, func(activity *models.Activity) error {
+		output, err := bill.export(ctx, activity, now)
+		if err != nil {
+			return err
+		}
+		return fn(output)
+	})
+}
+
 func (s *activitiesService) GetActivityByID(ctx context.Context, input *types.GetActivityInput) (*types.ActivityOutput, error) {
+	ctx, err := accessActivity(ctx, s.activitiesRepository, input.ID, permRead)
+	if err != nil {
+		return nil, err
+	}
+
 	activity, err := s.activitiesRepository.Get(ctx, input.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	return activity.Out(), nil
+	return s.out(ctx, activity)
+}
+
+// GetActivityHistory returns the changes made to the activity, oldest first. The history of deleted
+// activities is kept, so it is checked against the owner recorded in the events.
+func (s *activitiesService) GetActivityHistory(ctx context.Context, input *types.GetActivityInput) ([]*types.ActivityEventOutput, error) {
+	events, err := s.audit.events.GetByActivity(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, notFound(sql.ErrNoRows, "activity", input.ID)
+	}
+	if identity := identityFrom(ctx); identity != nil {
+		if err = identity.can(permRead, events[0].OwnerID); err != nil {
+			return nil, err
+		}
+	}
+	output := make([]*types.ActivityEventOutput, 0, len(events))
+	for _, event := range events {
+		output = append(output, event.Out())
+	}
+	return output, nil
 }
 
-func (s *activitiesService) SearchActivities(ctx context.Context, term string) ([]*types.ActivityOutput, error) {
-	activities, err := s.activitiesRepository.Search(ctx, term)
+// SearchActivities returns the activities matching the term and having every one of the tags.
+func (s *activitiesService) SearchActivities(ctx context.Context, input *types.SearchActivitiesInput) ([]*types.ActivityOutput, error) {
+	tags, err := models.ParseTags(input.Tags)
 	if err != nil {
+		return nil, invalidInput(err)
+	}
+	if ctx, err = scopeTo(ctx, input.UserID); err != nil {
 		return nil, err
 	}
+	activities, err := s.activitiesRepository.Search(ctx, input.Term)
+	if err != nil {
+		return nil, err
+	}
+	bill := s.billing()
 	output := make([]*types.ActivityOutput, 0, len(activities))
 	for _, activity := range activities {
-		output = append(output, activity.Out())
+		if !activity.HasTags(tags...) {
+			continue
+		}
+		activityOutput, err := bill.out(ctx, activity)
+		if err != nil {
+			return nil, err
+		}
+		output = append(output, activityOutput)
 	}
 	return output, nil
 }
 
 func (s *activitiesService) DeleteActivityByID(ctx context.Context, input *types.DeleteActivityInput) (int64, error) {
-	rows, err := s.activitiesRepository.Delete(ctx, input.ID)
+	ctx, err := accessActivity(ctx, s.activitiesRepository, input.ID, permWrite)
 	if err != nil {
 		return 0, err
 	}
 
-	if rows == 0 {
-		return 0, fmt.Errorf("unable to delete activity: ID=%v", input.ID)
+	err = s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		existing, err := s.activitiesRepository.Get(ctx, input.ID)
+		if err != nil {
+			return err
+		}
+		rows, err := s.activitiesRepository.Delete(ctx, input.ID)
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return notFound(sql.ErrNoRows, "activity", input.ID)
+		}
+		deleted, err := s.activitiesRepository.GetDeleted(ctx, input.ID)
+		if err != nil {
+			return err
+		}
+		return s.audit.record(ctx, models.ActionDeleted, deleted, snapshot(existing))
+	})
+	if err != nil {
+		return 0, err
 	}
 
 	log.Printf("Activity deleted: ID=%v\n", input.ID)
@@ -206,6 +776,92 @@ func (s *activitiesService) DeleteActivityByID(ctx context.Context, input *types
 	return input.ID, nil
 }
 
-func (s *activitiesService) Close() {
-	s.waitGroup.Wait()
+// RestoreActivity takes the activity out of the trash.
+func (s *activitiesService) RestoreActivity(ctx context.Context, input *types.RestoreActivityInput) (*types.ActivityOutput, error) {
+	ctx, err := accessDeletedActivity(ctx, s.activitiesRepository, input.ID, permWrite)
+	if err != nil {
+		return nil, notFound(err, "deleted activity", input.ID)
+	}
+
+	var restored *models.Activity
+
+	err = s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		deleted, err := s.activitiesRepository.GetDeleted(ctx, input.ID)
+		if err != nil {
+			return err
+		}
+		if _, err = s.activitiesRepository.Restore(ctx, input.ID); err != nil {
+			return err
+		}
+		if restored, err = s.activitiesRepository.Get(ctx, input.ID); err != nil {
+			return err
+		}
+		return s.audit.record(ctx, models.ActionRestored, restored, snapshot(deleted))
+	})
+	if err != nil {
+		return nil, notFound(err, "deleted activity", input.ID)
+	}
+
+	log.Printf("Activity restored: ID=%v\n", input.ID)
+
+	return s.out(ctx, restored)
+}
+
+// ListTrash returns the activities in the trash, the last deleted first.
+func (s *activitiesService) ListTrash(ctx context.Context, input *types.ListTrashInput) ([]*types.ActivityOutput, error) {
+	ctx, err := scopeTo(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	activities, err := s.activitiesRepository.Trash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bill := s.billing()
+	output := make([]*types.ActivityOutput, 0, len(activities))
+	for _, activity := range activities {
+		activityOutput, err := bill.out(ctx, activity)
+		if err != nil {
+			return nil, err
+		}
+		output = append(output, activityOutput)
+	}
+	return output, nil
+}
+
+// PurgeTrash deletes for good the activities of every user deleted before the given time, returning how many were purged.
+// Each activity is purged in its own transaction, along with its event.
+func (s *activitiesService) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	if err := authorize(ctx, permManage); err != nil {
+		return 0, err
+	}
+
+	ctx = repository.AllOwners(ctx)
+
+	trash, err := s.activitiesRepository.Trash(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var purged int64
+
+	for _, activity := range trash {
+		if !activity.DeletedAt.Before(before) {
+			continue
+		}
+		var rows int64
+		err = s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+			var err error
+			if rows, err = s.activitiesRepository.Purge(ctx, activity.ID); err != nil || rows == 0 {
+				return err
+			}
+			return s.audit.record(ctx, models.ActionPurged, activity, snapshot(activity))
+		})
+		if err != nil {
+			return purged, err
+		}
+		purged += rows
+	}
+
+	return purged, nil
 }
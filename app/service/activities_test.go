@@ -0,0 +1,507 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/pointer"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/types"
+	"github.com/ungame/command-time-track/db"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type nopObserver struct{}
+
+func (nopObserver) Count(string, ...string)          {}
+func (nopObserver) DurationOf(string, time.Duration) {}
+
+func newTestActivitiesService(t *testing.T) (ActivitiesService, repository.ActivitiesRepository) {
+	t.Helper()
+	repo := repository.NewMemoryActivitiesRepository()
+	activitiesService := NewActivitiesService(repository.NewMemoryTransactor(), repo, repository.NewMemoryActivityEventsRepository(), repository.NewMemoryProjectsRepository(), repository.NewMemoryClientsRepository(), nopObserver{})
+	return activitiesService, repo
+}
+
+func TestCreateManualActivity(t *testing.T) {
+	var (
+		ctx                     = context.Background()
+		activitiesService, repo = newTestActivitiesService(t)
+		now                     = time.Now().UTC().Truncate(time.Second)
+		startedAt, finishedAt   = now.Add(-time.Hour * 3), now.Add(-time.Hour * 2)
+		format                  = func(t time.Time) string { return t.Format(time.RFC3339) }
+	)
+
+	running, err := activitiesService.StartActivity(ctx, &types.StartActivityInput{Category: "running"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manual, err := activitiesService.CreateManualActivity(ctx, &types.StartActivityInput{
+		Category:   "manual",
+		StartedAt:  format(startedAt),
+		FinishedAt: format(finishedAt),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manual.Status != models.StatusFinished.String() || manual.Duration != 3600 {
+		t.Errorf("unexpected manual activity: %+v", manual)
+	}
+
+	if existing, _ := repo.Get(ctx, running.ID); existing.Status != models.StatusStarted {
+		t.Errorf("expected running activity to be left running, got %s", existing.Status)
+	}
+
+	invalid := []*types.StartActivityInput{
+		{StartedAt: format(startedAt)},
+		{FinishedAt: format(finishedAt)},
+		{StartedAt: format(finishedAt), FinishedAt: format(startedAt)},
+		{StartedAt: format(startedAt), FinishedAt: format(now.Add(time.Hour))},
+		{StartedAt: "yesterday", FinishedAt: format(finishedAt)},
+	}
+	for _, input := range invalid {
+		if _, err = activitiesService.CreateManualActivity(ctx, input); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected invalid input for %+v, got %v", input, err)
+		}
+	}
+
+	if _, err = activitiesService.StartActivity(ctx, &types.StartActivityInput{StartedAt: format(now.Add(time.Hour))}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected invalid input for start in the future, got %v", err)
+	}
+
+	if _, err = activitiesService.StartActivity(ctx, &types.StartActivityInput{StartedAt: format(finishedAt)}); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected conflict for start in the past while running, got %v", err)
+	}
+
+	if _, err = activitiesService.DeleteActivityByID(ctx, &types.DeleteActivityInput{ID: running.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	past, err := activitiesService.StartActivity(ctx, &types.StartActivityInput{Category: "past", StartedAt: format(finishedAt)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if past.Status != models.StatusStarted.String() || past.Duration < 2*3600 {
+		t.Errorf("unexpected activity started in the past: %+v", past)
+	}
+}
+
+func TestActivityOverlaps(t *testing.T) {
+	var (
+		ctx                  = context.Background()
+		activitiesService, _ = newTestActivitiesService(t)
+		base                 = time.Now().UTC().Truncate(time.Hour).Add(-time.Hour * 10)
+		at                   = func(minutes int) string { return base.Add(time.Minute * time.Duration(minutes)).Format(time.RFC3339) }
+	)
+
+	create := func(from, to int, resolve string) (*types.ActivityOutput, error) {
+		return activitiesService.CreateManualActivity(ctx, &types.StartActivityInput{
+			Category:   "overlap",
+			StartedAt:  at(from),
+			FinishedAt: at(to),
+			Resolve:    resolve,
+		})
+	}
+	duration := func(id int64) int64 {
+		output, err := activitiesService.GetActivityByID(ctx, &types.GetActivityInput{ID: id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return output.Duration
+	}
+
+	first, err := create(0, 120, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = create(60, 180, "")
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || len(conflict.IDs) != 1 || conflict.IDs[0] != first.ID {
+		t.Fatalf("expected conflict with activity %d, got %v", first.ID, err)
+	}
+
+	if _, err = create(60, 180, "merge"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected invalid resolve, got %v", err)
+	}
+
+	second, err := create(60, 180, ResolveTrim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := duration(first.ID); d != 3600 {
+		t.Errorf("expected first activity trimmed to 1h, got %ds", d)
+	}
+
+	if _, err = create(15, 30, ResolveSplit); err != nil {
+		t.Fatal(err)
+	}
+	if d := duration(first.ID); d != 15*60 {
+		t.Errorf("expected first activity split to 15m, got %ds", d)
+	}
+	list, err := activitiesService.ListActivities(ctx, &types.ListActivitiesInput{Category: "overlap"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	split := list.Activities[2]
+	if len(list.Activities) != 4 || split.Duration != 30*60 {
+		t.Fatalf("expected split activity of 30m, got %+v", split)
+	}
+
+	if _, err = create(35, 45, ResolveTrim); err != nil {
+		t.Fatal(err)
+	}
+	if d := duration(split.ID); d != 20*60 {
+		t.Errorf("expected split activity trimmed to 20m, got %ds", d)
+	}
+
+	if _, err = create(10, 50, ResolveTrim); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected conflict for activities covered entirely, got %v", err)
+	}
+
+	if _, err = activitiesService.StartActivity(ctx, &types.StartActivityInput{Category: "overlap", StartedAt: at(150), Resolve: ResolveTrim}); err != nil {
+		t.Fatal(err)
+	}
+	if d := duration(second.ID); d != 90*60 {
+		t.Errorf("expected second activity trimmed to 1h30m, got %ds", d)
+	}
+}
+
+func TestActivityOverlapsInvoiced(t *testing.T) {
+	var (
+		ctx                     = context.Background()
+		activitiesService, repo = newTestActivitiesService(t)
+		base                    = time.Now().UTC().Truncate(time.Hour).Add(-time.Hour * 10)
+		at                      = func(minutes int) string { return base.Add(time.Minute * time.Duration(minutes)).Format(time.RFC3339) }
+	)
+
+	invoiced, err := activitiesService.CreateManualActivity(ctx, &types.StartActivityInput{Category: "billed", StartedAt: at(0), FinishedAt: at(120)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = repo.SetInvoice(ctx, 1, []int64{invoiced.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, resolve := range []string{ResolveTrim, ResolveSplit} {
+		_, err = activitiesService.CreateManualActivity(ctx, &types.StartActivityInput{Category: "overlap", StartedAt: at(30), FinishedAt: at(60), Resolve: resolve})
+		var conflict *ConflictError
+		if !errors.As(err, &conflict) || len(conflict.IDs) != 1 || conflict.IDs[0] != invoiced.ID {
+			t.Errorf("expected %s to conflict with the invoiced activity %d, got %v", resolve, invoiced.ID, err)
+		}
+	}
+
+	output, err := activitiesService.GetActivityByID(ctx, &types.GetActivityInput{ID: invoiced.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output.Duration != 2*3600 {
+		t.Errorf("expected the invoiced activity unchanged, got %ds", output.Duration)
+	}
+	list, err := activitiesService.ListActivities(ctx, &types.ListActivitiesInput{})
+	if err != nil || len(list.Activities) != 1 {
+		t.Errorf("expected no activity created, got %+v, err=%v", list, err)
+	}
+}
+
+func TestActivityOverlapsSplitOwner(t *testing.T) {
+	var (
+		ctx                     = context.Background()
+		activitiesService, repo = newTestActivitiesService(t)
+		base                    = time.Now().UTC().Truncate(time.Hour).Add(-time.Hour * 10)
+		at                      = func(minutes int) time.Time { return base.Add(time.Minute * time.Duration(minutes)) }
+		aliceID                 = int64(2)
+	)
+
+	// an activity of another user, split by a request without owner scope like the admin tasks
+	alice := &models.Activity{Category: "alice", Status: models.StatusFinished, StartedAt: at(0), UpdatedAt: at(120), FinishedAt: pointer.New(at(120)),
+		Intervals: []*models.Interval{{StartedAt: at(0), FinishedAt: pointer.New(at(120))}}}
+	if _, err := repo.Create(repository.WithOwner(ctx, aliceID), alice); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := activitiesService.CreateManualActivity(ctx, &types.StartActivityInput{Category: "overlap", StartedAt: at(30).Format(time.RFC3339), FinishedAt: at(60).Format(time.RFC3339), Resolve: ResolveSplit})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	owned, err := repo.GetAll(repository.WithOwner(ctx, aliceID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(owned) != 2 || !owned[1].StartedAt.Equal(at(60)) {
+		t.Fatalf("expected both parts of the split activity kept by its owner, got %+v", owned)
+	}
+}
+
+func TestStartActivityStopsRunning(t *testing.T) {
+	var (
+		ctx                     = context.Background()
+		activitiesService, repo = newTestActivitiesService(t)
+		startedAt               = time.Now().UTC().Truncate(time.Second).Add(-time.Hour)
+	)
+
+	first, err := activitiesService.StartActivity(ctx, &types.StartActivityInput{Category: "first", StartedAt: startedAt.Format(time.RFC3339)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Stopped) != 0 {
+		t.Errorf("expected no activity stopped, got %+v", first.Stopped)
+	}
+
+	second, err := activitiesService.StartActivity(ctx, &types.StartActivityInput{Category: "second"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second.Stopped) != 1 || second.Stopped[0].ID != first.ID {
+		t.Fatalf("expected activity %d stopped, got %+v", first.ID, second.Stopped)
+	}
+	if stopped := second.Stopped[0]; stopped.Status != models.StatusFinished.String() || stopped.FinishedAt == nil || *stopped.FinishedAt != second.StartedAt {
+		t.Errorf("expected activity stopped at %s, got %+v", second.StartedAt, stopped)
+	}
+
+	started, err := repo.GetByStatus(ctx, models.StatusStarted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(started) != 1 || started[0].ID != second.ID {
+		t.Errorf("expected only activity %d running, got %d activities", second.ID, len(started))
+	}
+}
+
+func TestStartActivityConcurrently(t *testing.T) {
+	const starts = 10
+
+	conn := db.NewSQLite(filepath.Join(t.TempDir(), "activities.db"))
+	t.Cleanup(func() { _ = conn.Close() })
+	db.MustMigrate(conn, db.DialectSQLite)
+
+	var (
+		ctx               = context.Background()
+		repo              = repository.NewActivitiesRepository(ctx, conn)
+		activitiesService = NewActivitiesService(repository.NewTransactor(conn), repo, repository.NewActivityEventsRepository(conn), repository.NewProjectsRepository(conn), repository.NewClientsRepository(conn), nopObserver{})
+		wg                sync.WaitGroup
+	)
+	for i := 0; i < starts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// a start waiting for a later one overlaps it once running, it fails with a conflict
+			if _, err := activitiesService.StartActivity(ctx, &types.StartActivityInput{Category: "dev", Description: fmt.Sprintf("start %d", i)}); err != nil && !errors.Is(err, ErrConflict) {
+				t.Errorf("unexpected error on concurrent start: %s", err.Error())
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	started, err := repo.GetByStatus(ctx, models.StatusStarted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(started) != 1 {
+		t.Errorf("expected a single running activity after concurrent starts, got %d", len(started))
+	}
+}
+
+func TestStartActivityStopsRunningOfSameUser(t *testing.T) {
+	var (
+		activitiesService, repo = newTestActivitiesService(t)
+		alice                   = repository.WithOwner(context.Background(), 2)
+		bob                     = repository.WithOwner(context.Background(), 3)
+	)
+
+	first, err := activitiesService.StartActivity(alice, &types.StartActivityInput{Category: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := activitiesService.StartActivity(bob, &types.StartActivityInput{Category: "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second.Stopped) != 0 {
+		t.Errorf("expected no activity stopped, got %+v", second.Stopped)
+	}
+
+	if _, err = activitiesService.GetActivityByID(bob, &types.GetActivityInput{ID: first.ID}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected activity %d not found for another user, got %v", first.ID, err)
+	}
+
+	started, err := repo.GetByStatus(repository.AllOwners(context.Background()), models.StatusStarted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(started) != 2 {
+		t.Errorf("expected 2 activities running, got %d", len(started))
+	}
+}
+
+func TestActivityTags(t *testing.T) {
+	var (
+		ctx                  = context.Background()
+		activitiesService, _ = newTestActivitiesService(t)
+		base                 = time.Now().UTC().Truncate(time.Hour).Add(-time.Hour * 10)
+		at                   = func(hours int) string { return base.Add(time.Hour * time.Duration(hours)).Format(time.RFC3339) }
+	)
+
+	meeting, err := activitiesService.CreateManualActivity(ctx, &types.StartActivityInput{
+		Category:   "work",
+		StartedAt:  at(0),
+		FinishedAt: at(3),
+		Tags:       []string{"Meeting", "billable", "meeting"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(meeting.Tags) != "[billable meeting]" {
+		t.Errorf("unexpected tags: %v", meeting.Tags)
+	}
+
+	if _, err = activitiesService.StartActivity(ctx, &types.StartActivityInput{Category: "work", Tags: []string{"a,b"}}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected invalid tag, got %v", err)
+	}
+
+	oncall, err := activitiesService.StartActivity(ctx, &types.StartActivityInput{Category: "work", StartedAt: at(5)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tagged, err := activitiesService.AddActivityTags(ctx, &types.TagActivityInput{ID: oncall.ID, Tags: []string{"oncall", "PROJ-1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(tagged.Tags) != "[oncall proj-1]" {
+		t.Errorf("unexpected added tags: %v", tagged.Tags)
+	}
+	if _, err = activitiesService.AddActivityTags(ctx, &types.TagActivityInput{ID: oncall.ID}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected tags required, got %v", err)
+	}
+
+	untagged, err := activitiesService.RemoveActivityTags(ctx, &types.TagActivityInput{ID: oncall.ID, Tags: []string{"proj-1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(untagged.Tags) != "[oncall]" {
+		t.Errorf("unexpected removed tags: %v", untagged.Tags)
+	}
+
+	list, err := activitiesService.ListActivities(ctx, &types.ListActivitiesInput{Tags: []string{"BILLABLE"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Activities) != 1 || list.Activities[0].ID != meeting.ID {
+		t.Errorf("unexpected activities tagged billable: %+v", list.Activities)
+	}
+
+	found, err := activitiesService.SearchActivities(ctx, &types.SearchActivitiesInput{Term: "work", Tags: []string{"oncall"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].ID != oncall.ID {
+		t.Errorf("unexpected activities found tagged oncall: %+v", found)
+	}
+
+	if _, err = activitiesService.CreateManualActivity(ctx, &types.StartActivityInput{Category: "work", StartedAt: at(1), FinishedAt: at(2), Resolve: ResolveSplit}); err != nil {
+		t.Fatal(err)
+	}
+	list, err = activitiesService.ListActivities(ctx, &types.ListActivitiesInput{Tags: []string{"meeting"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Activities) != 2 {
+		t.Errorf("expected split activity to keep tags, got %+v", list.Activities)
+	}
+}
+
+func TestActivityTrash(t *testing.T) {
+	var (
+		ctx                     = context.Background()
+		activitiesService, repo = newTestActivitiesService(t)
+		listed                  = func() []int64 {
+			t.Helper()
+			output, err := activitiesService.ListActivities(ctx, &types.ListActivitiesInput{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids := make([]int64, 0, len(output.Activities))
+			for _, activity := range output.Activities {
+				ids = append(ids, activity.ID)
+			}
+			return ids
+		}
+	)
+
+	kept, err := activitiesService.StartActivity(ctx, &types.StartActivityInput{Category: "kept"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleted, err := activitiesService.StartActivity(ctx, &types.StartActivityInput{Category: "deleted"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = activitiesService.DeleteActivityByID(ctx, &types.DeleteActivityInput{ID: deleted.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	if ids := listed(); fmt.Sprint(ids) != fmt.Sprint([]int64{kept.ID}) {
+		t.Errorf("expected deleted activity hidden, got %v", ids)
+	}
+	trash, err := activitiesService.ListTrash(ctx, &types.ListTrashInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash) != 1 || trash[0].ID != deleted.ID || trash[0].DeletedAt == nil {
+		t.Fatalf("expected deleted activity in the trash, got %+v", trash)
+	}
+
+	stranger := WithIdentity(ctx, &Identity{UserID: 3, Role: models.RoleMember})
+	if _, err = activitiesService.RestoreActivity(stranger, &types.RestoreActivityInput{ID: deleted.ID}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected restore of another user forbidden, got %v", err)
+	}
+
+	restored, err := activitiesService.RestoreActivity(ctx, &types.RestoreActivityInput{ID: deleted.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.DeletedAt != nil || restored.Category != "deleted" {
+		t.Errorf("unexpected restored activity: %+v", restored)
+	}
+	if ids := listed(); len(ids) != 2 {
+		t.Errorf("expected restored activity listed, got %v", ids)
+	}
+	if _, err = activitiesService.RestoreActivity(ctx, &types.RestoreActivityInput{ID: kept.ID}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected restore of activity out of the trash not found, got %v", err)
+	}
+
+	if _, err = activitiesService.DeleteActivityByID(ctx, &types.DeleteActivityInput{ID: deleted.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if purged, err := activitiesService.PurgeTrash(ctx, time.Now().UTC().Add(-time.Hour)); err != nil || purged != 0 {
+		t.Errorf("expected recently deleted activity kept, got purged=%d, err=%v", purged, err)
+	}
+	if purged, err := activitiesService.PurgeTrash(ctx, time.Now().UTC().Add(time.Second)); err != nil || purged != 1 {
+		t.Errorf("expected deleted activity purged, got purged=%d, err=%v", purged, err)
+	}
+	if _, err = repo.GetDeleted(ctx, deleted.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected purged activity gone, got %v", err)
+	}
+
+	history, err := activitiesService.GetActivityHistory(ctx, &types.GetActivityInput{ID: deleted.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := make([]string, 0, len(history))
+	for _, event := range history {
+		actions = append(actions, event.Action)
+	}
+	expected := []string{models.ActionCreated, models.ActionDeleted, models.ActionRestored, models.ActionDeleted, models.ActionPurged}
+	if fmt.Sprint(actions) != fmt.Sprint(expected) {
+		t.Errorf("unexpected history: expected=%v, got=%v", expected, actions)
+	}
+}
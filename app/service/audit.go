@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/repository"
+	"time"
+)
+
+// audit appends the changes of the activities to their history, it must run in the transaction of the change.
+type audit struct {
+	events repository.ActivityEventsRepository
+}
+
+// snapshot returns the activity as JSON, the way the api outputs it.
+func snapshot(activity *models.Activity) *string {
+	data, err := json.Marshal(activity.Out())
+	if err != nil {
+		// the output holds no values json can't encode
+		panic(err)
+	}
+	value := string(data)
+	return &value
+}
+
+// actorOf returns the user making the request, the default user for internal calls.
+func actorOf(ctx context.Context) int64 {
+	if identity := identityFrom(ctx); identity != nil {
+		return identity.UserID
+	}
+	return userOf(ctx)
+}
+
+// record appends the action made on the activity, before is its snapshot taken ahead of the change.
+// Purged activities have no value after the change.
+func (a *audit) record(ctx context.Context, action string, activity *models.Activity, before *string) error {
+	event := &models.ActivityEvent{
+		ActivityID: activity.ID,
+		OwnerID:    activity.OwnerID,
+		ActorID:    actorOf(ctx),
+		Action:     action,
+		Before:     before,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if action != models.ActionPurged {
+		event.After = snapshot(activity)
+	}
+	_, err := a.events.Create(ctx, event)
+	return err
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/types"
+	"testing"
+)
+
+func TestActivityHistory(t *testing.T) {
+	var (
+		activitiesService, _ = newTestActivitiesService(t)
+		owner                = &Identity{UserID: 2, Role: models.RoleMember}
+		stranger             = &Identity{UserID: 3, Role: models.RoleMember}
+		admin                = &Identity{UserID: models.DefaultUserID, Role: models.RoleAdmin}
+		ctx                  = WithIdentity(context.Background(), owner)
+	)
+
+	first, err := activitiesService.StartActivity(ctx, &types.StartActivityInput{Category: "first"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = activitiesService.StartActivity(ctx, &types.StartActivityInput{Category: "second"}); err != nil {
+		t.Fatal(err)
+	}
+	adminCtx := WithIdentity(context.Background(), admin)
+	if _, err = activitiesService.UpdateActivityCategory(adminCtx, &types.UpdateActivityInput{ID: first.ID, Category: "renamed"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = activitiesService.DeleteActivityByID(ctx, &types.DeleteActivityInput{ID: first.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := activitiesService.GetActivityHistory(ctx, &types.GetActivityInput{ID: first.ID})
+	if err != nil {
+		t.Fatalf("unexpected error on get history of deleted activity: %s", err.Error())
+	}
+
+	expected := []struct {
+		action string
+		actor  int64
+	}{
+		{models.ActionCreated, owner.UserID},
+		{models.ActionStopped, owner.UserID},
+		{models.ActionCategoryUpdated, admin.UserID},
+		{models.ActionDeleted, owner.UserID},
+	}
+	if len(history) != len(expected) {
+		t.Fatalf("unexpected history length: expected=%d, got=%d", len(expected), len(history))
+	}
+	for i, event := range history {
+		if event.Action != expected[i].action || event.ActorID != expected[i].actor {
+			t.Errorf("unexpected event %d: expected=%v, got=%s by %d", i, expected[i], event.Action, event.ActorID)
+		}
+	}
+
+	category := func(value json.RawMessage) string {
+		t.Helper()
+		var activity *types.ActivityOutput
+		if err := json.Unmarshal(value, &activity); err != nil {
+			t.Fatalf("unexpected event value %s: %s", value, err.Error())
+		}
+		if activity == nil {
+			return ""
+		}
+		return activity.Category
+	}
+	if before, after := category(history[0].Before), category(history[0].After); before != "" || after != "first" {
+		t.Errorf("unexpected created values: before=%q, after=%q", before, after)
+	}
+	if before, after := category(history[2].Before), category(history[2].After); before != "first" || after != "renamed" {
+		t.Errorf("unexpected category_updated values: before=%q, after=%q", before, after)
+	}
+	if before, after := category(history[3].Before), category(history[3].After); before != "renamed" || after != "renamed" {
+		t.Errorf("unexpected deleted values: before=%q, after=%q", before, after)
+	}
+
+	_, err = activitiesService.GetActivityHistory(WithIdentity(context.Background(), stranger), &types.GetActivityInput{ID: first.ID})
+	if !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected history of another user forbidden, got %v", err)
+	}
+	if _, err = activitiesService.GetActivityHistory(ctx, &types.GetActivityInput{ID: first.ID + 100}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected history of missing activity not found, got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/repository"
+)
+
+// Reasons of ForbiddenError.
+const (
+	ReasonReadOnly      = "read_only_role"
+	ReasonNotOwner      = "not_activity_owner"
+	ReasonNotTeammate   = "not_teammate"
+	ReasonAdminRequired = "admin_required"
+)
+
+type permission int
+
+const (
+	// permRead reads activities, of the user or of their teammates.
+	permRead permission = iota
+	// permWrite changes activities of the user.
+	permWrite
+	// permManage changes users, teams, clients, projects and invoices.
+	permManage
+)
+
+// Identity is the user making a request, with the users sharing a team with them.
+type Identity struct {
+	UserID    int64
+	Role      models.Role
+	Teammates map[int64]bool
+}
+
+type identityKey struct{}
+
+// WithIdentity makes the services called with the returned context act as the identity,
+// scoping the activities repositories to the user. Contexts without identity are not restricted.
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	ctx = repository.WithOwner(ctx, identity.UserID)
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// WithoutAdmin lowers the identity of the context to a member when it is an admin, for requests that name no user
+// and so prove nothing about who makes them.
+func WithoutAdmin(ctx context.Context) context.Context {
+	identity := identityFrom(ctx)
+	if identity == nil || identity.Role != models.RoleAdmin {
+		return ctx
+	}
+	member := *identity
+	member.Role = models.RoleMember
+	return context.WithValue(ctx, identityKey{}, &member)
+}
+
+func identityFrom(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityKey{}).(*Identity)
+	return identity
+}
+
+// can checks the permission of the identity on the activities of the user, admins can do everything.
+func (i *Identity) can(p permission, userID int64) error {
+	switch {
+	case i.Role == models.RoleAdmin:
+		return nil
+	case p == permManage:
+		return &ForbiddenError{Reason: ReasonAdminRequired}
+	case p == permWrite && i.Role != models.RoleMember:
+		return &ForbiddenError{Reason: ReasonReadOnly}
+	case userID == i.UserID:
+		return nil
+	case p == permWrite:
+		return &ForbiddenError{Reason: ReasonNotOwner}
+	case i.Teammates[userID]:
+		return nil
+	default:
+		return &ForbiddenError{Reason: ReasonNotTeammate}
+	}
+}
+
+// authorize checks the permission of the request on the activities of its own user, or on shared resources.
+func authorize(ctx context.Context, p permission) error {
+	identity := identityFrom(ctx)
+	if identity == nil {
+		return nil
+	}
+	return identity.can(p, identity.UserID)
+}
+
+// scopeTo scopes the context to the activities of the user, once checked the request can read them,
+// zero keeps the user of the request.
+func scopeTo(ctx context.Context, userID int64) (context.Context, error) {
+	if userID == 0 {
+		return ctx, authorize(ctx, permRead)
+	}
+	if identity := identityFrom(ctx); identity != nil {
+		if err := identity.can(permRead, userID); err != nil {
+			return nil, err
+		}
+	}
+	return repository.WithOwner(ctx, userID), nil
+}
+
+// accessActivity scopes the context to the owner of the activity, once checked the request has the permission
+// on it. Missing activities fail with ErrNotFound naming the activity.
+func accessActivity(ctx context.Context, activitiesRepository repository.ActivitiesRepository, id int64, p permission) (context.Context, error) {
+	ctx, err := access(ctx, activitiesRepository.Get, id, p)
+	return ctx, notFound(err, "activity", id)
+}
+
+// accessDeletedActivity is accessActivity for the activities in the trash.
+func accessDeletedActivity(ctx context.Context, activitiesRepository repository.ActivitiesRepository, id int64, p permission) (context.Context, error) {
+	return access(ctx, activitiesRepository.GetDeleted, id, p)
+}
+
+func access(ctx context.Context, get func(ctx context.Context, id int64) (*models.Activity, error), id int64, p permission) (context.Context, error) {
+	identity := identityFrom(ctx)
+	if identity == nil {
+		return ctx, nil
+	}
+	activity, err := get(repository.AllOwners(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+	if err = identity.can(p, activity.OwnerID); err != nil {
+		return nil, err
+	}
+	return repository.WithOwner(ctx, activity.OwnerID), nil
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/types"
+	"testing"
+)
+
+func assertForbidden(t *testing.T, err error, reason string) {
+	t.Helper()
+	var forbidden *ForbiddenError
+	if !errors.As(err, &forbidden) || !errors.Is(err, ErrForbidden) || forbidden.Reason != reason {
+		t.Errorf("expected forbidden with reason %s, got %v", reason, err)
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	var (
+		background        = context.Background()
+		usersRepo         = repository.NewMemoryUsersRepository()
+		teamsRepo         = repository.NewMemoryTeamsRepository()
+		usersService      = NewUsersService(usersRepo, teamsRepo)
+		teamsService      = NewTeamsService(teamsRepo, usersRepo)
+		activitiesService = NewActivitiesService(repository.NewMemoryTransactor(), repository.NewMemoryActivitiesRepository(), repository.NewMemoryActivityEventsRepository(), repository.NewMemoryProjectsRepository(), repository.NewMemoryClientsRepository(), nopObserver{})
+		projectsService   = NewProjectsService(repository.NewMemoryClientsRepository(), repository.NewMemoryProjectsRepository(), repository.NewMemoryActivitiesRepository())
+	)
+
+	as := func(userID int64) context.Context {
+		t.Helper()
+		ctx, err := usersService.Identify(background, userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ctx
+	}
+	admin := as(models.DefaultUserID)
+
+	create := func(name, role string) int64 {
+		t.Helper()
+		user, err := usersService.CreateUser(admin, &types.UserInput{Name: name, Role: role})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return user.ID
+	}
+	alice, bob, carol := create("alice", ""), create("bob", "member"), create("carol", "viewer")
+
+	team, err := teamsService.CreateTeam(admin, &types.TeamInput{Name: "dev"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, userID := range []int64{alice, carol} {
+		if _, err = teamsService.AddTeamMember(admin, &types.TeamMemberInput{TeamID: team.ID, UserID: userID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err = usersService.CreateUser(as(alice), &types.UserInput{Name: "dave"})
+	assertForbidden(t, err, ReasonAdminRequired)
+	_, err = projectsService.CreateClient(as(alice), &types.ClientInput{Name: "acme"})
+	assertForbidden(t, err, ReasonAdminRequired)
+
+	activity, err := activitiesService.StartActivity(as(alice), &types.StartActivityInput{Category: "dev"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = activitiesService.StartActivity(as(carol), &types.StartActivityInput{Category: "dev"})
+	assertForbidden(t, err, ReasonReadOnly)
+
+	if _, err = activitiesService.GetActivityByID(as(carol), &types.GetActivityInput{ID: activity.ID}); err != nil {
+		t.Errorf("expected teammate to read the activity, got %v", err)
+	}
+	listed, err := activitiesService.ListActivities(as(carol), &types.ListActivitiesInput{UserID: alice})
+	if err != nil || len(listed.Activities) != 1 || listed.Activities[0].ID != activity.ID {
+		t.Errorf("expected teammate to list the activity, got %+v, err=%v", listed, err)
+	}
+	_, err = activitiesService.StopActivity(as(carol), &types.UpdateActivityInput{ID: activity.ID})
+	assertForbidden(t, err, ReasonReadOnly)
+
+	_, err = activitiesService.GetActivityByID(as(bob), &types.GetActivityInput{ID: activity.ID})
+	assertForbidden(t, err, ReasonNotTeammate)
+	_, err = activitiesService.ListActivities(as(bob), &types.ListActivitiesInput{UserID: alice})
+	assertForbidden(t, err, ReasonNotTeammate)
+	_, err = activitiesService.DeleteActivityByID(as(bob), &types.DeleteActivityInput{ID: activity.ID})
+	assertForbidden(t, err, ReasonNotOwner)
+
+	if _, err = activitiesService.StopActivity(admin, &types.UpdateActivityInput{ID: activity.ID}); err != nil {
+		t.Errorf("expected admin to stop the activity, got %v", err)
+	}
+	if _, err = activitiesService.DeleteActivityByID(admin, &types.DeleteActivityInput{ID: activity.ID}); err != nil {
+		t.Errorf("expected admin to delete the activity, got %v", err)
+	}
+
+	if _, err = usersService.UpdateUserRole(admin, &types.UserRoleInput{ID: models.DefaultUserID, Role: "member"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected the last admin to keep the role, got %v", err)
+	}
+	if _, err = usersService.UpdateUserRole(admin, &types.UserRoleInput{ID: bob, Role: "admin"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = usersService.UpdateUserRole(as(bob), &types.UserRoleInput{ID: models.DefaultUserID, Role: "viewer"}); err != nil {
+		t.Errorf("expected another admin to change the role, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/pointer"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/types"
+	"time"
+)
+
+// billing resolves the hourly rate of activities: their own rate, otherwise the rate of their project,
+// otherwise the rate of the project client. Projects and clients are loaded once, a billing serves one call.
+type billing struct {
+	projectsRepository repository.ProjectsRepository
+	clientsRepository  repository.ClientsRepository
+	projects           map[int64]*models.Project
+	clients            map[int64]*models.Client
+}
+
+func newBilling(projectsRepository repository.ProjectsRepository, clientsRepository repository.ClientsRepository) *billing {
+	return &billing{
+		projectsRepository: projectsRepository,
+		clientsRepository:  clientsRepository,
+		projects:           make(map[int64]*models.Project),
+		clients:            make(map[int64]*models.Client),
+	}
+}
+
+func (b *billing) project(ctx context.Context, id int64) (*models.Project, error) {
+	if project, ok := b.projects[id]; ok {
+		return project, nil
+	}
+	project, err := b.projectsRepository.Get(ctx, id)
+	if err != nil {
+		return nil, notFound(err, "project", id)
+	}
+	b.projects[id] = project
+	return project, nil
+}
+
+func (b *billing) client(ctx context.Context, id int64) (*models.Client, error) {
+	if client, ok := b.clients[id]; ok {
+		return client, nil
+	}
+	client, err := b.clientsRepository.Get(ctx, id)
+	if err != nil {
+		return nil, notFound(err, "client", id)
+	}
+	b.clients[id] = client
+	return client, nil
+}
+
+// rate returns the hourly rate of the activity, nil when neither the activity, its project nor its client have one.
+func (b *billing) rate(ctx context.Context, activity *models.Activity) (*models.Cents, error) {
+	if activity.HourlyRate != nil || activity.ProjectID == nil {
+		return activity.HourlyRate, nil
+	}
+	project, err := b.project(ctx, *activity.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if project.HourlyRate != nil {
+		return project.HourlyRate, nil
+	}
+	client, err := b.client(ctx, project.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	return client.HourlyRate, nil
+}
+
+// out returns the activity output with the resolved hourly rate and the amount billed for its duration.
+func (b *billing) out(ctx context.Context, activity *models.Activity) (*types.ActivityOutput, error) {
+	rate, err := b.rate(ctx, activity)
+	if err != nil {
+		return nil, err
+	}
+	out := activity.Out()
+	if rate != nil {
+		out.HourlyRate = pointer.New(rate.Float())
+		out.BillableAmount = pointer.New(rate.Bill(activity.Duration(time.Now().UTC())).Float())
+	}
+	return out, nil
+}
+
+// export returns the export output with the resolved hourly rate and the amount billed at now.
+func (b *billing) export(ctx context.Context, activity *models.Activity, now time.Time) (*types.ExportActivityOutput, error) {
+	rate, err := b.rate(ctx, activity)
+	if err != nil {
+		return nil, err
+	}
+	out := activity.Export(now)
+	if rate != nil {
+		out.HourlyRate = pointer.New(rate.Float())
+		out.BillableAmount = pointer.New(rate.Bill(activity.Duration(now).Round(time.Second)).Float())
+	}
+	return out, nil
+}
+
+// hourlyRate converts an optional hourly rate input to cents.
+func hourlyRate(rate *float64) (*models.Cents, error) {
+	if rate == nil {
+		return nil, nil
+	}
+	cents, err := models.CentsOf(*rate)
+	if err != nil {
+		return nil, invalidInput(err)
+	}
+	return &cents, nil
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/ungame/command-time-track/app/apperr"
+	"strconv"
+	"strings"
+)
+
+// The kinds of the errors of the services, the errors of the repositories match them too.
+var (
+	ErrInvalidInput = apperr.ErrValidation
+	ErrConflict     = apperr.ErrConflict
+	ErrNotFound     = apperr.ErrNotFound
+	ErrForbidden    = apperr.ErrForbidden
+)
+
+func invalidInput(err error) error {
+	return apperr.Invalid("", err)
+}
+
+// invalidField is invalidInput detailing the invalid field.
+func invalidField(field string, err error) error {
+	return apperr.Invalid(field, err)
+}
+
+// conflict returns a conflict with the state of an entity, like pausing a finished activity.
+func conflict(format string, args ...any) error {
+	return apperr.Errorf(apperr.CodeConflict, format, args...)
+}
+
+// notFound replaces the not found errors of the repositories with one naming what is missing.
+func notFound(err error, what string, id int64) error {
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, ErrNotFound) {
+		return apperr.Errorf(apperr.CodeNotFound, "%s %d", what, id)
+	}
+	return err
+}
+
+// ConflictError lists the activities preventing a change, it matches ErrConflict.
+type ConflictError struct {
+	Reason string
+	IDs    []int64
+}
+
+func (e *ConflictError) Error() string {
+	ids := make([]string, 0, len(e.IDs))
+	for _, id := range e.IDs {
+		ids = append(ids, strconv.FormatInt(id, 10))
+	}
+	return fmt.Sprintf("%s: %s: %s", ErrConflict, e.Reason, strings.Join(ids, ", "))
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// ForbiddenError tells why the user of a request is not allowed to do it, it matches ErrForbidden.
+type ForbiddenError struct {
+	Reason string
+}
+
+func (e *ForbiddenError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrForbidden, e.Reason)
+}
+
+func (e *ForbiddenError) Is(target error) bool {
+	return target == ErrForbidden
+}
+
+// ErrorReason returns the machine readable reason, written along the error message.
+func (e *ForbiddenError) ErrorReason() string {
+	return e.Reason
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ungame/command-time-track/app/importer"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/pointer"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/types"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ImportService interface {
+	ImportActivities(ctx context.Context, input *types.ImportActivitiesInput, r io.Reader) (*types.ImportActivitiesOutput, error)
+}
+
+type importService struct {
+	transactor           repository.Transactor
+	activitiesRepository repository.ActivitiesRepository
+	audit                *audit
+	location             *time.Location
+}
+
+func NewImportService(
+	transactor repository.Transactor,
+	activitiesRepository repository.ActivitiesRepository,
+	activityEventsRepository repository.ActivityEventsRepository,
+	location *time.Location,
+) ImportService {
+	return &importService{
+		transactor:           transactor,
+		activitiesRepository: activitiesRepository,
+		audit:                &audit{events: activityEventsRepository},
+		location:             location,
+	}
+}
+
+func (s *importService) ImportActivities(ctx context.Context, input *types.ImportActivitiesInput, r io.Reader) (*types.ImportActivitiesOutput, error) {
+	if err := authorize(ctx, permWrite); err != nil {
+		return nil, err
+	}
+
+	loc := s.location
+	if input.TimeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(input.TimeZone); err != nil {
+			return nil, invalidField("tz", fmt.Errorf("invalid time zone: %s", input.TimeZone))
+		}
+	}
+
+	if err := validResolve(input.Resolve); err != nil {
+		return nil, err
+	}
+
+	result, err := importer.ReadCSV(r, loc, input.DateFormat)
+	if errors.Is(err, importer.ErrDateFormat) || errors.Is(err, importer.ErrAmbiguousDates) || errors.Is(err, importer.ErrMixedDates) {
+		return nil, invalidField("date_format", err)
+	}
+	if err != nil {
+		return nil, invalidInput(err)
+	}
+
+	output := &types.ImportActivitiesOutput{
+		Source:     result.Source,
+		DryRun:     input.DryRun,
+		IDs:        make([]int64, 0, len(result.Records)),
+		Duplicates: make([]*types.ImportRowOutput, 0),
+		Rejected:   make([]*types.ImportRowOutput, 0, len(result.Rejected)),
+	}
+
+	for _, rejected := range result.Rejected {
+		output.Rejected = append(output.Rejected, &types.ImportRowOutput{Row: rejected.Row, Reason: rejected.Err.Error()})
+	}
+
+	var (
+		seen     = make(map[string]int, len(result.Records))
+		accepted = make([]*acceptedRow, 0)
+		now      = time.Now().UTC()
+	)
+
+	for _, record := range result.Records {
+		if record.FinishedAt.After(now) {
+			output.Rejected = append(output.Rejected, &types.ImportRowOutput{Row: record.Row, Reason: "activity finishes in the future"})
+			continue
+		}
+
+		key := fmt.Sprintf("%s\x00%s\x00%d\x00%d", record.Category, record.Description, record.StartedAt.Unix(), record.FinishedAt.Unix())
+		if row, ok := seen[key]; ok {
+			output.Duplicates = append(output.Duplicates, &types.ImportRowOutput{Row: record.Row, Reason: fmt.Sprintf("same as row %d", row)})
+			continue
+		}
+		seen[key] = record.Row
+
+		existing, err := s.findImported(ctx, record)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			output.Duplicates = append(output.Duplicates, &types.ImportRowOutput{Row: record.Row, Reason: "activity already exists", ActivityID: existing.ID})
+			continue
+		}
+
+		activity := &models.Activity{
+			Category:    record.Category,
+			Description: record.Description,
+			Status:      models.StatusFinished,
+			StartedAt:   record.StartedAt,
+			UpdatedAt:   now,
+			FinishedAt:  pointer.New(record.FinishedAt),
+			Intervals:   []*models.Interval{{StartedAt: record.StartedAt, FinishedAt: pointer.New(record.FinishedAt)}},
+		}
+
+		if input.DryRun {
+			err = s.checkRow(ctx, accepted, activity, input.Resolve)
+		} else {
+			err = s.importRow(ctx, activity, input.Resolve, now)
+		}
+		if errors.Is(err, ErrConflict) {
+			output.Rejected = append(output.Rejected, &types.ImportRowOutput{Row: record.Row, Reason: err.Error()})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		output.Imported++
+		if input.DryRun {
+			accepted = append(accepted, &acceptedRow{row: record.Row, activity: activity})
+			continue
+		}
+		output.IDs = append(output.IDs, activity.ID)
+	}
+
+	log.Printf("Activities imported from %s: imported=%d, duplicates=%d, rejected=%d, dry_run=%v\n",
+		output.Source, output.Imported, len(output.Duplicates), len(output.Rejected), output.DryRun)
+
+	return output, nil
+}
+
+// importRow creates the activity of a row, checking its overlaps in the same transaction so no activity
+// written meanwhile can overlap it.
+func (s *importService) importRow(ctx context.Context, activity *models.Activity, resolve string, now time.Time) error {
+	return s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		overlaps, err := checkOverlaps(ctx, s.activitiesRepository, activity, resolve, nil)
+		if err != nil {
+			return err
+		}
+		if err = resolveOverlaps(ctx, s.activitiesRepository, s.audit, activity, resolve, overlaps, now); err != nil {
+			return err
+		}
+		if _, err = s.activitiesRepository.Create(ctx, activity); err != nil {
+			return err
+		}
+		return s.audit.record(ctx, models.ActionCreated, activity, nil)
+	})
+}
+
+// acceptedRow is a row a dry run would import, kept in memory as nothing is written.
+type acceptedRow struct {
+	row      int
+	activity *models.Activity
+}
+
+// checkRow checks the overlaps of the activity of a row as importRow does, with the rows accepted before it too.
+// Resolving them gives the accepted rows the time left, as the import would.
+func (s *importService) checkRow(ctx context.Context, accepted []*acceptedRow, activity *models.Activity, resolve string) error {
+	if _, err := checkOverlaps(ctx, s.activitiesRepository, activity, resolve, nil); err != nil {
+		return err
+	}
+
+	var (
+		periods       = periodsOf(activity)
+		overlaps      []*overlap
+		rows, covered []string
+	)
+	for _, a := range accepted {
+		o := overlapOf(a.activity, periods, nil)
+		if o == nil {
+			continue
+		}
+		overlaps = append(overlaps, o)
+		rows = append(rows, strconv.Itoa(a.row))
+		if len(o.rest) == 0 {
+			covered = append(covered, strconv.Itoa(a.row))
+		}
+	}
+
+	switch {
+	case len(overlaps) == 0:
+		return nil
+	case resolve == "":
+		return conflict("activity overlaps rows: %s", strings.Join(rows, ", "))
+	case len(covered) > 0:
+		return conflict("activity covers rows entirely, unable to %s: %s", resolve, strings.Join(covered, ", "))
+	}
+	for _, o := range overlaps {
+		o.activity.Intervals = o.rest
+	}
+	return nil
+}
+
+// findImported returns the activity with the same category, description and times of the record, if any.
+func (s *importService) findImported(ctx context.Context, record *importer.Record) (*models.Activity, error) {
+	var (
+		from     = record.StartedAt
+		to       = record.StartedAt.Add(time.Second)
+		finished = models.StatusFinished
+	)
+	candidates, err := s.activitiesRepository.Find(ctx, &repository.ActivitiesQuery{
+		From:     &from,
+		To:       &to,
+		Category: record.Category,
+		Status:   &finished,
+		Limit:    MaxLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range candidates {
+		if candidate.StartedAt.Equal(record.StartedAt) &&
+			candidate.FinishedAt != nil && candidate.FinishedAt.Equal(record.FinishedAt) &&
+			candidate.Description == record.Description {
+			return candidate, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/types"
+	"strings"
+	"testing"
+	"time"
+)
+
+const togglImport = "User,Email,Client,Project,Task,Description,Billable,Start date,Start time,End date,End time,Duration,Tags,Amount ()\n" +
+	"Ana,ana@example.com,,Backend,,Review,No,2023-01-15,09:00:00,2023-01-15,10:30:00,01:30:00,,\n" +
+	"Ana,ana@example.com,,Backend,,Review,No,2023-01-15,09:00:00,2023-01-15,10:30:00,01:30:00,,\n" +
+	"Ana,ana@example.com,,Backend,,Deploy,No,2023-01-15,11:00:00,2023-01-15,11:30:00,00:30:00,,\n" +
+	"Ana,ana@example.com,,Backend,,Broken,No,2023-01-15,,2023-01-15,11:30:00,00:30:00,,\n"
+
+func TestImportActivities(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		repo    = repository.NewMemoryActivitiesRepository()
+		imports = NewImportService(repository.NewMemoryTransactor(), repo, repository.NewMemoryActivityEventsRepository(), time.UTC)
+	)
+
+	output, err := imports.ImportActivities(ctx, &types.ImportActivitiesInput{DryRun: true}, strings.NewReader(togglImport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output.Imported != 2 || len(output.IDs) != 0 || len(output.Duplicates) != 1 || len(output.Rejected) != 1 {
+		t.Fatalf("unexpected dry run output: %+v", output)
+	}
+	if activities, _ := repo.GetAll(ctx); len(activities) != 0 {
+		t.Fatalf("expected no activities after dry run, got %d", len(activities))
+	}
+
+	output, err = imports.ImportActivities(ctx, &types.ImportActivitiesInput{}, strings.NewReader(togglImport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output.Source != "toggl" || output.Imported != 2 || len(output.IDs) != 2 {
+		t.Fatalf("unexpected import output: %+v", output)
+	}
+
+	activity, err := repo.Get(ctx, output.IDs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if activity.FinishedAt == nil || activity.Duration(time.Now()) != time.Minute*90 || activity.Category != "Backend" {
+		t.Errorf("unexpected imported activity: %+v", activity)
+	}
+
+	output, err = imports.ImportActivities(ctx, &types.ImportActivitiesInput{}, strings.NewReader(togglImport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output.Imported != 0 || len(output.Duplicates) != 3 || output.Duplicates[2].ActivityID == 0 {
+		t.Errorf("expected every row to be a duplicate on second import: %+v", output)
+	}
+
+	if _, err = imports.ImportActivities(ctx, &types.ImportActivitiesInput{}, strings.NewReader("a,b\n")); err == nil {
+		t.Error("expected error for unsupported csv")
+	}
+}
+
+func TestImportActivitiesOverlappingRows(t *testing.T) {
+	const overlapping = "User,Email,Client,Project,Task,Description,Billable,Start date,Start time,End date,End time,Duration,Tags,Amount ()\n" +
+		"Ana,ana@example.com,,Backend,,Review,No,2023-01-15,09:00:00,2023-01-15,10:30:00,01:30:00,,\n" +
+		"Ana,ana@example.com,,Backend,,Deploy,No,2023-01-15,10:00:00,2023-01-15,11:00:00,01:00:00,,\n"
+
+	var (
+		ctx     = context.Background()
+		repo    = repository.NewMemoryActivitiesRepository()
+		imports = NewImportService(repository.NewMemoryTransactor(), repo, repository.NewMemoryActivityEventsRepository(), time.UTC)
+	)
+
+	dryRun, err := imports.ImportActivities(ctx, &types.ImportActivitiesInput{DryRun: true}, strings.NewReader(overlapping))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dryRun.Imported != 1 || len(dryRun.Rejected) != 1 || dryRun.Rejected[0].Row != 3 || !strings.Contains(dryRun.Rejected[0].Reason, "rows: 2") {
+		t.Fatalf("expected dry run to reject the row overlapping row 2, got %+v", dryRun)
+	}
+
+	output, err := imports.ImportActivities(ctx, &types.ImportActivitiesInput{}, strings.NewReader(overlapping))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output.Imported != dryRun.Imported || len(output.Rejected) != len(dryRun.Rejected) || output.Rejected[0].Row != 3 {
+		t.Errorf("expected import to match the dry run %+v, got %+v", dryRun, output)
+	}
+
+	imports = NewImportService(repository.NewMemoryTransactor(), repository.NewMemoryActivitiesRepository(), repository.NewMemoryActivityEventsRepository(), time.UTC)
+	resolved, err := imports.ImportActivities(ctx, &types.ImportActivitiesInput{DryRun: true, Resolve: ResolveTrim}, strings.NewReader(overlapping))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.Imported != 2 || len(resolved.Rejected) != 0 {
+		t.Errorf("expected the overlapping rows resolved in dry run, got %+v", resolved)
+	}
+}
+
+func TestImportActivitiesDates(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		repo     = repository.NewMemoryActivitiesRepository()
+		imports  = NewImportService(repository.NewMemoryTransactor(), repo, repository.NewMemoryActivityEventsRepository(), time.UTC)
+		tomorrow = time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+		header   = "User,Email,Client,Project,Task,Description,Billable,Start date,Start time,End date,End time,Duration,Tags,Amount ()\n"
+		past     = "Ana,ana@example.com,,Backend,,Review,No,03/04/2023,09:00:00,03/04/2023,10:00:00,01:00:00,,\n"
+		future   = "Ana,ana@example.com,,Backend,,Planning,No,2023-01-15,09:00:00," + tomorrow + ",10:00:00,01:00:00,,\n"
+	)
+
+	if _, err := imports.ImportActivities(ctx, &types.ImportActivitiesInput{}, strings.NewReader(header+past)); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected ambiguous dates rejected, got %v", err)
+	}
+
+	output, err := imports.ImportActivities(ctx, &types.ImportActivitiesInput{DateFormat: "dd/mm/yyyy"}, strings.NewReader(header+past+future))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output.Imported != 1 || len(output.Rejected) != 1 || output.Rejected[0].Row != 3 || output.Rejected[0].Reason != "activity finishes in the future" {
+		t.Fatalf("expected activity finishing in the future rejected, got %+v", output)
+	}
+	activity, err := repo.Get(ctx, output.IDs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if activity.StartedAt.Month() != time.April || activity.StartedAt.Day() != 3 {
+		t.Errorf("expected dates read day first, got %s", activity.StartedAt)
+	}
+}
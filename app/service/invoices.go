@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/types"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxInvoiceRounding is the largest rounding of invoice durations, in minutes.
+const MaxInvoiceRounding = 60
+
+type InvoicesService interface {
+	CreateInvoice(ctx context.Context, input *types.CreateInvoiceInput) (*types.InvoiceOutput, error)
+	GetInvoiceByID(ctx context.Context, id int64) (*types.InvoiceOutput, error)
+	ListInvoices(ctx context.Context) ([]*types.InvoiceOutput, error)
+}
+
+type invoicesService struct {
+	transactor           repository.Transactor
+	activitiesRepository repository.ActivitiesRepository
+	invoicesRepository   repository.InvoicesRepository
+	projectsRepository   repository.ProjectsRepository
+	clientsRepository    repository.ClientsRepository
+	audit                *audit
+	location             *time.Location
+}
+
+func NewInvoicesService(
+	transactor repository.Transactor,
+	activitiesRepository repository.ActivitiesRepository,
+	activityEventsRepository repository.ActivityEventsRepository,
+	invoicesRepository repository.InvoicesRepository,
+	projectsRepository repository.ProjectsRepository,
+	clientsRepository repository.ClientsRepository,
+	location *time.Location,
+) InvoicesService {
+	return &invoicesService{
+		transactor:           transactor,
+		activitiesRepository: activitiesRepository,
+		invoicesRepository:   invoicesRepository,
+		projectsRepository:   projectsRepository,
+		clientsRepository:    clientsRepository,
+		audit:                &audit{events: activityEventsRepository},
+		location:             location,
+	}
+}
+
+// CreateInvoice bills the finished activities of every user not invoiced yet that started in the invoice period,
+// of the categories and of the projects of the client when given, and marks them as invoiced in the same transaction.
+func (s *invoicesService) CreateInvoice(ctx context.Context, input *types.CreateInvoiceInput) (*types.InvoiceOutput, error) {
+	if err := authorize(ctx, permManage); err != nil {
+		return nil, err
+	}
+
+	ctx = repository.AllOwners(ctx)
+
+	var (
+		loc = s.location
+		err error
+	)
+
+	if input.TimeZone != "" {
+		if loc, err = time.LoadLocation(input.TimeZone); err != nil {
+			return nil, invalidField("tz", fmt.Errorf("invalid time zone: %s", input.TimeZone))
+		}
+	}
+
+	if input.From == "" || input.To == "" {
+		return nil, invalidInput(errors.New("from and to are required"))
+	}
+	from, err := parseTimeIn(input.From, loc)
+	if err != nil {
+		return nil, invalidInput(err)
+	}
+	to, err := parseTimeIn(input.To, loc)
+	if err != nil {
+		return nil, invalidInput(err)
+	}
+	if !from.Before(*to) {
+		return nil, invalidInput(errors.New("invalid time range: from must be before to"))
+	}
+
+	rate, err := models.CentsOf(input.HourlyRate)
+	if err != nil || rate == 0 {
+		return nil, invalidField("hourly_rate", fmt.Errorf("invalid hourly rate: %v", input.HourlyRate))
+	}
+
+	if input.Rounding < 0 || input.Rounding > MaxInvoiceRounding || (input.Rounding > 0 && MaxInvoiceRounding%input.Rounding != 0) {
+		return nil, invalidField("rounding", fmt.Errorf("invalid rounding: %d, must be 0 or divide %d minutes", input.Rounding, MaxInvoiceRounding))
+	}
+
+	categories := make(map[string]bool, len(input.Categories))
+	for _, category := range input.Categories {
+		if category = strings.TrimSpace(category); category != "" {
+			categories[category] = true
+		}
+	}
+	if len(categories) == 0 && strings.TrimSpace(input.Client) == "" {
+		return nil, invalidInput(errors.New("categories or client is required"))
+	}
+
+	invoice := &models.Invoice{
+		From:       *from,
+		To:         *to,
+		HourlyRate: rate,
+		Rounding:   time.Duration(input.Rounding) * time.Minute,
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	var projects map[int64]bool
+	if strings.TrimSpace(input.Client) != "" {
+		if invoice.Client, projects, err = s.clientProjects(ctx, input.Client); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		finished = models.StatusFinished
+		invoiced = false
+		query    = repository.ActivitiesQuery{From: from, To: to, Status: &finished, Invoiced: &invoiced}
+		ids      []int64
+		billed   []*models.Activity
+	)
+
+	err = s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		err := repository.Each(ctx, s.activitiesRepository, query, func(activity *models.Activity) error {
+			if activity.StartedAt.Before(*from) || !activity.StartedAt.Before(*to) {
+				return nil
+			}
+			if len(categories) > 0 && !categories[activity.Category] {
+				return nil
+			}
+			if projects != nil && (activity.ProjectID == nil || !projects[*activity.ProjectID]) {
+				return nil
+			}
+			invoice.Add(activity.Category, activity.Duration(invoice.CreatedAt))
+			ids = append(ids, activity.ID)
+			billed = append(billed, activity)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return invalidInput(errors.New("no activities to invoice"))
+		}
+
+		sort.SliceStable(invoice.Items, func(i, j int) bool {
+			return invoice.Items[i].Category < invoice.Items[j].Category
+		})
+		if _, err = s.invoicesRepository.Create(ctx, invoice); err != nil {
+			return err
+		}
+
+		rows, err := s.activitiesRepository.SetInvoice(ctx, invoice.ID, ids)
+		if err != nil {
+			return err
+		}
+		if rows != int64(len(ids)) {
+			return &ConflictError{Reason: "activities were invoiced meanwhile", IDs: ids}
+		}
+		for _, activity := range billed {
+			before := snapshot(activity)
+			activity.InvoiceID = &invoice.ID
+			if err = s.audit.record(ctx, models.ActionInvoiced, activity, before); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Invoice created: ID=%v, activities=%d, total=%.2f\n", invoice.ID, len(ids), invoice.Total.Float())
+
+	return invoice.Out(), nil
+}
+
+// clientProjects returns the name of the client and the ids of its projects, clients are matched by name ignoring case.
+func (s *invoicesService) clientProjects(ctx context.Context, name string) (string, map[int64]bool, error) {
+	clients, err := s.clientsRepository.GetAll(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, client := range clients {
+		if !strings.EqualFold(client.Name, strings.TrimSpace(name)) {
+			continue
+		}
+		projects, err := s.projectsRepository.GetByClient(ctx, client.ID)
+		if err != nil {
+			return "", nil, err
+		}
+		ids := make(map[int64]bool, len(projects))
+		for _, project := range projects {
+			ids[project.ID] = true
+		}
+		return client.Name, ids, nil
+	}
+	return "", nil, invalidField("client", fmt.Errorf("client not found: %s", name))
+}
+
+func (s *invoicesService) GetInvoiceByID(ctx context.Context, id int64) (*types.InvoiceOutput, error) {
+	if err := authorize(ctx, permManage); err != nil {
+		return nil, err
+	}
+	invoice, err := s.invoicesRepository.Get(ctx, id)
+	if err != nil {
+		return nil, notFound(err, "invoice", id)
+	}
+	return invoice.Out(), nil
+}
+
+func (s *invoicesService) ListInvoices(ctx context.Context) ([]*types.InvoiceOutput, error) {
+	if err := authorize(ctx, permManage); err != nil {
+		return nil, err
+	}
+	invoices, err := s.invoicesRepository.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	output := make([]*types.InvoiceOutput, 0, len(invoices))
+	for _, invoice := range invoices {
+		output = append(output, invoice.Out())
+	}
+	return output, nil
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/types"
+	"testing"
+	"time"
+)
+
+func TestCreateInvoice(t *testing.T) {
+	var (
+		ctx               = context.Background()
+		transactor        = repository.NewMemoryTransactor()
+		activitiesRepo    = repository.NewMemoryActivitiesRepository()
+		eventsRepo        = repository.NewMemoryActivityEventsRepository()
+		projectsRepo      = repository.NewMemoryProjectsRepository()
+		clientsRepo       = repository.NewMemoryClientsRepository()
+		activitiesService = NewActivitiesService(transactor, activitiesRepo, eventsRepo, projectsRepo, clientsRepo, nopObserver{})
+		projectsService   = NewProjectsService(clientsRepo, projectsRepo, activitiesRepo)
+		invoicesService   = NewInvoicesService(transactor, activitiesRepo, eventsRepo, repository.NewMemoryInvoicesRepository(), projectsRepo, clientsRepo, time.UTC)
+		base              = time.Now().UTC().Truncate(time.Hour).Add(-time.Hour * 24)
+		at                = func(minutes int) string { return base.Add(time.Minute * time.Duration(minutes)).Format(time.RFC3339) }
+	)
+
+	manual := func(category string, from, to int, projectID *int64) int64 {
+		t.Helper()
+		output, err := activitiesService.CreateManualActivity(ctx, &types.StartActivityInput{
+			Category:   category,
+			StartedAt:  at(from),
+			FinishedAt: at(to),
+			ProjectID:  projectID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return output.ID
+	}
+
+	client, err := projectsService.CreateClient(ctx, &types.ClientInput{Name: "Acme"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	project, err := projectsService.CreateProject(ctx, &types.ProjectInput{ClientID: client.ID, Name: "Website"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manual("dev", 0, 52, nil)
+	manual("dev", 60, 68, nil)
+	manual("meeting", 120, 140, nil)
+	manual("other", 180, 240, nil)
+	acme := manual("support", 300, 330, &project.ID)
+
+	if _, err = activitiesService.StartActivity(ctx, &types.StartActivityInput{Category: "dev", StartedAt: at(400)}); err != nil {
+		t.Fatal(err)
+	}
+
+	input := &types.CreateInvoiceInput{
+		Categories: []string{"dev", "meeting"},
+		From:       at(0),
+		To:         at(600),
+		HourlyRate: 100,
+		Rounding:   15,
+	}
+
+	invoice, err := invoicesService.CreateInvoice(ctx, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(invoice.Items) != 2 || invoice.Items[0].Category != "dev" || invoice.Items[0].Activities != 2 || invoice.Items[1].Category != "meeting" {
+		t.Fatalf("unexpected invoice items: %+v", invoice.Items)
+	}
+	// 52 minutes round to 45 and 8 minutes to 15, 20 minutes of meeting round to 15
+	if invoice.Items[0].Duration != 3600 || invoice.Items[0].Amount != 100 || invoice.Items[1].Amount != 25 || invoice.Total != 125 {
+		t.Errorf("unexpected invoice amounts: items=%+v, total=%v", invoice.Items, invoice.Total)
+	}
+
+	if _, err = invoicesService.CreateInvoice(ctx, input); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected invoiced activities not to be billed twice, got %v", err)
+	}
+
+	byClient, err := invoicesService.CreateInvoice(ctx, &types.CreateInvoiceInput{Client: "acme", From: at(0), To: at(600), HourlyRate: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if byClient.Client != "Acme" || len(byClient.Items) != 1 || byClient.Items[0].Category != "support" || byClient.Total != 30 {
+		t.Errorf("unexpected invoice of client: %+v", byClient)
+	}
+
+	activity, err := activitiesService.GetActivityByID(ctx, &types.GetActivityInput{ID: acme})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if activity.InvoiceID == nil || *activity.InvoiceID != byClient.ID {
+		t.Errorf("expected activity marked as invoiced, got %v", activity.InvoiceID)
+	}
+
+	for _, invalid := range []*types.CreateInvoiceInput{
+		{From: at(0), To: at(600), HourlyRate: 100},
+		{Categories: []string{"other"}, From: at(0), To: at(600), HourlyRate: 100, Rounding: 7},
+		{Categories: []string{"other"}, From: at(0), To: at(600)},
+		{Categories: []string{"other"}, From: at(600), To: at(0), HourlyRate: 100},
+		{Client: "unknown", From: at(0), To: at(600), HourlyRate: 100},
+	} {
+		if _, err = invoicesService.CreateInvoice(ctx, invalid); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected invalid input %+v, got %v", invalid, err)
+		}
+	}
+
+	invoices, err := invoicesService.ListInvoices(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(invoices) != 2 {
+		t.Errorf("unexpected invoices: %d", len(invoices))
+	}
+	if _, err = invoicesService.GetInvoiceByID(ctx, 42); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected invoice not found, got %v", err)
+	}
+}
+
+func TestCreateInvoiceOfMembers(t *testing.T) {
+	var (
+		background        = context.Background()
+		transactor        = repository.NewMemoryTransactor()
+		activitiesRepo    = repository.NewMemoryActivitiesRepository()
+		eventsRepo        = repository.NewMemoryActivityEventsRepository()
+		projectsRepo      = repository.NewMemoryProjectsRepository()
+		clientsRepo       = repository.NewMemoryClientsRepository()
+		usersService      = NewUsersService(repository.NewMemoryUsersRepository(), repository.NewMemoryTeamsRepository())
+		activitiesService = NewActivitiesService(transactor, activitiesRepo, eventsRepo, projectsRepo, clientsRepo, nopObserver{})
+		invoicesService   = NewInvoicesService(transactor, activitiesRepo, eventsRepo, repository.NewMemoryInvoicesRepository(), projectsRepo, clientsRepo, time.UTC)
+		base              = time.Now().UTC().Truncate(time.Hour).Add(-time.Hour * 24)
+		at                = func(minutes int) string { return base.Add(time.Minute * time.Duration(minutes)).Format(time.RFC3339) }
+	)
+
+	as := func(userID int64) context.Context {
+		t.Helper()
+		ctx, err := usersService.Identify(background, userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ctx
+	}
+	admin := as(models.DefaultUserID)
+
+	user, err := usersService.CreateUser(admin, &types.UserInput{Name: "alice", Role: "member"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	member := as(user.ID)
+
+	activity, err := activitiesService.CreateManualActivity(member, &types.StartActivityInput{Category: "dev", StartedAt: at(0), FinishedAt: at(60)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = activitiesService.CreateManualActivity(admin, &types.StartActivityInput{Category: "dev", StartedAt: at(60), FinishedAt: at(90)}); err != nil {
+		t.Fatal(err)
+	}
+
+	input := &types.CreateInvoiceInput{Categories: []string{"dev"}, From: at(0), To: at(600), HourlyRate: 100}
+
+	_, err = invoicesService.CreateInvoice(member, input)
+	assertForbidden(t, err, ReasonAdminRequired)
+
+	invoice, err := invoicesService.CreateInvoice(admin, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(invoice.Items) != 1 || invoice.Items[0].Activities != 2 || invoice.Total != 150 {
+		t.Errorf("expected the activities of every user invoiced, got %+v", invoice)
+	}
+
+	invoiced, err := activitiesService.GetActivityByID(member, &types.GetActivityInput{ID: activity.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invoiced.InvoiceID == nil || *invoiced.InvoiceID != invoice.ID {
+		t.Errorf("expected the activity of the member marked as invoiced, got %v", invoiced.InvoiceID)
+	}
+
+	_, err = invoicesService.GetInvoiceByID(member, invoice.ID)
+	assertForbidden(t, err, ReasonAdminRequired)
+	_, err = invoicesService.ListInvoices(member)
+	assertForbidden(t, err, ReasonAdminRequired)
+	if invoices, err := invoicesService.ListInvoices(admin); err != nil || len(invoices) != 1 {
+		t.Errorf("expected admin to list the invoice, got %d, err=%v", len(invoices), err)
+	}
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/pointer"
+	"github.com/ungame/command-time-track/app/repository"
+	"log"
+	"time"
+)
+
+const (
+	ResolveTrim  = "trim"
+	ResolveSplit = "split"
+)
+
+// endOfTime stands for the end of open intervals, which run until they are paused or finished.
+var endOfTime = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+type period struct {
+	from, to time.Time
+}
+
+func (p period) overlaps(o period) bool {
+	return p.from.Before(o.to) && o.from.Before(p.to)
+}
+
+// subtract returns what is left of p after removing o, nothing when o covers p.
+func (p period) subtract(o period) []period {
+	if !p.overlaps(o) {
+		return []period{p}
+	}
+	var rest []period
+	if p.from.Before(o.from) {
+		rest = append(rest, period{p.from, o.from})
+	}
+	if o.to.Before(p.to) {
+		rest = append(rest, period{o.to, p.to})
+	}
+	return rest
+}
+
+func intervalPeriod(interval *models.Interval) period {
+	if interval.FinishedAt == nil {
+		return period{interval.StartedAt, endOfTime}
+	}
+	return period{interval.StartedAt, *interval.FinishedAt}
+}
+
+// intervalsOf returns the activity intervals, activities recorded before intervals existed
+// get one interval for the whole period between start and finish.
+func intervalsOf(activity *models.Activity) []*models.Interval {
+	if len(activity.Intervals) > 0 {
+		return activity.Intervals
+	}
+	return []*models.Interval{{ActivityID: activity.ID, StartedAt: activity.StartedAt, FinishedAt: activity.FinishedAt}}
+}
+
+func validResolve(resolve string) error {
+	switch resolve {
+	case "", ResolveTrim, ResolveSplit:
+		return nil
+	default:
+		return invalidInput(fmt.Errorf("invalid resolve: %s, expected %s or %s", resolve, ResolveTrim, ResolveSplit))
+	}
+}
+
+// overlap is an existing activity running at the same time of another one, along with
+// the intervals left when the time of the other activity is removed from it.
+type overlap struct {
+	activity *models.Activity
+	rest     []*models.Interval
+}
+
+func periodsOf(activity *models.Activity) []period {
+	periods := make([]period, 0, len(activity.Intervals))
+	for _, interval := range intervalsOf(activity) {
+		periods = append(periods, intervalPeriod(interval))
+	}
+	return periods
+}
+
+// findOverlaps returns the activities running at the same time of the given activity. Started activities
+// about to be stopped are taken as finishing at stopAt, when given.
+func findOverlaps(ctx context.Context, repo repository.ActivitiesRepository, activity *models.Activity, stopAt *time.Time) ([]*overlap, error) {
+	periods := periodsOf(activity)
+
+	query := repository.ActivitiesQuery{From: &periods[0].from}
+	if to := periods[len(periods)-1].to; to != endOfTime {
+		query.To = &to
+	}
+
+	overlaps := make([]*overlap, 0)
+
+	err := repository.Each(ctx, repo, query, func(existing *models.Activity) error {
+		if existing.ID == activity.ID {
+			return nil
+		}
+		if o := overlapOf(existing, periods, stopAt); o != nil {
+			overlaps = append(overlaps, o)
+		}
+		return nil
+	})
+
+	return overlaps, err
+}
+
+// overlapOf returns the overlap of the existing activity with the periods, nil when it runs at other times.
+func overlapOf(existing *models.Activity, periods []period, stopAt *time.Time) *overlap {
+	var (
+		found bool
+		rest  []*models.Interval
+	)
+
+	for _, interval := range intervalsOf(existing) {
+		p := intervalPeriod(interval)
+		if stopAt != nil && existing.Status == models.StatusStarted && interval.FinishedAt == nil {
+			if !interval.StartedAt.Before(*stopAt) {
+				// can't stop before it starts, the interval is covered by the new activity
+				found = true
+				continue
+			}
+			p.to = *stopAt
+		}
+		pieces := []period{p}
+		for _, o := range periods {
+			var next []period
+			for _, piece := range pieces {
+				if piece.overlaps(o) {
+					found = true
+				}
+				next = append(next, piece.subtract(o)...)
+			}
+			pieces = next
+		}
+		for i, piece := range pieces {
+			kept := &models.Interval{ActivityID: existing.ID, StartedAt: piece.from}
+			if i == 0 {
+				kept.ID = interval.ID
+			}
+			if piece.to != endOfTime {
+				kept.FinishedAt = pointer.New(piece.to)
+			}
+			rest = append(rest, kept)
+		}
+	}
+
+	if !found {
+		return nil
+	}
+	return &overlap{activity: existing, rest: rest}
+}
+
+func overlapIDs(overlaps []*overlap) []int64 {
+	ids := make([]int64, 0, len(overlaps))
+	for _, o := range overlaps {
+		ids = append(ids, o.activity.ID)
+	}
+	return ids
+}
+
+// checkOverlaps fails with a conflict when the activity overlaps others, unless resolve is given and every
+// overlapping activity keeps some of its time once the time of the activity is removed from it.
+// Invoiced activities are never trimmed or split, the hours of issued invoices must not change.
+func checkOverlaps(ctx context.Context, repo repository.ActivitiesRepository, activity *models.Activity, resolve string, stopAt *time.Time) ([]*overlap, error) {
+	overlaps, err := findOverlaps(ctx, repo, activity, stopAt)
+	if err != nil {
+		return nil, err
+	}
+	if err = resolvable(overlaps, resolve); err != nil {
+		return nil, err
+	}
+	return overlaps, nil
+}
+
+// resolvable fails with the conflict of the overlaps, nil when there are none or resolve can give them the time left.
+func resolvable(overlaps []*overlap, resolve string) error {
+	if len(overlaps) == 0 {
+		return nil
+	}
+	if resolve == "" {
+		return &ConflictError{Reason: "activity overlaps other activities", IDs: overlapIDs(overlaps)}
+	}
+	var invoiced, covered []*overlap
+	for _, o := range overlaps {
+		if o.activity.InvoiceID != nil {
+			invoiced = append(invoiced, o)
+		}
+		if len(o.rest) == 0 {
+			covered = append(covered, o)
+		}
+	}
+	if len(invoiced) > 0 {
+		return &ConflictError{Reason: "activity overlaps invoiced activities, unable to " + resolve, IDs: overlapIDs(invoiced)}
+	}
+	if len(covered) > 0 {
+		return &ConflictError{Reason: "activity covers other activities entirely, unable to " + resolve, IDs: overlapIDs(covered)}
+	}
+	return nil
+}
+
+// resolveOverlaps gives the overlapping activities the time left to them. Trim keeps every piece in the
+// same activity, split moves the pieces after the start of the given activity to a new activity.
+// Every change is recorded by the audit, it must run in a transaction.
+func resolveOverlaps(ctx context.Context, repo repository.ActivitiesRepository, audit *audit, activity *models.Activity, resolve string, overlaps []*overlap, now time.Time) error {
+	startedAt := activity.StartedAt
+
+	for _, o := range overlaps {
+		var (
+			existing = o.activity
+			rest     = o.rest
+			before   = snapshot(existing)
+			action   = models.ActionTrimmed
+		)
+
+		if resolve == ResolveSplit {
+			var before, after []*models.Interval
+			for _, interval := range rest {
+				if interval.StartedAt.Before(startedAt) {
+					before = append(before, interval)
+				} else {
+					after = append(after, interval)
+				}
+			}
+			if len(before) > 0 && len(after) > 0 {
+				split := &models.Activity{
+					OwnerID:     existing.OwnerID,
+					Category:    existing.Category,
+					Description: existing.Description,
+					Status:      existing.Status,
+					Tags:        existing.Tags,
+					ProjectID:   existing.ProjectID,
+					HourlyRate:  existing.HourlyRate,
+					StartedAt:   after[0].StartedAt,
+					UpdatedAt:   now,
+					FinishedAt:  existing.FinishedAt,
+				}
+				for _, interval := range after {
+					split.Intervals = append(split.Intervals, &models.Interval{StartedAt: interval.StartedAt, FinishedAt: interval.FinishedAt})
+				}
+				reshape(split, now)
+				if _, err := repo.Create(ctx, split); err != nil {
+					return err
+				}
+				if err := audit.record(ctx, models.ActionSplit, split, nil); err != nil {
+					return err
+				}
+				log.Printf("Activity split: ID=%v, into ID=%v\n", existing.ID, split.ID)
+
+				action = models.ActionSplit
+
+				rest = before
+				if existing.Status == models.StatusPaused {
+					existing.Status = models.StatusFinished
+				}
+			}
+		}
+
+		existing.Intervals = rest
+		reshape(existing, now)
+
+		if _, err := repo.Update(ctx, existing); err != nil {
+			return err
+		}
+		if err := audit.record(ctx, action, existing, before); err != nil {
+			return err
+		}
+
+		log.Printf("Activity trimmed: ID=%v\n", existing.ID)
+	}
+
+	return nil
+}
+
+// reshape fits the start, finish and status of the activity to its intervals,
+// a started activity left without open interval is finished.
+func reshape(activity *models.Activity, now time.Time) {
+	first, last := activity.Intervals[0], activity.Intervals[len(activity.Intervals)-1]
+
+	activity.StartedAt = first.StartedAt
+	activity.UpdatedAt = now
+
+	if last.FinishedAt == nil {
+		return
+	}
+	if activity.Status == models.StatusStarted {
+		activity.Status = models.StatusFinished
+	}
+	if activity.Status == models.StatusFinished {
+		activity.FinishedAt = pointer.New(*last.FinishedAt)
+	}
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/pointer"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/types"
+	"log"
+	"strings"
+	"time"
+)
+
+const MaxNameLength = 100
+
+type ProjectsService interface {
+	CreateClient(ctx context.Context, input *types.ClientInput) (*types.ClientOutput, error)
+	UpdateClient(ctx context.Context, input *types.ClientInput) (*types.ClientOutput, error)
+	GetClientByID(ctx context.Context, id int64) (*types.ClientOutput, error)
+	ListClients(ctx context.Context) ([]*types.ClientOutput, error)
+	DeleteClientByID(ctx context.Context, id int64) error
+	CreateProject(ctx context.Context, input *types.ProjectInput) (*types.ProjectOutput, error)
+	UpdateProject(ctx context.Context, input *types.ProjectInput) (*types.ProjectOutput, error)
+	GetProjectByID(ctx context.Context, id int64) (*types.ProjectOutput, error)
+	ListProjects(ctx context.Context, input *types.ListProjectsInput) ([]*types.ProjectOutput, error)
+	DeleteProjectByID(ctx context.Context, id int64) error
+}
+
+type projectsService struct {
+	clientsRepository    repository.ClientsRepository
+	projectsRepository   repository.ProjectsRepository
+	activitiesRepository repository.ActivitiesRepository
+}
+
+func NewProjectsService(clientsRepository repository.ClientsRepository, projectsRepository repository.ProjectsRepository, activitiesRepository repository.ActivitiesRepository) ProjectsService {
+	return &projectsService{
+		clientsRepository:    clientsRepository,
+		projectsRepository:   projectsRepository,
+		activitiesRepository: activitiesRepository,
+	}
+}
+
+func validName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", invalidField("name", errors.New("name is required"))
+	}
+	if len([]rune(name)) > MaxNameLength {
+		return "", invalidField("name", fmt.Errorf("name is longer than %d characters", MaxNameLength))
+	}
+	return name, nil
+}
+
+func (s *projectsService) CreateClient(ctx context.Context, input *types.ClientInput) (*types.ClientOutput, error) {
+	if err := authorize(ctx, permManage); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	client := &models.Client{CreatedAt: now, UpdatedAt: now}
+	if err := s.setClient(ctx, client, input); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.clientsRepository.Create(ctx, client); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Client created: ID=%v\n", client.ID)
+
+	return client.Out(), nil
+}
+
+func (s *projectsService) UpdateClient(ctx context.Context, input *types.ClientInput) (*types.ClientOutput, error) {
+	if err := authorize(ctx, permManage); err != nil {
+		return nil, err
+	}
+	client, err := s.clientsRepository.Get(ctx, input.ID)
+	if err != nil {
+		return nil, notFound(err, "client", input.ID)
+	}
+	if err = s.setClient(ctx, client, input); err != nil {
+		return nil, err
+	}
+	client.UpdatedAt = time.Now().UTC()
+
+	if _, err = s.clientsRepository.Update(ctx, client); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Client updated: ID=%v\n", client.ID)
+
+	return client.Out(), nil
+}
+
+// setClient validates the input and sets it to the client, client names are unique.
+func (s *projectsService) setClient(ctx context.Context, client *models.Client, input *types.ClientInput) error {
+	name, err := validName(input.Name)
+	if err != nil {
+		return err
+	}
+	rate, err := hourlyRate(input.HourlyRate)
+	if err != nil {
+		return err
+	}
+
+	clients, err := s.clientsRepository.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, existing := range clients {
+		if existing.ID != client.ID && strings.EqualFold(existing.Name, name) {
+			return &ConflictError{Reason: "client name already exists", IDs: []int64{existing.ID}}
+		}
+	}
+
+	client.Name = name
+	client.HourlyRate = rate
+	return nil
+}
+
+func (s *projectsService) GetClientByID(ctx context.Context, id int64) (*types.ClientOutput, error) {
+	client, err := s.clientsRepository.Get(ctx, id)
+	if err != nil {
+		return nil, notFound(err, "client", id)
+	}
+	return client.Out(), nil
+}
+
+func (s *projectsService) ListClients(ctx context.Context) ([]*types.ClientOutput, error) {
+	clients, err := s.clientsRepository.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	output := make([]*types.ClientOutput, 0, len(clients))
+	for _, client := range clients {
+		output = append(output, client.Out())
+	}
+	return output, nil
+}
+
+// DeleteClientByID deletes a client without projects.
+func (s *projectsService) DeleteClientByID(ctx context.Context, id int64) error {
+	if err := authorize(ctx, permManage); err != nil {
+		return err
+	}
+	if _, err := s.clientsRepository.Get(ctx, id); err != nil {
+		return notFound(err, "client", id)
+	}
+
+	projects, err := s.projectsRepository.GetByClient(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(projects) > 0 {
+		ids := make([]int64, 0, len(projects))
+		for _, project := range projects {
+			ids = append(ids, project.ID)
+		}
+		return &ConflictError{Reason: "client has projects", IDs: ids}
+	}
+
+	if _, err = s.clientsRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	log.Printf("Client deleted: ID=%v\n", id)
+
+	return nil
+}
+
+func (s *projectsService) CreateProject(ctx context.Context, input *types.ProjectInput) (*types.ProjectOutput, error) {
+	if err := authorize(ctx, permManage); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	project := &models.Project{CreatedAt: now, UpdatedAt: now}
+	if err := s.setProject(ctx, project, input); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.projectsRepository.Create(ctx, project); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Project created: ID=%v, client ID=%v\n", project.ID, project.ClientID)
+
+	return project.Out(), nil
+}
+
+func (s *projectsService) UpdateProject(ctx context.Context, input *types.ProjectInput) (*types.ProjectOutput, error) {
+	if err := authorize(ctx, permManage); err != nil {
+		return nil, err
+	}
+	project, err := s.projectsRepository.Get(ctx, input.ID)
+	if err != nil {
+		return nil, notFound(err, "project", input.ID)
+	}
+	if err = s.setProject(ctx, project, input); err != nil {
+		return nil, err
+	}
+	project.UpdatedAt = time.Now().UTC()
+
+	if _, err = s.projectsRepository.Update(ctx, project); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Project updated: ID=%v\n", project.ID)
+
+	return project.Out(), nil
+}
+
+// setProject validates the input and sets it to the project, project names are unique by client.
+func (s *projectsService) setProject(ctx context.Context, project *models.Project, input *types.ProjectInput) error {
+	name, err := validName(input.Name)
+	if err != nil {
+		return err
+	}
+	rate, err := hourlyRate(input.HourlyRate)
+	if err != nil {
+		return err
+	}
+
+	if input.ClientID == 0 {
+		return invalidField("client_id", errors.New("client_id is required"))
+	}
+	if _, err = s.clientsRepository.Get(ctx, input.ClientID); err != nil {
+		if err = notFound(err, "client", input.ClientID); errors.Is(err, ErrNotFound) {
+			return invalidInput(err)
+		}
+		return err
+	}
+
+	projects, err := s.projectsRepository.GetByClient(ctx, input.ClientID)
+	if err != nil {
+		return err
+	}
+	for _, existing := range projects {
+		if existing.ID != project.ID && strings.EqualFold(existing.Name, name) {
+			return &ConflictError{Reason: "project name already exists for client", IDs: []int64{existing.ID}}
+		}
+	}
+
+	project.ClientID = input.ClientID
+	project.Name = name
+	project.HourlyRate = rate
+	return nil
+}
+
+func (s *projectsService) GetProjectByID(ctx context.Context, id int64) (*types.ProjectOutput, error) {
+	project, err := s.projectsRepository.Get(ctx, id)
+	if err != nil {
+		return nil, notFound(err, "project", id)
+	}
+	return project.Out(), nil
+}
+
+func (s *projectsService) ListProjects(ctx context.Context, input *types.ListProjectsInput) ([]*types.ProjectOutput, error) {
+	var (
+		projects []*models.Project
+		err      error
+	)
+	if input.ClientID != 0 {
+		projects, err = s.projectsRepository.GetByClient(ctx, input.ClientID)
+	} else {
+		projects, err = s.projectsRepository.GetAll(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	output := make([]*types.ProjectOutput, 0, len(projects))
+	for _, project := range projects {
+		output = append(output, project.Out())
+	}
+	return output, nil
+}
+
+// DeleteProjectByID deletes a project without activities.
+func (s *projectsService) DeleteProjectByID(ctx context.Context, id int64) error {
+	if err := authorize(ctx, permManage); err != nil {
+		return err
+	}
+	if _, err := s.projectsRepository.Get(ctx, id); err != nil {
+		return notFound(err, "project", id)
+	}
+
+	// projects are shared, the activities of every user keep the project
+	activities, err := s.activitiesRepository.Find(repository.AllOwners(ctx), &repository.ActivitiesQuery{ProjectID: pointer.New(id), Limit: DefaultLimit})
+	if err != nil {
+		return err
+	}
+	if len(activities) > 0 {
+		ids := make([]int64, 0, len(activities))
+		for _, activity := range activities {
+			ids = append(ids, activity.ID)
+		}
+		return &ConflictError{Reason: "project has activities", IDs: ids}
+	}
+
+	if _, err = s.projectsRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	log.Printf("Project deleted: ID=%v\n", id)
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/types"
+	"testing"
+	"time"
+)
+
+func TestProjectsBilling(t *testing.T) {
+	var (
+		ctx               = context.Background()
+		activitiesRepo    = repository.NewMemoryActivitiesRepository()
+		projectsRepo      = repository.NewMemoryProjectsRepository()
+		clientsRepo       = repository.NewMemoryClientsRepository()
+		projectsService   = NewProjectsService(clientsRepo, projectsRepo, activitiesRepo)
+		activitiesService = NewActivitiesService(repository.NewMemoryTransactor(), activitiesRepo, repository.NewMemoryActivityEventsRepository(), projectsRepo, clientsRepo, nopObserver{})
+		reportsService    = NewReportsService(activitiesRepo, projectsRepo, clientsRepo, time.UTC)
+		base              = time.Now().UTC().Truncate(time.Hour).Add(-time.Hour * 10)
+		at                = func(hours int) string { return base.Add(time.Hour * time.Duration(hours)).Format(time.RFC3339) }
+		rate              = func(amount float64) *float64 { return &amount }
+	)
+
+	client, err := projectsService.CreateClient(ctx, &types.ClientInput{Name: "Acme", HourlyRate: rate(100)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = projectsService.CreateClient(ctx, &types.ClientInput{Name: "acme"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected duplicated client name, got %v", err)
+	}
+
+	website, err := projectsService.CreateProject(ctx, &types.ProjectInput{ClientID: client.ID, Name: "Website"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	support, err := projectsService.CreateProject(ctx, &types.ProjectInput{ClientID: client.ID, Name: "Support", HourlyRate: rate(60)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = projectsService.CreateProject(ctx, &types.ProjectInput{ClientID: client.ID + 1, Name: "Website"}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected missing client, got %v", err)
+	}
+
+	clientRate, err := activitiesService.CreateManualActivity(ctx, &types.StartActivityInput{Category: "dev", StartedAt: at(0), FinishedAt: at(2), ProjectID: &website.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clientRate.BillableAmount == nil || *clientRate.BillableAmount != 200 || *clientRate.HourlyRate != 100 {
+		t.Errorf("expected client rate, got rate=%v, amount=%v", clientRate.HourlyRate, clientRate.BillableAmount)
+	}
+
+	projectRate, err := activitiesService.CreateManualActivity(ctx, &types.StartActivityInput{Category: "support", StartedAt: at(2), FinishedAt: at(3), ProjectID: &support.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if projectRate.BillableAmount == nil || *projectRate.BillableAmount != 60 {
+		t.Errorf("expected project rate, got amount=%v", projectRate.BillableAmount)
+	}
+
+	override, err := activitiesService.UpdateActivityRate(ctx, &types.UpdateActivityRateInput{ID: projectRate.ID, HourlyRate: rate(90.5)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if override.BillableAmount == nil || *override.BillableAmount != 90.5 {
+		t.Errorf("expected activity rate, got amount=%v", override.BillableAmount)
+	}
+
+	unbilled, err := activitiesService.CreateManualActivity(ctx, &types.StartActivityInput{Category: "dev", StartedAt: at(3), FinishedAt: at(4)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unbilled.HourlyRate != nil || unbilled.BillableAmount != nil {
+		t.Errorf("expected activity without project not billable, got amount=%v", unbilled.BillableAmount)
+	}
+
+	missing := support.ID + 100
+	if _, err = activitiesService.UpdateActivityProject(ctx, &types.UpdateActivityProjectInput{ID: unbilled.ID, ProjectID: &missing}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected missing project, got %v", err)
+	}
+
+	summary, err := reportsService.Summary(ctx, &types.SummaryReportInput{From: at(0), To: at(5), GroupBy: GroupByProject})
+	if err != nil {
+		t.Fatal(err)
+	}
+	amounts := make(map[string]float64)
+	for _, bucket := range summary.Buckets {
+		amounts[bucket.Key] = bucket.Amount
+	}
+	if summary.Amount != 290.5 || amounts["Website"] != 200 || amounts["Support"] != 90.5 || amounts[noneKey] != 0 {
+		t.Errorf("unexpected amounts by project: total=%v, buckets=%v", summary.Amount, amounts)
+	}
+
+	list, err := activitiesService.ListActivities(ctx, &types.ListActivitiesInput{ProjectID: website.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Activities) != 1 || list.Activities[0].ID != clientRate.ID {
+		t.Errorf("unexpected activities of project: %+v", list.Activities)
+	}
+
+	if err = projectsService.DeleteClientByID(ctx, client.ID); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected client with projects conflict, got %v", err)
+	}
+	if err = projectsService.DeleteProjectByID(ctx, website.ID); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected project with activities conflict, got %v", err)
+	}
+	if _, err = projectsService.GetProjectByID(ctx, missing); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected project not found, got %v", err)
+	}
+}
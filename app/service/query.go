@@ -0,0 +1,85 @@
+package service
+
+import (
+	"fmt"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/types"
+	"time"
+)
+
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+	dateLayout   = "2006-01-02"
+)
+
+func newActivitiesQuery(input *types.ListActivitiesInput) (*repository.ActivitiesQuery, error) {
+	query := &repository.ActivitiesQuery{
+		Category: input.Category,
+		Sort:     input.Sort,
+		Order:    input.Order,
+		Limit:    input.Limit,
+	}
+
+	if input.ProjectID != 0 {
+		query.ProjectID = &input.ProjectID
+	}
+
+	var err error
+
+	if query.Tags, err = models.ParseTags(input.Tags); err != nil {
+		return nil, invalidInput(err)
+	}
+	if query.From, err = parseTime(input.From); err != nil {
+		return nil, invalidInput(err)
+	}
+	if query.To, err = parseTime(input.To); err != nil {
+		return nil, invalidInput(err)
+	}
+
+	if input.Status != "" {
+		status, err := models.ParseStatus(input.Status)
+		if err != nil {
+			return nil, invalidInput(err)
+		}
+		query.Status = &status
+	}
+
+	switch {
+	case query.Limit == 0:
+		query.Limit = DefaultLimit
+	case query.Limit > MaxLimit:
+		return nil, invalidField("limit", fmt.Errorf("limit must be at most %d", MaxLimit))
+	}
+
+	if input.Cursor != "" {
+		if query.After, err = repository.DecodeCursor(input.Cursor); err != nil {
+			return nil, invalidInput(err)
+		}
+	}
+
+	if err = query.Validate(); err != nil {
+		return nil, invalidInput(err)
+	}
+
+	return query, nil
+}
+
+func parseTime(s string) (*time.Time, error) {
+	return parseTimeIn(s, time.UTC)
+}
+
+// parseTimeIn parses RFC 3339 times or dates, dates are midnight at the given location.
+func parseTimeIn(s string, loc *time.Location) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, dateLayout} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			t = t.UTC()
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid time %q, expected RFC 3339 or %s", s, dateLayout)
+}
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/types"
+	"sort"
+	"time"
+)
+
+const (
+	GroupByDay      = "day"
+	GroupByWeek     = "week"
+	GroupByCategory = "category"
+	GroupByProject  = "project"
+	GroupByClient   = "client"
+
+	// noneKey is the bucket key of the activities without a project (or client) when grouping by project or client.
+	noneKey = "none"
+
+	maxReportBuckets = 1000
+)
+
+type ReportsService interface {
+	Summary(ctx context.Context, input *types.SummaryReportInput) (*types.SummaryReportOutput, error)
+}
+
+type reportsService struct {
+	activitiesRepository repository.ActivitiesRepository
+	projectsRepository   repository.ProjectsRepository
+	clientsRepository    repository.ClientsRepository
+	location             *time.Location
+}
+
+func NewReportsService(
+	activitiesRepository repository.ActivitiesRepository,
+	projectsRepository repository.ProjectsRepository,
+	clientsRepository repository.ClientsRepository,
+	location *time.Location,
+) ReportsService {
+	return &reportsService{
+		activitiesRepository: activitiesRepository,
+		projectsRepository:   projectsRepository,
+		clientsRepository:    clientsRepository,
+		location:             location,
+	}
+}
+
+func (s *reportsService) Summary(ctx context.Context, input *types.SummaryReportInput) (*types.SummaryReportOutput, error) {
+	var (
+		now = time.Now().UTC()
+		loc = s.location
+		err error
+	)
+
+	if input.TimeZone != "" {
+		if loc, err = time.LoadLocation(input.TimeZone); err != nil {
+			return nil, invalidField("tz", fmt.Errorf("invalid time zone: %s", input.TimeZone))
+		}
+	}
+
+	if ctx, err = scopeTo(ctx, input.UserID); err != nil {
+		return nil, err
+	}
+
+	groupBy := input.GroupBy
+	switch groupBy {
+	case "":
+		groupBy = GroupByDay
+	case GroupByDay, GroupByWeek, GroupByCategory, GroupByProject, GroupByClient:
+	default:
+		return nil, invalidField("group_by", fmt.Errorf("invalid group by: %s", input.GroupBy))
+	}
+
+	to, err := parseTimeIn(input.To, loc)
+	if err != nil {
+		return nil, invalidInput(err)
+	}
+	if to == nil {
+		to = &now
+	}
+
+	from, err := parseTimeIn(input.From, loc)
+	if err != nil {
+		return nil, invalidInput(err)
+	}
+	if from == nil {
+		from = pointerTo(startOfDay(to.In(loc)).AddDate(0, 0, -6).UTC())
+	}
+
+	if !from.Before(*to) {
+		return nil, invalidInput(fmt.Errorf("invalid time range: from must be before to"))
+	}
+
+	summary, err := newSummary(groupBy, *from, *to, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := models.ParseTags(input.Tags)
+	if err != nil {
+		return nil, invalidInput(err)
+	}
+
+	query := repository.ActivitiesQuery{From: from, To: to, Tags: tags}
+
+	bill := newBilling(s.projectsRepository, s.clientsRepository)
+
+	err = repository.Each(ctx, s.activitiesRepository, query, func(activity *models.Activity) error {
+		// paused activities are not running, the time of their intervals is always counted
+		if !input.IncludeRunning && activity.Status == models.StatusStarted {
+			return nil
+		}
+		rate, err := bill.rate(ctx, activity)
+		if err != nil {
+			return err
+		}
+		key, err := groupKey(ctx, bill, groupBy, activity)
+		if err != nil {
+			return err
+		}
+		for _, span := range spansOf(activity, now) {
+			summary.add(activity, key, rate, span[0], span[1])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return summary.Out(), nil
+}
+
+// groupKey returns the bucket key of the activity when grouping by category, project or client.
+func groupKey(ctx context.Context, bill *billing, groupBy string, activity *models.Activity) (string, error) {
+	switch groupBy {
+	case GroupByCategory:
+		return activity.Category, nil
+	case GroupByProject, GroupByClient:
+		if activity.ProjectID == nil {
+			return noneKey, nil
+		}
+		project, err := bill.project(ctx, *activity.ProjectID)
+		if err != nil {
+			return "", err
+		}
+		if groupBy == GroupByProject {
+			return project.Name, nil
+		}
+		client, err := bill.client(ctx, project.ClientID)
+		if err != nil {
+			return "", err
+		}
+		return client.Name, nil
+	}
+	return "", nil
+}
+
+// spansOf returns the periods the activity was running, intervals when recorded,
+// otherwise the whole period between start and finish.
+func spansOf(activity *models.Activity, now time.Time) [][2]time.Time {
+	if len(activity.Intervals) == 0 {
+		finishedAt := now
+		if activity.FinishedAt != nil {
+			finishedAt = *activity.FinishedAt
+		}
+		return [][2]time.Time{{activity.StartedAt, finishedAt}}
+	}
+	spans := make([][2]time.Time, 0, len(activity.Intervals))
+	for _, interval := range activity.Intervals {
+		finishedAt := now
+		if interval.FinishedAt != nil {
+			finishedAt = *interval.FinishedAt
+		}
+		spans = append(spans, [2]time.Time{interval.StartedAt, finishedAt})
+	}
+	return spans
+}
+
+type bucket struct {
+	key        string
+	from, to   time.Time
+	duration   time.Duration
+	amount     models.Cents
+	activities map[int64]bool
+	categories map[string]time.Duration
+}
+
+func newBucket(key string, from, to time.Time) *bucket {
+	return &bucket{
+		key:        key,
+		from:       from,
+		to:         to,
+		activities: make(map[int64]bool),
+		categories: make(map[string]time.Duration),
+	}
+}
+
+func (b *bucket) add(activity *models.Activity, rate *models.Cents, duration time.Duration) {
+	b.duration += duration
+	if rate != nil {
+		b.amount += rate.Bill(duration)
+	}
+	b.activities[activity.ID] = true
+	b.categories[activity.Category] += duration
+}
+
+type summary struct {
+	groupBy  string
+	from, to time.Time
+	loc      *time.Location
+	total    time.Duration
+	amount   models.Cents
+	buckets  []*bucket
+	byKey    map[string]*bucket
+}
+
+func newSummary(groupBy string, from, to time.Time, loc *time.Location) (*summary, error) {
+	s := &summary{
+		groupBy: groupBy,
+		from:    from,
+		to:      to,
+		loc:     loc,
+		byKey:   make(map[string]*bucket),
+	}
+	if s.grouped() {
+		return s, nil
+	}
+	for start := s.bucketStart(from); start.Before(to); start = s.bucketEnd(start) {
+		if len(s.buckets) == maxReportBuckets {
+			return nil, invalidInput(fmt.Errorf("time range is too long, at most %d buckets are allowed", maxReportBuckets))
+		}
+		b := newBucket(start.Format(dateLayout), start, s.bucketEnd(start))
+		s.buckets = append(s.buckets, b)
+		s.byKey[b.key] = b
+	}
+	return s, nil
+}
+
+// grouped reports whether the buckets are keyed by the activities instead of time.
+func (s *summary) grouped() bool {
+	return s.groupBy == GroupByCategory || s.groupBy == GroupByProject || s.groupBy == GroupByClient
+}
+
+func (s *summary) bucketStart(t time.Time) time.Time {
+	day := startOfDay(t.In(s.loc))
+	if s.groupBy == GroupByWeek {
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	}
+	return day
+}
+
+func (s *summary) bucketEnd(start time.Time) time.Time {
+	if s.groupBy == GroupByWeek {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// add accounts the period of the activity inside the summary range, splitting it
+// when it crosses midnight (or the start of a week) in the summary location.
+// The key is the bucket of the activity when the summary is not grouped by time,
+// the amount is billed at rate, nil when the activity is not billable.
+func (s *summary) add(activity *models.Activity, key string, rate *models.Cents, start, end time.Time) {
+	if start.Before(s.from) {
+		start = s.from
+	}
+	if end.After(s.to) {
+		end = s.to
+	}
+	if !start.Before(end) {
+		return
+	}
+
+	s.total += end.Sub(start)
+	if rate != nil {
+		s.amount += rate.Bill(end.Sub(start))
+	}
+
+	if s.grouped() {
+		b, ok := s.byKey[key]
+		if !ok {
+			b = newBucket(key, time.Time{}, time.Time{})
+			s.buckets = append(s.buckets, b)
+			s.byKey[b.key] = b
+		}
+		b.add(activity, rate, end.Sub(start))
+		return
+	}
+
+	for start.Before(end) {
+		bucketStart := s.bucketStart(start)
+		bucketEnd := s.bucketEnd(bucketStart)
+		segmentEnd := end
+		if bucketEnd.Before(segmentEnd) {
+			segmentEnd = bucketEnd
+		}
+		s.byKey[bucketStart.Format(dateLayout)].add(activity, rate, segmentEnd.Sub(start))
+		start = segmentEnd
+	}
+}
+
+func (s *summary) Out() *types.SummaryReportOutput {
+	if s.grouped() {
+		sort.SliceStable(s.buckets, func(i, j int) bool {
+			if s.buckets[i].duration == s.buckets[j].duration {
+				return s.buckets[i].key < s.buckets[j].key
+			}
+			return s.buckets[i].duration > s.buckets[j].duration
+		})
+	}
+
+	out := &types.SummaryReportOutput{
+		From:     s.from.In(s.loc).Format(time.RFC3339),
+		To:       s.to.In(s.loc).Format(time.RFC3339),
+		TimeZone: s.loc.String(),
+		GroupBy:  s.groupBy,
+		Total:    seconds(s.total),
+		Amount:   s.amount.Float(),
+		Buckets:  make([]*types.SummaryBucketOutput, 0, len(s.buckets)),
+	}
+
+	for _, b := range s.buckets {
+		bucketOut := &types.SummaryBucketOutput{
+			Key:        b.key,
+			Duration:   seconds(b.duration),
+			Activities: len(b.activities),
+			Amount:     b.amount.Float(),
+		}
+		if !s.grouped() {
+			bucketOut.From = b.from.Format(time.RFC3339)
+			bucketOut.To = b.to.Format(time.RFC3339)
+			bucketOut.Categories = make(map[string]int64, len(b.categories))
+			for category, duration := range b.categories {
+				bucketOut.Categories[category] = seconds(duration)
+			}
+		}
+		out.Buckets = append(out.Buckets, bucketOut)
+	}
+
+	return out
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func seconds(d time.Duration) int64 {
+	return int64(d.Round(time.Second).Seconds())
+}
+
+func pointerTo(t time.Time) *time.Time {
+	return &t
+}
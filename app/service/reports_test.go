@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/types"
+	"testing"
+	"time"
+)
+
+func createFinishedActivity(t *testing.T, repo repository.ActivitiesRepository, category string, startedAt, finishedAt time.Time) {
+	t.Helper()
+
+	activity := &models.Activity{
+		Category:  category,
+		StartedAt: startedAt,
+		UpdatedAt: startedAt,
+		Intervals: []*models.Interval{{StartedAt: startedAt}},
+	}
+	if _, err := repo.Create(context.Background(), activity); err != nil {
+		t.Fatal(err)
+	}
+	activity.Finish(finishedAt)
+	if _, err := repo.Update(context.Background(), activity); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReportsSummary(t *testing.T) {
+	loc, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skip("time zone database unavailable:", err)
+	}
+
+	repo := repository.NewMemoryActivitiesRepository()
+	at := func(day, hour int) time.Time {
+		return time.Date(2024, time.March, day, hour, 0, 0, 0, loc)
+	}
+
+	createFinishedActivity(t, repo, "work", at(4, 22), at(5, 2))
+	createFinishedActivity(t, repo, "study", at(10, 23), at(11, 1))
+
+	reports := NewReportsService(repo, repository.NewMemoryProjectsRepository(), repository.NewMemoryClientsRepository(), time.UTC)
+
+	t.Run("Day", func(t *testing.T) {
+		output, err := reports.Summary(context.Background(), &types.SummaryReportInput{
+			From:     "2024-03-04",
+			To:       "2024-03-06",
+			GroupBy:  GroupByDay,
+			TimeZone: loc.String(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if output.Total != 4*3600 {
+			t.Errorf("expected total of 4h, got %ds", output.Total)
+		}
+		if len(output.Buckets) != 2 {
+			t.Fatalf("expected 2 buckets, got %d", len(output.Buckets))
+		}
+		for i, key := range []string{"2024-03-04", "2024-03-05"} {
+			bucket := output.Buckets[i]
+			if bucket.Key != key || bucket.Duration != 2*3600 || bucket.Categories["work"] != 2*3600 {
+				t.Errorf("unexpected bucket %d: %+v", i, bucket)
+			}
+		}
+	})
+
+	t.Run("Week", func(t *testing.T) {
+		output, err := reports.Summary(context.Background(), &types.SummaryReportInput{
+			From:     "2024-03-04",
+			To:       "2024-03-18",
+			GroupBy:  GroupByWeek,
+			TimeZone: loc.String(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(output.Buckets) != 2 {
+			t.Fatalf("expected 2 buckets, got %d", len(output.Buckets))
+		}
+		first, second := output.Buckets[0], output.Buckets[1]
+		if first.Key != "2024-03-04" || first.Duration != 5*3600 || first.Activities != 2 {
+			t.Errorf("unexpected first week: %+v", first)
+		}
+		if second.Key != "2024-03-11" || second.Duration != 3600 || second.Activities != 1 {
+			t.Errorf("unexpected second week: %+v", second)
+		}
+	})
+
+	t.Run("Category", func(t *testing.T) {
+		output, err := reports.Summary(context.Background(), &types.SummaryReportInput{
+			From:     "2024-03-04",
+			To:       "2024-03-18",
+			GroupBy:  GroupByCategory,
+			TimeZone: loc.String(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if output.Total != 6*3600 {
+			t.Errorf("expected total of 6h, got %ds", output.Total)
+		}
+		if len(output.Buckets) != 2 || output.Buckets[0].Key != "work" || output.Buckets[0].Duration != 4*3600 {
+			t.Errorf("unexpected buckets: %+v", output.Buckets)
+		}
+	})
+
+	t.Run("Tags", func(t *testing.T) {
+		output, err := reports.Summary(context.Background(), &types.SummaryReportInput{
+			From:    "2024-03-04",
+			To:      "2024-03-18",
+			GroupBy: GroupByCategory,
+			Tags:    []string{"billable"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if output.Total != 0 || len(output.Buckets) != 0 {
+			t.Errorf("expected no activities tagged billable, got %+v", output)
+		}
+	})
+
+	t.Run("InvalidInput", func(t *testing.T) {
+		inputs := []*types.SummaryReportInput{
+			{GroupBy: "month"},
+			{TimeZone: "Nowhere/City"},
+			{From: "2024-03-05", To: "2024-03-04"},
+		}
+		for _, input := range inputs {
+			if _, err := reports.Summary(context.Background(), input); err == nil {
+				t.Errorf("expected error for %+v", input)
+			}
+		}
+	})
+}
+
+func TestReportsSummaryRunning(t *testing.T) {
+	var (
+		repo    = repository.NewMemoryActivitiesRepository()
+		reports = NewReportsService(repo, repository.NewMemoryProjectsRepository(), repository.NewMemoryClientsRepository(), time.UTC)
+		now     = time.Now().UTC().Truncate(time.Second)
+	)
+
+	pausedAt := now.Add(-time.Hour * 2)
+	for _, activity := range []*models.Activity{
+		{Category: "paused", Status: models.StatusPaused, StartedAt: pausedAt.Add(-time.Hour), UpdatedAt: pausedAt, Intervals: []*models.Interval{{StartedAt: pausedAt.Add(-time.Hour), FinishedAt: &pausedAt}}},
+		{Category: "started", Status: models.StatusStarted, StartedAt: now.Add(-time.Hour), UpdatedAt: now.Add(-time.Hour), Intervals: []*models.Interval{{StartedAt: now.Add(-time.Hour)}}},
+	} {
+		if _, err := repo.Create(context.Background(), activity); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	output, err := reports.Summary(context.Background(), &types.SummaryReportInput{GroupBy: GroupByCategory})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output.Total != 3600 || len(output.Buckets) != 1 || output.Buckets[0].Key != "paused" {
+		t.Errorf("expected the paused activity counted without the started one, got %+v", output)
+	}
+
+	output, err = reports.Summary(context.Background(), &types.SummaryReportInput{GroupBy: GroupByCategory, IncludeRunning: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output.Total < 2*3600 || len(output.Buckets) != 2 {
+		t.Errorf("expected the started activity counted up to now, got %+v", output)
+	}
+}
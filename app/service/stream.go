@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"github.com/ungame/command-time-track/app/broker"
+)
+
+type StreamService interface {
+	// SubscribeActivities subscribes to the changes of the activities the request can read, resuming after lastEventID
+	// when given. The subscription must be closed once done.
+	SubscribeActivities(ctx context.Context, lastEventID int64) (*broker.Subscription, error)
+}
+
+type streamService struct {
+	broker *broker.Broker
+}
+
+func NewStreamService(broker *broker.Broker) StreamService {
+	return &streamService{broker: broker}
+}
+
+func (s *streamService) SubscribeActivities(ctx context.Context, lastEventID int64) (*broker.Subscription, error) {
+	if err := authorize(ctx, permRead); err != nil {
+		return nil, err
+	}
+	identity := identityFrom(ctx)
+	return s.broker.Subscribe(lastEventID, func(event *broker.Event) bool {
+		return identity == nil || identity.can(permRead, event.OwnerID) == nil
+	}), nil
+}
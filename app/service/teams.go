@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/types"
+	"log"
+	"strings"
+	"time"
+)
+
+type TeamsService interface {
+	CreateTeam(ctx context.Context, input *types.TeamInput) (*types.TeamOutput, error)
+	GetTeamByID(ctx context.Context, id int64) (*types.TeamOutput, error)
+	ListTeams(ctx context.Context) ([]*types.TeamOutput, error)
+	AddTeamMember(ctx context.Context, input *types.TeamMemberInput) (*types.TeamOutput, error)
+	RemoveTeamMember(ctx context.Context, input *types.TeamMemberInput) (*types.TeamOutput, error)
+}
+
+type teamsService struct {
+	teamsRepository repository.TeamsRepository
+	usersRepository repository.UsersRepository
+}
+
+func NewTeamsService(teamsRepository repository.TeamsRepository, usersRepository repository.UsersRepository) TeamsService {
+	return &teamsService{teamsRepository: teamsRepository, usersRepository: usersRepository}
+}
+
+// CreateTeam creates a team, names are unique ignoring case.
+func (s *teamsService) CreateTeam(ctx context.Context, input *types.TeamInput) (*types.TeamOutput, error) {
+	if err := authorize(ctx, permManage); err != nil {
+		return nil, err
+	}
+
+	name, err := validName(input.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	teams, err := s.teamsRepository.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range teams {
+		if strings.EqualFold(existing.Name, name) {
+			return nil, &ConflictError{Reason: "team name already exists", IDs: []int64{existing.ID}}
+		}
+	}
+
+	team := &models.Team{Name: name, CreatedAt: time.Now().UTC()}
+	if _, err = s.teamsRepository.Create(ctx, team); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Team created: ID=%v\n", team.ID)
+
+	return team.Out(), nil
+}
+
+func (s *teamsService) GetTeamByID(ctx context.Context, id int64) (*types.TeamOutput, error) {
+	team, err := s.teamsRepository.Get(ctx, id)
+	if err != nil {
+		return nil, notFound(err, "team", id)
+	}
+	return team.Out(), nil
+}
+
+func (s *teamsService) ListTeams(ctx context.Context) ([]*types.TeamOutput, error) {
+	teams, err := s.teamsRepository.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	output := make([]*types.TeamOutput, 0, len(teams))
+	for _, team := range teams {
+		output = append(output, team.Out())
+	}
+	return output, nil
+}
+
+// AddTeamMember adds the user to the team, letting them read the activities of the other members.
+func (s *teamsService) AddTeamMember(ctx context.Context, input *types.TeamMemberInput) (*types.TeamOutput, error) {
+	if err := authorize(ctx, permManage); err != nil {
+		return nil, err
+	}
+
+	team, err := s.teamsRepository.Get(ctx, input.TeamID)
+	if err != nil {
+		return nil, notFound(err, "team", input.TeamID)
+	}
+	if _, err = s.usersRepository.Get(ctx, input.UserID); err != nil {
+		return nil, invalidField("user_id", fmt.Errorf("user not found: %d", input.UserID))
+	}
+	for _, member := range team.Members {
+		if member == input.UserID {
+			return team.Out(), nil
+		}
+	}
+
+	if err = s.teamsRepository.AddMember(ctx, team.ID, input.UserID); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Team member added: ID=%v, user ID=%v\n", team.ID, input.UserID)
+
+	return s.GetTeamByID(ctx, team.ID)
+}
+
+func (s *teamsService) RemoveTeamMember(ctx context.Context, input *types.TeamMemberInput) (*types.TeamOutput, error) {
+	if err := authorize(ctx, permManage); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.teamsRepository.Get(ctx, input.TeamID); err != nil {
+		return nil, notFound(err, "team", input.TeamID)
+	}
+
+	rows, err := s.teamsRepository.RemoveMember(ctx, input.TeamID, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		return nil, fmt.Errorf("%w: member %d of team %d", ErrNotFound, input.UserID, input.TeamID)
+	}
+
+	log.Printf("Team member removed: ID=%v, user ID=%v\n", input.TeamID, input.UserID)
+
+	return s.GetTeamByID(ctx, input.TeamID)
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/types"
+	"log"
+	"time"
+)
+
+type TokensService interface {
+	CreateToken(ctx context.Context, input *types.TokenInput) (*types.CreateTokenOutput, error)
+	ListTokens(ctx context.Context) ([]*types.TokenOutput, error)
+	RevokeToken(ctx context.Context, id int64) error
+}
+
+type tokensService struct {
+	tokensRepository repository.TokensRepository
+}
+
+func NewTokensService(tokensRepository repository.TokensRepository) TokensService {
+	return &tokensService{tokensRepository: tokensRepository}
+}
+
+// userOf returns the user making the request, the default user when the context is not scoped to one.
+func userOf(ctx context.Context) int64 {
+	if userID := repository.OwnerFrom(ctx); userID != 0 {
+		return userID
+	}
+	return models.DefaultUserID
+}
+
+// CreateToken creates a token of the user making the request, the secret is only part of this output.
+func (s *tokensService) CreateToken(ctx context.Context, input *types.TokenInput) (*types.CreateTokenOutput, error) {
+	name, err := validName(input.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	token, secret, err := models.NewToken(userOf(ctx), name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = s.tokensRepository.Create(ctx, token); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Token created: ID=%v, user ID=%v\n", token.ID, token.UserID)
+
+	return &types.CreateTokenOutput{TokenOutput: *token.Out(), Token: secret}, nil
+}
+
+func (s *tokensService) ListTokens(ctx context.Context) ([]*types.TokenOutput, error) {
+	tokens, err := s.tokensRepository.GetByUser(ctx, userOf(ctx))
+	if err != nil {
+		return nil, err
+	}
+	output := make([]*types.TokenOutput, 0, len(tokens))
+	for _, token := range tokens {
+		output = append(output, token.Out())
+	}
+	return output, nil
+}
+
+// RevokeToken revokes a token of the user making the request, revoked tokens are kept to be listed.
+func (s *tokensService) RevokeToken(ctx context.Context, id int64) error {
+	rows, err := s.tokensRepository.Revoke(ctx, userOf(ctx), id, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return notFound(sql.ErrNoRows, "token", id)
+	}
+
+	log.Printf("Token revoked: ID=%v\n", id)
+
+	return nil
+}
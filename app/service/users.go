@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/types"
+	"log"
+	"strings"
+	"time"
+)
+
+type UsersService interface {
+	CreateUser(ctx context.Context, input *types.UserInput) (*types.UserOutput, error)
+	UpdateUserRole(ctx context.Context, input *types.UserRoleInput) (*types.UserOutput, error)
+	GetUserByID(ctx context.Context, id int64) (*types.UserOutput, error)
+	ListUsers(ctx context.Context) ([]*types.UserOutput, error)
+	// Identify returns a context acting as the user, see WithIdentity.
+	Identify(ctx context.Context, userID int64) (context.Context, error)
+}
+
+type usersService struct {
+	usersRepository repository.UsersRepository
+	teamsRepository repository.TeamsRepository
+}
+
+func NewUsersService(usersRepository repository.UsersRepository, teamsRepository repository.TeamsRepository) UsersService {
+	return &usersService{usersRepository: usersRepository, teamsRepository: teamsRepository}
+}
+
+// CreateUser creates a user, names are unique ignoring case. Users are members unless given another role.
+func (s *usersService) CreateUser(ctx context.Context, input *types.UserInput) (*types.UserOutput, error) {
+	if err := authorize(ctx, permManage); err != nil {
+		return nil, err
+	}
+
+	name, err := validName(input.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	role := models.RoleMember
+	if input.Role != "" {
+		if role, err = models.ParseRole(input.Role); err != nil {
+			return nil, invalidInput(err)
+		}
+	}
+
+	users, err := s.usersRepository.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range users {
+		if strings.EqualFold(existing.Name, name) {
+			return nil, &ConflictError{Reason: "user name already exists", IDs: []int64{existing.ID}}
+		}
+	}
+
+	user := &models.User{Name: name, Role: role, CreatedAt: time.Now().UTC()}
+	if _, err = s.usersRepository.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	log.Printf("User created: ID=%v, role=%s\n", user.ID, user.Role)
+
+	return user.Out(), nil
+}
+
+// UpdateUserRole changes the role of a user, the last admin can't lose it.
+func (s *usersService) UpdateUserRole(ctx context.Context, input *types.UserRoleInput) (*types.UserOutput, error) {
+	if err := authorize(ctx, permManage); err != nil {
+		return nil, err
+	}
+
+	role, err := models.ParseRole(input.Role)
+	if err != nil {
+		return nil, invalidInput(err)
+	}
+
+	user, err := s.usersRepository.Get(ctx, input.ID)
+	if err != nil {
+		return nil, notFound(err, "user", input.ID)
+	}
+	if user.Role == role {
+		return user.Out(), nil
+	}
+
+	if user.Role == models.RoleAdmin {
+		users, err := s.usersRepository.GetAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		admins := 0
+		for _, existing := range users {
+			if existing.Role == models.RoleAdmin {
+				admins++
+			}
+		}
+		if admins == 1 {
+			return nil, &ConflictError{Reason: "the last admin can't change role", IDs: []int64{user.ID}}
+		}
+	}
+
+	if _, err = s.usersRepository.SetRole(ctx, user.ID, role); err != nil {
+		return nil, err
+	}
+	user.Role = role
+
+	log.Printf("User role updated: ID=%v, role=%s\n", user.ID, user.Role)
+
+	return user.Out(), nil
+}
+
+func (s *usersService) GetUserByID(ctx context.Context, id int64) (*types.UserOutput, error) {
+	user, err := s.usersRepository.Get(ctx, id)
+	if err != nil {
+		return nil, notFound(err, "user", id)
+	}
+	return user.Out(), nil
+}
+
+func (s *usersService) ListUsers(ctx context.Context) ([]*types.UserOutput, error) {
+	users, err := s.usersRepository.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	output := make([]*types.UserOutput, 0, len(users))
+	for _, user := range users {
+		output = append(output, user.Out())
+	}
+	return output, nil
+}
+
+func (s *usersService) Identify(ctx context.Context, userID int64) (context.Context, error) {
+	user, err := s.usersRepository.Get(ctx, userID)
+	if err != nil {
+		return nil, notFound(err, "user", userID)
+	}
+	teammates, err := s.teamsRepository.Teammates(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	identity := &Identity{UserID: user.ID, Role: user.Role, Teammates: make(map[int64]bool, len(teammates))}
+	for _, id := range teammates {
+		identity.Teammates[id] = true
+	}
+	return WithIdentity(ctx, identity), nil
+}
@@ -0,0 +1,485 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ungame/command-time-track/app/broker"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/types"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxWebhookAttempts is the number of attempts of a delivery before it fails for good.
+	MaxWebhookAttempts = 8
+	// webhookBackoff is the wait before attempting a delivery again after its first failure, doubling on every
+	// failure up to maxWebhookBackoff.
+	webhookBackoff    = time.Second * 30
+	maxWebhookBackoff = time.Hour
+	// deliveriesLimit is the number of deliveries listed and attempted at once, and of events dispatched at once.
+	deliveriesLimit = 100
+	// webhookWorkers is the number of webhooks delivered to at once, the deliveries of a webhook are sent in turn
+	// so a slow webhook holds a single worker.
+	webhookWorkers = 8
+	// deliverBudget is how long deliveries are started for on every attempt of the deliveries due, those left
+	// are attempted next time.
+	deliverBudget = time.Second * 30
+	// maxWebhookSecretLength fits the secret column.
+	maxWebhookSecretLength = 100
+)
+
+// WebhookSender posts a delivery to its webhook, returning the status of the response, zero without response.
+// Check fails when the sender refuses to post to the url, like to private addresses. See webhook.Client.
+type WebhookSender interface {
+	Send(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error)
+	Check(ctx context.Context, address string) error
+}
+
+type WebhooksService interface {
+	CreateWebhook(ctx context.Context, input *types.WebhookInput) (*types.CreateWebhookOutput, error)
+	UpdateWebhook(ctx context.Context, input *types.UpdateWebhookInput) (*types.WebhookOutput, error)
+	DeleteWebhook(ctx context.Context, id int64) error
+	GetWebhook(ctx context.Context, id int64) (*types.WebhookOutput, error)
+	ListWebhooks(ctx context.Context) ([]*types.WebhookOutput, error)
+	// ListDeliveries returns the last deliveries of the webhook with the status, of any status when empty.
+	ListDeliveries(ctx context.Context, webhookID int64, status string) ([]*types.WebhookDeliveryOutput, error)
+	// ReplayDelivery sends the event of a delivery again, as a new delivery.
+	ReplayDelivery(ctx context.Context, webhookID, deliveryID int64) (*types.WebhookDeliveryOutput, error)
+	// Dispatch queues a delivery of the events in the outbox to the active webhooks accepting them, when their
+	// owner can read the activity, returning the number of events dispatched. See WebhookOutbox.
+	Dispatch(ctx context.Context) (int, error)
+	// DeliverDue attempts the pending deliveries due at the time, returning the number of deliveries attempted.
+	DeliverDue(ctx context.Context, now time.Time) (int, error)
+}
+
+type webhooksService struct {
+	transactor         repository.Transactor
+	webhooksRepository repository.WebhooksRepository
+	usersService       UsersService
+	sender             WebhookSender
+}
+
+func NewWebhooksService(transactor repository.Transactor, webhooksRepository repository.WebhooksRepository, usersService UsersService, sender WebhookSender) WebhooksService {
+	return &webhooksService{transactor: transactor, webhooksRepository: webhooksRepository, usersService: usersService, sender: sender}
+}
+
+type outboxEventsRepository struct {
+	repository.ActivityEventsRepository
+	webhooksRepository repository.WebhooksRepository
+}
+
+// WebhookOutbox returns the repository queuing the activity events it records to dispatch to the webhooks, in
+// the transaction recording them, so the events committed are dispatched even when the server stops before.
+func WebhookOutbox(activityEventsRepository repository.ActivityEventsRepository, webhooksRepository repository.WebhooksRepository) repository.ActivityEventsRepository {
+	return &outboxEventsRepository{ActivityEventsRepository: activityEventsRepository, webhooksRepository: webhooksRepository}
+}
+
+func (r *outboxEventsRepository) Create(ctx context.Context, event *models.ActivityEvent) (int64, error) {
+	id, err := r.ActivityEventsRepository.Create(ctx, event)
+	if err != nil {
+		return id, err
+	}
+	data, err := broker.DataOf(event)
+	if err != nil {
+		return id, err
+	}
+	return id, r.webhooksRepository.QueueEvent(ctx, &models.WebhookEvent{
+		EventID:   id,
+		EventType: broker.TypeOf(event.Action),
+		OwnerID:   event.OwnerID,
+		Data:      string(data),
+		CreatedAt: event.CreatedAt,
+	})
+}
+
+// validWebhookURL returns the url of a webhook once checked the sender posts to it.
+func (s *webhooksService) validWebhookURL(ctx context.Context, address string) (string, error) {
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return "", invalidField("url", errors.New("url is required"))
+	}
+	u, err := url.Parse(address)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", invalidField("url", fmt.Errorf("invalid url: %s, must be an absolute http or https url", address))
+	}
+	if err = s.sender.Check(ctx, address); err != nil {
+		return "", invalidField("url", err)
+	}
+	return address, nil
+}
+
+// validWebhookEvents returns the distinct types of the events, none accepts every event.
+func validWebhookEvents(events []string) ([]string, error) {
+	valid := make([]string, 0, len(events))
+	seen := make(map[string]bool, len(events))
+	for _, event := range events {
+		event = strings.ToLower(strings.TrimSpace(event))
+		switch event {
+		case broker.TypeStarted, broker.TypeStopped, broker.TypeUpdated, broker.TypeDeleted:
+		default:
+			return nil, invalidField("events", fmt.Errorf("invalid event: %s, must be started, stopped, updated or deleted", event))
+		}
+		if !seen[event] {
+			seen[event] = true
+			valid = append(valid, event)
+		}
+	}
+	return valid, nil
+}
+
+// webhookOf returns the webhook of the user making the request, the webhooks of other users are not found.
+func (s *webhooksService) webhookOf(ctx context.Context, id int64) (*models.Webhook, error) {
+	webhook, err := s.webhooksRepository.Get(ctx, id)
+	if err == nil && webhook.OwnerID != userOf(ctx) {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, notFound(err, "webhook", id)
+	}
+	return webhook, nil
+}
+
+// CreateWebhook creates a webhook of the user making the request, the secret is only part of this output.
+// Viewers can't, the server makes requests to the url.
+func (s *webhooksService) CreateWebhook(ctx context.Context, input *types.WebhookInput) (*types.CreateWebhookOutput, error) {
+	if err := authorize(ctx, permWrite); err != nil {
+		return nil, err
+	}
+	address, err := s.validWebhookURL(ctx, input.URL)
+	if err != nil {
+		return nil, err
+	}
+	events, err := validWebhookEvents(input.Events)
+	if err != nil {
+		return nil, err
+	}
+	secret := strings.TrimSpace(input.Secret)
+	if len(secret) > maxWebhookSecretLength {
+		return nil, invalidField("secret", fmt.Errorf("secret is longer than %d characters", maxWebhookSecretLength))
+	}
+	if secret == "" {
+		if secret, err = models.NewWebhookSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC()
+	webhook := &models.Webhook{
+		OwnerID:   userOf(ctx),
+		URL:       address,
+		Secret:    secret,
+		Events:    events,
+		Active:    true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if _, err = s.webhooksRepository.Create(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Webhook created: ID=%v, user ID=%v\n", webhook.ID, webhook.OwnerID)
+
+	return &types.CreateWebhookOutput{WebhookOutput: *webhook.Out(), Secret: secret}, nil
+}
+
+func (s *webhooksService) UpdateWebhook(ctx context.Context, input *types.UpdateWebhookInput) (*types.WebhookOutput, error) {
+	if err := authorize(ctx, permWrite); err != nil {
+		return nil, err
+	}
+	webhook, err := s.webhookOf(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	if webhook.URL, err = s.validWebhookURL(ctx, input.URL); err != nil {
+		return nil, err
+	}
+	if webhook.Events, err = validWebhookEvents(input.Events); err != nil {
+		return nil, err
+	}
+	if input.Active != nil {
+		webhook.Active = *input.Active
+	}
+	webhook.UpdatedAt = time.Now().UTC()
+
+	rows, err := s.webhooksRepository.Update(ctx, webhook)
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		return nil, notFound(sql.ErrNoRows, "webhook", webhook.ID)
+	}
+
+	log.Printf("Webhook updated: ID=%v\n", webhook.ID)
+
+	return webhook.Out(), nil
+}
+
+// DeleteWebhook deletes the webhook along with its deliveries.
+func (s *webhooksService) DeleteWebhook(ctx context.Context, id int64) error {
+	if err := authorize(ctx, permWrite); err != nil {
+		return err
+	}
+	if _, err := s.webhookOf(ctx, id); err != nil {
+		return err
+	}
+	rows, err := s.webhooksRepository.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return notFound(sql.ErrNoRows, "webhook", id)
+	}
+
+	log.Printf("Webhook deleted: ID=%v\n", id)
+
+	return nil
+}
+
+func (s *webhooksService) GetWebhook(ctx context.Context, id int64) (*types.WebhookOutput, error) {
+	webhook, err := s.webhookOf(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return webhook.Out(), nil
+}
+
+func (s *webhooksService) ListWebhooks(ctx context.Context) ([]*types.WebhookOutput, error) {
+	webhooks, err := s.webhooksRepository.GetByOwner(ctx, userOf(ctx))
+	if err != nil {
+		return nil, err
+	}
+	output := make([]*types.WebhookOutput, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		output = append(output, webhook.Out())
+	}
+	return output, nil
+}
+
+func (s *webhooksService) ListDeliveries(ctx context.Context, webhookID int64, status string) ([]*types.WebhookDeliveryOutput, error) {
+	switch status {
+	case "", models.DeliveryPending, models.DeliverySucceeded, models.DeliveryFailed:
+	default:
+		return nil, invalidField("status", fmt.Errorf("invalid status: %s, must be pending, succeeded or failed", status))
+	}
+	if _, err := s.webhookOf(ctx, webhookID); err != nil {
+		return nil, err
+	}
+	deliveries, err := s.webhooksRepository.GetDeliveries(ctx, webhookID, status, deliveriesLimit)
+	if err != nil {
+		return nil, err
+	}
+	output := make([]*types.WebhookDeliveryOutput, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		output = append(output, delivery.Out())
+	}
+	return output, nil
+}
+
+func (s *webhooksService) ReplayDelivery(ctx context.Context, webhookID, deliveryID int64) (*types.WebhookDeliveryOutput, error) {
+	if err := authorize(ctx, permWrite); err != nil {
+		return nil, err
+	}
+	if _, err := s.webhookOf(ctx, webhookID); err != nil {
+		return nil, err
+	}
+	delivery, err := s.webhooksRepository.GetDelivery(ctx, deliveryID)
+	if err == nil && delivery.WebhookID != webhookID {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, notFound(err, "delivery", deliveryID)
+	}
+
+	replay := newDelivery(webhookID, delivery.EventID, delivery.EventType, delivery.Payload, time.Now().UTC())
+	if _, err = s.webhooksRepository.CreateDelivery(ctx, replay); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Webhook delivery replayed: ID=%v, replay ID=%v\n", delivery.ID, replay.ID)
+
+	return replay.Out(), nil
+}
+
+func newDelivery(webhookID, eventID int64, eventType, payload string, now time.Time) *models.WebhookDelivery {
+	return &models.WebhookDelivery{
+		WebhookID:     webhookID,
+		EventID:       eventID,
+		EventType:     eventType,
+		Payload:       payload,
+		Status:        models.DeliveryPending,
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// errDispatched rolls back the dispatch of an event dispatched meanwhile.
+var errDispatched = errors.New("event dispatched already")
+
+func (s *webhooksService) Dispatch(ctx context.Context) (int, error) {
+	events, err := s.webhooksRepository.GetQueuedEvents(ctx, deliveriesLimit)
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+	webhooks, err := s.webhooksRepository.GetActive(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	identities := make(map[int64]*Identity)
+	for _, webhook := range webhooks {
+		if _, ok := identities[webhook.OwnerID]; ok {
+			continue
+		}
+		ownerCtx, err := s.usersService.Identify(ctx, webhook.OwnerID)
+		if err != nil {
+			return 0, err
+		}
+		identities[webhook.OwnerID] = identityFrom(ownerCtx)
+	}
+
+	for i, event := range events {
+		err = s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+			return s.dispatch(ctx, event, webhooks, identities)
+		})
+		if err != nil && !errors.Is(err, errDispatched) {
+			return i, err
+		}
+	}
+	return len(events), nil
+}
+
+// dispatch queues the deliveries of the event and removes it from the outbox, in the transaction in the context.
+func (s *webhooksService) dispatch(ctx context.Context, event *models.WebhookEvent, webhooks []*models.Webhook, identities map[int64]*Identity) error {
+	payload, err := json.Marshal(&types.WebhookPayload{EventID: event.EventID, Event: event.EventType, Data: json.RawMessage(event.Data)})
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for _, webhook := range webhooks {
+		if !webhook.Accepts(event.EventType) || identities[webhook.OwnerID].can(permRead, event.OwnerID) != nil {
+			continue
+		}
+		if _, err = s.webhooksRepository.CreateDelivery(ctx, newDelivery(webhook.ID, event.EventID, event.EventType, string(payload), now)); err != nil {
+			return err
+		}
+	}
+	rows, err := s.webhooksRepository.DequeueEvent(ctx, event.EventID)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errDispatched
+	}
+	return nil
+}
+
+// nextAttempt returns when a delivery failing after the attempts is attempted again, nil once it failed for good.
+func nextAttempt(attempts int, now time.Time) *time.Time {
+	if attempts >= MaxWebhookAttempts {
+		return nil
+	}
+	backoff := webhookBackoff
+	for i := 1; i < attempts && backoff < maxWebhookBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxWebhookBackoff {
+		backoff = maxWebhookBackoff
+	}
+	next := now.Add(backoff)
+	return &next
+}
+
+func (s *webhooksService) DeliverDue(ctx context.Context, now time.Time) (int, error) {
+	deliveries, err := s.webhooksRepository.GetDueDeliveries(ctx, now, deliveriesLimit)
+	if err != nil {
+		return 0, err
+	}
+	var (
+		webhooks = make(map[int64]*models.Webhook)
+		queues   = make(map[int64][]*models.WebhookDelivery)
+		order    = make([]int64, 0)
+	)
+	for _, delivery := range deliveries {
+		webhook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			webhook, err = s.webhooksRepository.Get(ctx, delivery.WebhookID)
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				// deleted along with its deliveries since
+				webhook = nil
+			case err != nil:
+				return 0, err
+			default:
+				order = append(order, webhook.ID)
+			}
+			webhooks[delivery.WebhookID] = webhook
+		}
+		if webhook != nil {
+			queues[webhook.ID] = append(queues[webhook.ID], delivery)
+		}
+	}
+
+	var (
+		deadline  = time.Now().Add(deliverBudget)
+		pending   = make(chan int64)
+		workers   sync.WaitGroup
+		mutex     sync.Mutex
+		attempted int
+		failure   error
+	)
+	for i := 0; i < webhookWorkers && i < len(order); i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for id := range pending {
+				n, err := s.deliver(ctx, webhooks[id], queues[id], now, deadline)
+				mutex.Lock()
+				attempted += n
+				if failure == nil {
+					failure = err
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	for _, id := range order {
+		pending <- id
+	}
+	close(pending)
+	workers.Wait()
+	return attempted, failure
+}
+
+// deliver attempts the deliveries of the webhook in turn until the deadline, returning the number of deliveries
+// attempted.
+func (s *webhooksService) deliver(ctx context.Context, webhook *models.Webhook, deliveries []*models.WebhookDelivery, now, deadline time.Time) (int, error) {
+	for i, delivery := range deliveries {
+		if ctx.Err() != nil || time.Now().After(deadline) {
+			return i, nil
+		}
+
+		if !webhook.Active {
+			// deliveries queued before the webhook was paused are kept to be replayed
+			delivery.Fail(0, errors.New("webhook is paused"), now, nil)
+		} else if status, err := s.sender.Send(ctx, webhook, delivery); err != nil {
+			delivery.Fail(status, err, now, nextAttempt(delivery.Attempts+1, now))
+			log.Printf("Webhook delivery failed: ID=%v, attempts=%d, error=%s\n", delivery.ID, delivery.Attempts, err.Error())
+		} else {
+			delivery.Succeed(status, now)
+		}
+
+		if _, err := s.webhooksRepository.UpdateDelivery(ctx, delivery); err != nil {
+			return i, err
+		}
+	}
+	return len(deliveries), nil
+}
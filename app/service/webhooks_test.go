@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ungame/command-time-track/app/broker"
+	"github.com/ungame/command-time-track/app/models"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/types"
+	"strings"
+	"testing"
+	"time"
+)
+
+// senderFunc sends the deliveries with a function.
+type senderFunc func(webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error)
+
+func (f senderFunc) Send(_ context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	return f(webhook, delivery)
+}
+
+// Check refuses the urls of the 10.0.0.0/8 network, standing for the private addresses.
+func (f senderFunc) Check(_ context.Context, address string) error {
+	if strings.HasPrefix(address, "http://10.") {
+		return errors.New("webhook target not allowed")
+	}
+	return nil
+}
+
+func TestWebhooks(t *testing.T) {
+	var (
+		background   = context.Background()
+		webhooksRepo = repository.NewMemoryWebhooksRepository()
+		usersService = NewUsersService(repository.NewMemoryUsersRepository(), repository.NewMemoryTeamsRepository())
+		status       = 500
+		sent         = make([]*models.WebhookDelivery, 0)
+		sender       = senderFunc(func(webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+			sent = append(sent, delivery)
+			if status != 200 {
+				return status, fmt.Errorf("unexpected status: %d", status)
+			}
+			return status, nil
+		})
+		webhooksService = NewWebhooksService(repository.NewMemoryTransactor(), webhooksRepo, usersService, sender)
+	)
+
+	as := func(userID int64) context.Context {
+		t.Helper()
+		ctx, err := usersService.Identify(background, userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ctx
+	}
+	admin := as(models.DefaultUserID)
+	user, err := usersService.CreateUser(admin, &types.UserInput{Name: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice := as(user.ID)
+
+	viewer, err := usersService.CreateUser(admin, &types.UserInput{Name: "carol", Role: "viewer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = webhooksService.CreateWebhook(as(viewer.ID), &types.WebhookInput{URL: "https://example.com/hook"})
+	assertForbidden(t, err, ReasonReadOnly)
+
+	for _, input := range []*types.WebhookInput{
+		{URL: ""},
+		{URL: "example.com/hook"},
+		{URL: "ftp://example.com/hook"},
+		{URL: "https://example.com/hook", Events: []string{"paused"}},
+		{URL: "http://10.0.0.1/hook"},
+	} {
+		if _, err = webhooksService.CreateWebhook(alice, input); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("expected invalid webhook %+v rejected, got %v", input, err)
+		}
+	}
+
+	created, err := webhooksService.CreateWebhook(alice, &types.WebhookInput{URL: "https://example.com/hook", Events: []string{" Started", "stopped", "started"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created.Secret) <= len(models.WebhookSecretPrefix) || len(created.Events) != 2 || !created.Active {
+		t.Errorf("unexpected webhook: %+v", created)
+	}
+	deletions, err := webhooksService.CreateWebhook(alice, &types.WebhookInput{URL: "https://example.com/deleted", Events: []string{"deleted"}, Secret: "shared"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deletions.Secret != "shared" {
+		t.Errorf("expected secret given kept, got %s", deletions.Secret)
+	}
+	everything, err := webhooksService.CreateWebhook(admin, &types.WebhookInput{URL: "http://localhost:8080/hook"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = webhooksService.GetWebhook(admin, created.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected webhook of another user not found, got %v", err)
+	}
+	if listed, _ := webhooksService.ListWebhooks(alice); len(listed) != 2 {
+		t.Errorf("expected webhooks of the user listed, got %+v", listed)
+	}
+
+	// events of alice reach her webhooks accepting them and the admin, events of the admin are hidden from alice
+	data := `{"activity_id":1}`
+	for _, event := range []*models.WebhookEvent{
+		{EventID: 1, EventType: broker.TypeStarted, OwnerID: user.ID, Data: data},
+		{EventID: 2, EventType: broker.TypeUpdated, OwnerID: user.ID, Data: data},
+		{EventID: 3, EventType: broker.TypeStarted, OwnerID: models.DefaultUserID, Data: data},
+	} {
+		if err = webhooksRepo.QueueEvent(background, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if dispatched, err := webhooksService.Dispatch(background); err != nil || dispatched != 3 {
+		t.Fatalf("expected events queued dispatched, got dispatched=%d, err=%v", dispatched, err)
+	}
+	if dispatched, _ := webhooksService.Dispatch(background); dispatched != 0 {
+		t.Errorf("expected events dispatched once, got %d", dispatched)
+	}
+	deliveries, err := webhooksService.ListDeliveries(alice, created.ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 || deliveries[0].EventID != 1 || deliveries[0].Status != models.DeliveryPending {
+		t.Fatalf("unexpected deliveries: %+v", deliveries)
+	}
+	var payload types.WebhookPayload
+	if err = json.Unmarshal(deliveries[0].Payload, &payload); err != nil || payload.EventID != 1 || payload.Event != broker.TypeStarted || string(payload.Data) != data {
+		t.Errorf("unexpected payload: %s", deliveries[0].Payload)
+	}
+	if deliveries, _ = webhooksService.ListDeliveries(admin, everything.ID, ""); len(deliveries) != 3 {
+		t.Errorf("expected every event delivered to the admin, got %+v", deliveries)
+	}
+	if deliveries, _ = webhooksService.ListDeliveries(alice, deletions.ID, ""); len(deliveries) != 0 {
+		t.Errorf("expected events filtered, got %+v", deliveries)
+	}
+
+	if _, err = webhooksService.UpdateWebhook(admin, &types.UpdateWebhookInput{ID: everything.ID, URL: everything.URL, Active: new(bool)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// failed attempts back off, doubling the wait
+	now := time.Now().UTC()
+	attempted, err := webhooksService.DeliverDue(background, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempted != 4 || len(sent) != 1 || sent[0].EventID != 1 {
+		t.Fatalf("expected due deliveries attempted, paused webhook skipped, got attempted=%d, sent=%+v", attempted, sent)
+	}
+	if deliveries, _ = webhooksService.ListDeliveries(admin, everything.ID, models.DeliveryFailed); len(deliveries) != 3 {
+		t.Errorf("expected deliveries of paused webhook failed, got %+v", deliveries)
+	}
+	for i, wait := range []time.Duration{time.Second * 30, time.Minute, time.Minute * 2} {
+		if attempted, _ = webhooksService.DeliverDue(background, now.Add(wait-time.Second)); attempted != 0 {
+			t.Errorf("expected delivery not attempted before its backoff of %s", wait)
+		}
+		now = now.Add(wait)
+		if attempted, _ = webhooksService.DeliverDue(background, now); attempted != 1 {
+			t.Fatalf("expected delivery attempted after its backoff of %s", wait)
+		}
+		deliveries, _ = webhooksService.ListDeliveries(alice, created.ID, "")
+		if deliveries[0].Attempts != i+2 || *deliveries[0].ResponseStatus != 500 || deliveries[0].LastError == nil {
+			t.Errorf("unexpected delivery after failed attempt: %+v", deliveries[0])
+		}
+	}
+
+	status = 200
+	if _, err = webhooksService.DeliverDue(background, now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	deliveries, _ = webhooksService.ListDeliveries(alice, created.ID, "")
+	if deliveries[0].Status != models.DeliverySucceeded || deliveries[0].Attempts != 5 || deliveries[0].LastError != nil || deliveries[0].NextAttemptAt != nil {
+		t.Errorf("unexpected delivery after successful attempt: %+v", deliveries[0])
+	}
+
+	// deliveries fail for good after the last attempt
+	status = 503
+	if err = webhooksRepo.QueueEvent(background, &models.WebhookEvent{EventID: 4, EventType: broker.TypeStopped, OwnerID: user.ID, Data: data}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = webhooksService.Dispatch(background); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < MaxWebhookAttempts; i++ {
+		now = now.Add(maxWebhookBackoff * 2)
+		if _, err = webhooksService.DeliverDue(background, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	failed, _ := webhooksService.ListDeliveries(alice, created.ID, models.DeliveryFailed)
+	if len(failed) != 1 || failed[0].EventID != 4 || failed[0].Attempts != MaxWebhookAttempts || failed[0].NextAttemptAt != nil {
+		t.Fatalf("expected delivery failed for good, got %+v", failed)
+	}
+
+	if _, err = webhooksService.ReplayDelivery(alice, deletions.ID, failed[0].ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected delivery of another webhook not replayed, got %v", err)
+	}
+	_, err = webhooksService.ReplayDelivery(as(viewer.ID), created.ID, failed[0].ID)
+	assertForbidden(t, err, ReasonReadOnly)
+	replay, err := webhooksService.ReplayDelivery(alice, created.ID, failed[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replay.ID == failed[0].ID || replay.EventID != 4 || replay.Status != models.DeliveryPending || replay.Attempts != 0 || string(replay.Payload) != string(failed[0].Payload) {
+		t.Errorf("unexpected replay: %+v", replay)
+	}
+
+	if err = webhooksService.DeleteWebhook(admin, created.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected webhook of another user not deleted, got %v", err)
+	}
+	assertForbidden(t, webhooksService.DeleteWebhook(as(viewer.ID), created.ID), ReasonReadOnly)
+	if err = webhooksService.DeleteWebhook(alice, created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = webhooksService.ListDeliveries(alice, created.ID, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected deleted webhook not found, got %v", err)
+	}
+}
+
+func TestWebhookOutbox(t *testing.T) {
+	var (
+		ctx          = context.Background()
+		transactor   = repository.NewMemoryTransactor()
+		webhooksRepo = repository.NewMemoryWebhooksRepository()
+		events       = WebhookOutbox(repository.NewMemoryActivityEventsRepository(), webhooksRepo)
+		usersService = NewUsersService(repository.NewMemoryUsersRepository(), repository.NewMemoryTeamsRepository())
+		sender       = senderFunc(func(webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+			return 200, nil
+		})
+		webhooksService = NewWebhooksService(transactor, webhooksRepo, usersService, sender)
+		rollback        = errors.New("rollback")
+	)
+
+	admin, err := usersService.Identify(ctx, models.DefaultUserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = webhooksService.CreateWebhook(admin, &types.WebhookInput{URL: "https://example.com/hook"}); err != nil {
+		t.Fatal(err)
+	}
+
+	record := func(ctx context.Context, action string) *models.ActivityEvent {
+		t.Helper()
+		after := `{"id":1}`
+		event := &models.ActivityEvent{ActivityID: 1, OwnerID: models.DefaultUserID, ActorID: models.DefaultUserID, Action: action, After: &after}
+		if _, err := events.Create(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+		return event
+	}
+
+	err = transactor.WithinTx(ctx, func(ctx context.Context) error {
+		record(ctx, models.ActionCreated)
+		return rollback
+	})
+	if !errors.Is(err, rollback) {
+		t.Fatalf("expected rollback, got %v", err)
+	}
+	var stopped *models.ActivityEvent
+	err = transactor.WithinTx(ctx, func(ctx context.Context) error {
+		stopped = record(ctx, models.ActionStopped)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queued, err := webhooksRepo.GetQueuedEvents(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queued) != 1 || queued[0].EventID != stopped.ID || queued[0].EventType != broker.TypeStopped {
+		t.Fatalf("expected only the committed event queued, got %+v", queued)
+	}
+
+	if dispatched, err := webhooksService.Dispatch(ctx); err != nil || dispatched != 1 {
+		t.Fatalf("expected event dispatched, got dispatched=%d, err=%v", dispatched, err)
+	}
+	if queued, _ = webhooksRepo.GetQueuedEvents(ctx, 10); len(queued) != 0 {
+		t.Errorf("expected event dispatched removed from the outbox, got %+v", queued)
+	}
+	due, err := webhooksRepo.GetDueDeliveries(ctx, time.Now().UTC(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 1 || due[0].EventID != stopped.ID {
+		t.Errorf("expected delivery of the activity event, got %+v", due)
+	}
+}
+
+func TestDeliverDueSlowWebhook(t *testing.T) {
+	var (
+		ctx          = context.Background()
+		webhooksRepo = repository.NewMemoryWebhooksRepository()
+		usersService = NewUsersService(repository.NewMemoryUsersRepository(), repository.NewMemoryTeamsRepository())
+		slow         = make(chan struct{})
+		delivered    = make(chan int64, 10)
+		sender       = senderFunc(func(webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+			if strings.HasSuffix(webhook.URL, "/slow") {
+				<-slow
+			}
+			delivered <- delivery.WebhookID
+			return 200, nil
+		})
+		webhooksService = NewWebhooksService(repository.NewMemoryTransactor(), webhooksRepo, usersService, sender)
+	)
+
+	admin, err := usersService.Identify(ctx, models.DefaultUserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]int64, 0, 2)
+	for _, address := range []string{"https://example.com/slow", "https://example.com/fast"} {
+		created, err := webhooksService.CreateWebhook(admin, &types.WebhookInput{URL: address})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, created.ID)
+	}
+	for id := int64(1); id <= 3; id++ {
+		if err = webhooksRepo.QueueEvent(ctx, &models.WebhookEvent{EventID: id, EventType: broker.TypeStarted, OwnerID: models.DefaultUserID, Data: "{}"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = webhooksService.Dispatch(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan int)
+	go func() {
+		attempted, err := webhooksService.DeliverDue(ctx, time.Now().UTC())
+		if err != nil {
+			t.Error(err)
+		}
+		done <- attempted
+	}()
+
+	// the fast webhook gets its deliveries while the slow one holds its worker
+	for i := 0; i < 3; i++ {
+		select {
+		case id := <-delivered:
+			if id != ids[1] {
+				t.Fatalf("unexpected delivery to webhook %d before the slow webhook answered", id)
+			}
+		case <-time.After(time.Second * 5):
+			t.Fatal("expected deliveries to the fast webhook not waiting for the slow one")
+		}
+	}
+	close(slow)
+	if attempted := <-done; attempted != 6 {
+		t.Errorf("expected every delivery attempted, got %d", attempted)
+	}
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"github.com/ungame/command-time-track/app/ioext"
+	"github.com/ungame/command-time-track/app/repository"
+	"github.com/ungame/command-time-track/app/service"
+	"github.com/ungame/command-time-track/app/types"
+	"log"
+)
+
+const tokenUsage = "usage: main [flags] token <user> [name]"
+
+// Token prints a new api token of the user, letting the first token be created when the server requires them.
+func Token(args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		log.Fatalln(tokenUsage)
+	}
+
+	if store == StoreMemory {
+		log.Fatalln("tokens are not supported by the memory storage backend")
+	}
+
+	conn := openStore()
+	defer ioext.Close(conn)
+
+	ctx := context.Background()
+
+	user, err := repository.NewUsersRepository(conn).GetByName(ctx, args[0])
+	if err != nil {
+		log.Fatalln("unable to find user:", args[0], err.Error())
+	}
+
+	name := "cli"
+	if len(args) == 2 {
+		name = args[1]
+	}
+
+	tokensService := service.NewTokensService(repository.NewTokensRepository(conn))
+	output, err := tokensService.CreateToken(repository.WithOwner(ctx, user.ID), &types.TokenInput{Name: name})
+	if err != nil {
+		log.Fatalln("unable to create token:", err.Error())
+	}
+	fmt.Println(output.Token)
+}
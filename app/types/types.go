@@ -1,18 +1,59 @@
 package types
 
+import (
+	"encoding/json"
+	"time"
+)
+
+// TimeLayout is the layout of the times of the outputs, written with time.Time.String.
+const TimeLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
+
 type StartActivityInput struct {
-	Category    string `json:"category"`
-	Description string `json:"description"`
+	Category    string   `json:"category"`
+	Description string   `json:"description"`
+	StartedAt   string   `json:"started_at,omitempty"`
+	FinishedAt  string   `json:"finished_at,omitempty"`
+	Resolve     string   `json:"resolve,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	ProjectID   *int64   `json:"project_id,omitempty"`
+	HourlyRate  *float64 `json:"hourly_rate,omitempty"`
 }
 
 type ActivityOutput struct {
-	ID          int64   `json:"id"`
-	Category    string  `json:"category"`
-	Description string  `json:"description"`
-	Status      string  `json:"status"`
-	StartedAt   string  `json:"started_at"`
-	UpdatedAt   string  `json:"updated_at"`
-	FinishedAt  *string `json:"finished_at"`
+	ID             int64             `json:"id"`
+	Category       string            `json:"category"`
+	Description    string            `json:"description"`
+	Status         string            `json:"status"`
+	StartedAt      string            `json:"started_at"`
+	UpdatedAt      string            `json:"updated_at"`
+	FinishedAt     *string           `json:"finished_at"`
+	Duration       int64             `json:"duration"`
+	Intervals      []*IntervalOutput `json:"intervals"`
+	Tags           []string          `json:"tags"`
+	ProjectID      *int64            `json:"project_id"`
+	HourlyRate     *float64          `json:"hourly_rate"`
+	BillableAmount *float64          `json:"billable_amount"`
+	InvoiceID      *int64            `json:"invoice_id"`
+	DeletedAt      *string           `json:"deleted_at,omitempty"`
+	// the times above as time values, for the outputs not written as json, unset on the outputs read from it
+	StartedTime  time.Time  `json:"-"`
+	UpdatedTime  time.Time  `json:"-"`
+	FinishedTime *time.Time `json:"-"`
+	DeletedTime  *time.Time `json:"-"`
+}
+
+type StartActivityOutput struct {
+	*ActivityOutput
+	Stopped []*ActivityOutput `json:"stopped"`
+}
+
+type IntervalOutput struct {
+	ID         int64   `json:"id"`
+	StartedAt  string  `json:"started_at"`
+	FinishedAt *string `json:"finished_at"`
+	// the times above as time values, like the ones of ActivityOutput
+	StartedTime  time.Time  `json:"-"`
+	FinishedTime *time.Time `json:"-"`
 }
 
 type UpdateActivityInput struct {
@@ -21,6 +62,123 @@ type UpdateActivityInput struct {
 	Description string `json:"description"`
 }
 
+type UpdateActivityProjectInput struct {
+	ID        int64  `json:"id"`
+	ProjectID *int64 `json:"project_id"`
+}
+
+type UpdateActivityRateInput struct {
+	ID         int64    `json:"id"`
+	HourlyRate *float64 `json:"hourly_rate"`
+}
+
+type TagActivityInput struct {
+	ID   int64    `json:"id"`
+	Tags []string `json:"tags"`
+}
+
+type SearchActivitiesInput struct {
+	Term   string   `json:"term"`
+	Tags   []string `json:"tags"`
+	UserID int64    `json:"user_id"`
+}
+
+type ListActivitiesInput struct {
+	From      string   `json:"from"`
+	To        string   `json:"to"`
+	Category  string   `json:"category"`
+	ProjectID int64    `json:"project_id"`
+	Tags      []string `json:"tags"`
+	Status    string   `json:"status"`
+	Sort      string   `json:"sort"`
+	Order     string   `json:"order"`
+	Limit     int      `json:"limit"`
+	Cursor    string   `json:"cursor"`
+	UserID    int64    `json:"user_id"`
+}
+
+type ListActivitiesOutput struct {
+	Activities []*ActivityOutput `json:"activities"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+type ExportActivityOutput struct {
+	ID             int64    `json:"id"`
+	Category       string   `json:"category"`
+	Description    string   `json:"description"`
+	Status         string   `json:"status"`
+	StartedAt      string   `json:"started_at"`
+	FinishedAt     *string  `json:"finished_at"`
+	UpdatedAt      string   `json:"updated_at"`
+	Duration       int64    `json:"duration_seconds"`
+	DurationHours  float64  `json:"duration_hours"`
+	Tags           []string `json:"tags"`
+	ProjectID      *int64   `json:"project_id"`
+	HourlyRate     *float64 `json:"hourly_rate"`
+	BillableAmount *float64 `json:"billable_amount"`
+	InvoiceID      *int64   `json:"invoice_id"`
+}
+
+type ImportActivitiesInput struct {
+	TimeZone   string `json:"tz"`
+	DryRun     bool   `json:"dry_run"`
+	Resolve    string `json:"resolve"`
+	DateFormat string `json:"date_format"`
+}
+
+type ImportActivitiesOutput struct {
+	Source     string             `json:"source"`
+	DryRun     bool               `json:"dry_run"`
+	Imported   int                `json:"imported"`
+	IDs        []int64            `json:"ids"`
+	Duplicates []*ImportRowOutput `json:"duplicates"`
+	Rejected   []*ImportRowOutput `json:"rejected"`
+}
+
+type ImportRowOutput struct {
+	Row        int    `json:"row"`
+	Reason     string `json:"reason"`
+	ActivityID int64  `json:"activity_id,omitempty"`
+}
+
+type SummaryReportInput struct {
+	From           string   `json:"from"`
+	To             string   `json:"to"`
+	GroupBy        string   `json:"group_by"`
+	TimeZone       string   `json:"tz"`
+	IncludeRunning bool     `json:"include_running"`
+	Tags           []string `json:"tags"`
+	UserID         int64    `json:"user_id"`
+}
+
+type SummaryReportOutput struct {
+	From     string                 `json:"from"`
+	To       string                 `json:"to"`
+	TimeZone string                 `json:"tz"`
+	GroupBy  string                 `json:"group_by"`
+	Total    int64                  `json:"total"`
+	Amount   float64                `json:"billable_amount"`
+	Buckets  []*SummaryBucketOutput `json:"buckets"`
+}
+
+type SummaryBucketOutput struct {
+	Key        string           `json:"key"`
+	From       string           `json:"from,omitempty"`
+	To         string           `json:"to,omitempty"`
+	Duration   int64            `json:"duration"`
+	Amount     float64          `json:"billable_amount"`
+	Activities int              `json:"activities"`
+	Categories map[string]int64 `json:"categories,omitempty"`
+}
+
+// ConflictOutput is the error output of the conflicts between activities, listing the conflicting ones.
+type ConflictOutput struct {
+	Code      string  `json:"code"`
+	Message   string  `json:"message"`
+	Err       string  `json:"error"`
+	Conflicts []int64 `json:"conflicts"`
+}
+
 type GetActivityInput struct {
 	ID int64 `json:"id"`
 }
@@ -28,3 +186,202 @@ type GetActivityInput struct {
 type DeleteActivityInput struct {
 	ID int64 `json:"id"`
 }
+
+type RestoreActivityInput struct {
+	ID int64 `json:"id"`
+}
+
+type ListTrashInput struct {
+	UserID int64 `json:"user_id,omitempty"`
+}
+
+type ClientInput struct {
+	ID         int64    `json:"id"`
+	Name       string   `json:"name"`
+	HourlyRate *float64 `json:"hourly_rate"`
+}
+
+type ClientOutput struct {
+	ID         int64    `json:"id"`
+	Name       string   `json:"name"`
+	HourlyRate *float64 `json:"hourly_rate"`
+	CreatedAt  string   `json:"created_at"`
+	UpdatedAt  string   `json:"updated_at"`
+}
+
+type ProjectInput struct {
+	ID         int64    `json:"id"`
+	ClientID   int64    `json:"client_id"`
+	Name       string   `json:"name"`
+	HourlyRate *float64 `json:"hourly_rate"`
+}
+
+type ProjectOutput struct {
+	ID         int64    `json:"id"`
+	ClientID   int64    `json:"client_id"`
+	Name       string   `json:"name"`
+	HourlyRate *float64 `json:"hourly_rate"`
+	CreatedAt  string   `json:"created_at"`
+	UpdatedAt  string   `json:"updated_at"`
+}
+
+type ListProjectsInput struct {
+	ClientID int64 `json:"client_id"`
+}
+
+type CreateInvoiceInput struct {
+	Categories []string `json:"categories"`
+	Client     string   `json:"client"`
+	From       string   `json:"from"`
+	To         string   `json:"to"`
+	TimeZone   string   `json:"tz"`
+	HourlyRate float64  `json:"hourly_rate"`
+	Rounding   int      `json:"rounding"`
+}
+
+type InvoiceOutput struct {
+	ID         int64                `json:"id"`
+	Client     string               `json:"client,omitempty"`
+	From       string               `json:"from"`
+	To         string               `json:"to"`
+	HourlyRate float64              `json:"hourly_rate"`
+	Rounding   int                  `json:"rounding"`
+	Duration   int64                `json:"duration"`
+	Total      float64              `json:"total"`
+	Items      []*InvoiceItemOutput `json:"items"`
+	CreatedAt  string               `json:"created_at"`
+}
+
+type InvoiceItemOutput struct {
+	Category      string  `json:"category"`
+	Activities    int     `json:"activities"`
+	Duration      int64   `json:"duration"`
+	DurationHours float64 `json:"duration_hours"`
+	Amount        float64 `json:"amount"`
+}
+
+type UserInput struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+type UserRoleInput struct {
+	ID   int64  `json:"id"`
+	Role string `json:"role"`
+}
+
+type UserOutput struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	CreatedAt string `json:"created_at"`
+}
+
+type TeamInput struct {
+	Name string `json:"name"`
+}
+
+type TeamMemberInput struct {
+	TeamID int64 `json:"team_id"`
+	UserID int64 `json:"user_id"`
+}
+
+type TeamOutput struct {
+	ID        int64   `json:"id"`
+	Name      string  `json:"name"`
+	Members   []int64 `json:"members"`
+	CreatedAt string  `json:"created_at"`
+}
+
+// ActivityEventOutput is an entry of the history of an activity, before and after are the activity
+// as returned by the api, null when it did not exist.
+type ActivityEventOutput struct {
+	ID         int64           `json:"id"`
+	ActivityID int64           `json:"activity_id"`
+	ActorID    int64           `json:"actor_id"`
+	Action     string          `json:"action"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	CreatedAt  string          `json:"created_at"`
+}
+
+// ActivityStreamOutput is the data of the events of the activities stream, activity is the activity
+// after the change, before it when purged.
+type ActivityStreamOutput struct {
+	ActivityID int64           `json:"activity_id"`
+	ActorID    int64           `json:"actor_id"`
+	Action     string          `json:"action"`
+	Activity   json.RawMessage `json:"activity"`
+	CreatedAt  string          `json:"created_at"`
+}
+
+type TokenInput struct {
+	Name string `json:"name"`
+}
+
+type TokenOutput struct {
+	ID        int64   `json:"id"`
+	Name      string  `json:"name"`
+	Prefix    string  `json:"prefix"`
+	CreatedAt string  `json:"created_at"`
+	RevokedAt *string `json:"revoked_at"`
+}
+
+// CreateTokenOutput holds the secret of a new token, it is never returned again.
+type CreateTokenOutput struct {
+	TokenOutput
+	Token string `json:"token"`
+}
+
+// WebhookInput creates a webhook, events filters the types of the events delivered and the secret
+// is generated when not given.
+type WebhookInput struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret,omitempty"`
+}
+
+// UpdateWebhookInput replaces the url and events of a webhook, active pauses or resumes its deliveries when given.
+type UpdateWebhookInput struct {
+	ID     int64    `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+type WebhookOutput struct {
+	ID        int64    `json:"id"`
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`
+	Active    bool     `json:"active"`
+	CreatedAt string   `json:"created_at"`
+	UpdatedAt string   `json:"updated_at"`
+}
+
+// CreateWebhookOutput holds the secret of a new webhook, it is never returned again.
+type CreateWebhookOutput struct {
+	WebhookOutput
+	Secret string `json:"secret"`
+}
+
+// WebhookPayload is the body posted to the webhooks, data is the data of the event on the activities stream.
+type WebhookPayload struct {
+	EventID int64           `json:"event_id"`
+	Event   string          `json:"event"`
+	Data    json.RawMessage `json:"data"`
+}
+
+type WebhookDeliveryOutput struct {
+	ID             int64           `json:"id"`
+	WebhookID      int64           `json:"webhook_id"`
+	EventID        int64           `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus *int            `json:"response_status"`
+	LastError      *string         `json:"last_error"`
+	NextAttemptAt  *string         `json:"next_attempt_at"`
+	CreatedAt      string          `json:"created_at"`
+	UpdatedAt      string          `json:"updated_at"`
+}
@@ -0,0 +1,72 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"syscall"
+)
+
+// Option configures the webhook client.
+type Option func(c *Client)
+
+// AllowPrivate lets the webhooks post to loopback, link-local and private addresses, like services next to the
+// server. Leave it off when users can't be trusted with requests made from the network of the server.
+func AllowPrivate() Option {
+	return func(c *Client) {
+		c.allowPrivate = true
+	}
+}
+
+// public tells whether the address is reachable from outside the network of the server.
+func public(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsUnspecified()
+}
+
+func (c *Client) checkIP(ip net.IP) error {
+	if !c.allowPrivate && !public(ip) {
+		return fmt.Errorf("webhook target not allowed: %s is a loopback, link-local or private address", ip)
+	}
+	return nil
+}
+
+// Check fails when the client refuses to post to the url, a host resolving to a loopback, link-local or private
+// address unless allowed. Hosts that don't resolve pass, every connection is checked again when sending.
+func (c *Client) Check(ctx context.Context, address string) error {
+	if c.allowPrivate {
+		return nil
+	}
+	u, err := url.Parse(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil {
+		return c.checkIP(ip)
+	}
+	addresses, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return nil
+	}
+	for _, address := range addresses {
+		if err = c.checkIP(address.IP); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// control checks the address of every connection before it is made, so neither redirects nor hosts resolving
+// differently since the url was checked reach the addresses refused.
+func (c *Client) control(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("webhook target not allowed: %s is not an ip address", host)
+	}
+	return c.checkIP(ip)
+}
@@ -0,0 +1,84 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/ioext"
+	"github.com/ungame/command-time-track/app/models"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultTimeout is how long a webhook has to answer a delivery.
+const DefaultTimeout = time.Second * 10
+
+// SignaturePrefix starts the signature header, naming the algorithm of the signature.
+const SignaturePrefix = "sha256="
+
+// Sign returns the signature of the body sent in the signature header, the hex HMAC-SHA256 of the body
+// keyed with the secret of the webhook.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return SignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify tells whether the signature is the signature of the body with the secret.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Client posts the deliveries to the webhooks, refusing loopback, link-local and private addresses unless allowed.
+type Client struct {
+	client       *http.Client
+	allowPrivate bool
+}
+
+func NewClient(timeout time.Duration, options ...Option) *Client {
+	c := new(Client)
+	for _, option := range options {
+		option(c)
+	}
+	dialer := &net.Dialer{Timeout: timeout, Control: c.control}
+	c.client = &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: timeout},
+	}
+	return c
+}
+
+// Send posts the payload of the delivery to the webhook, signed with its secret, returning the status of
+// the response. Responses other than 2xx fail.
+func (c *Client) Send(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set(httpext.HeaderContentType, httpext.MimeJson)
+	request.Header.Set(httpext.HeaderWebhookEvent, delivery.EventType)
+	request.Header.Set(httpext.HeaderWebhookDelivery, strconv.FormatInt(delivery.ID, 10))
+	request.Header.Set(httpext.HeaderWebhookSignature, Sign(webhook.Secret, body))
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer ioext.Close(response.Body)
+	// drains the body to reuse the connection
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(response.Body, 1<<16))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("unexpected status: %s", response.Status)
+	}
+	return response.StatusCode, nil
+}
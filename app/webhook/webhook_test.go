@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"context"
+	"github.com/ungame/command-time-track/app/httpext"
+	"github.com/ungame/command-time-track/app/models"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientSend(t *testing.T) {
+	var (
+		status   = http.StatusNoContent
+		received *http.Request
+		body     []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	var (
+		client   = NewClient(time.Second, AllowPrivate())
+		webhook  = &models.Webhook{ID: 1, URL: server.URL, Secret: "whsec_test", Active: true}
+		delivery = &models.WebhookDelivery{ID: 7, WebhookID: 1, EventID: 3, EventType: "started", Payload: `{"event_id":3,"event":"started","data":{}}`}
+	)
+
+	got, err := client.Send(context.Background(), webhook, delivery)
+	if err != nil || got != http.StatusNoContent {
+		t.Fatalf("unexpected send: status=%d, err=%v", got, err)
+	}
+	if received.Method != http.MethodPost || string(body) != delivery.Payload {
+		t.Errorf("unexpected request: %s %s", received.Method, body)
+	}
+	if received.Header.Get(httpext.HeaderWebhookEvent) != "started" || received.Header.Get(httpext.HeaderWebhookDelivery) != "7" {
+		t.Errorf("unexpected headers: %v", received.Header)
+	}
+	signature := received.Header.Get(httpext.HeaderWebhookSignature)
+	if !Verify("whsec_test", body, signature) {
+		t.Errorf("unexpected signature: %s", signature)
+	}
+	if Verify("whsec_other", body, signature) || Verify("whsec_test", append(body, ' '), signature) {
+		t.Errorf("expected signature bound to the secret and the body")
+	}
+
+	status = http.StatusBadGateway
+	if got, err = client.Send(context.Background(), webhook, delivery); err == nil || got != http.StatusBadGateway {
+		t.Errorf("expected error status failed, got status=%d, err=%v", got, err)
+	}
+
+	server.Close()
+	if got, err = client.Send(context.Background(), webhook, delivery); err == nil || got != 0 {
+		t.Errorf("expected unreachable webhook failed, got status=%d, err=%v", got, err)
+	}
+}
+
+func TestClientPrivate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	var (
+		ctx      = context.Background()
+		client   = NewClient(time.Second)
+		webhook  = &models.Webhook{ID: 1, URL: server.URL, Secret: "whsec_test", Active: true}
+		delivery = &models.WebhookDelivery{ID: 7, WebhookID: 1, EventID: 3, EventType: "started", Payload: "{}"}
+	)
+
+	for _, address := range []string{server.URL, "http://localhost/hook", "http://10.0.0.1/hook", "http://192.168.1.1/hook", "http://169.254.169.254/latest", "http://[::1]/hook", "http://0.0.0.0/hook"} {
+		if err := client.Check(ctx, address); err == nil {
+			t.Errorf("expected %s refused", address)
+		}
+	}
+	if err := client.Check(ctx, "https://93.184.216.34/hook"); err != nil {
+		t.Errorf("expected public address allowed, got %v", err)
+	}
+	if _, err := client.Send(ctx, webhook, delivery); err == nil {
+		t.Errorf("expected delivery to a loopback address refused")
+	}
+	if err := NewClient(time.Second, AllowPrivate()).Check(ctx, server.URL); err != nil {
+		t.Errorf("expected private addresses allowed, got %v", err)
+	}
+}
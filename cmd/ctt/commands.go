@@ -0,0 +1,578 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/ungame/command-time-track/app/client"
+	"github.com/ungame/command-time-track/app/types"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	statusStarted = "STARTED"
+	statusPaused  = "PAUSED"
+)
+
+type cli struct {
+	activities client.ActivitiesClient
+	tokens     client.TokensClient
+	out        io.Writer
+	json       bool
+}
+
+type command func(ctx context.Context, c *cli, args []string) error
+
+var commands = map[string]command{
+	"start":   start,
+	"add":     add,
+	"stop":    stop,
+	"pause":   pause,
+	"resume":  resume,
+	"status":  status,
+	"ls":      list,
+	"search":  search,
+	"edit":    edit,
+	"tag":     tag,
+	"untag":   untag,
+	"rm":      remove,
+	"restore": restore,
+	"trash":   trash,
+	"history": history,
+	"import":  importCsv,
+	"token":   tokens,
+}
+
+func (c *cli) flags(name, usage string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.BoolVar(&c.json, "json", c.json, "print output as json")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: ctt %s %s\n", name, usage)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+func parseID(s string) (int64, error) {
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid activity id: %s", s)
+	}
+	return id, nil
+}
+
+// splitTags splits comma separated tags given on the command line.
+func splitTags(s string) []string {
+	tags := make([]string, 0)
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// projectOf returns the project id given on the command line, nil when not given.
+func projectOf(id int64) *int64 {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
+
+func start(ctx context.Context, c *cli, args []string) error {
+	var (
+		tags    string
+		project int64
+	)
+
+	flags := c.flags("start", "[-tags t1,t2] [-project id] <category> <description>")
+	flags.StringVar(&tags, "tags", "", "comma separated activity tags")
+	flags.Int64Var(&project, "project", 0, "id of the activity project")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 2 {
+		flags.Usage()
+		return errors.New("category and description are required")
+	}
+	output, err := c.activities.StartActivity(ctx, &types.StartActivityInput{
+		Category:    flags.Arg(0),
+		Description: strings.Join(flags.Args()[1:], " "),
+		Tags:        splitTags(tags),
+		ProjectID:   projectOf(project),
+	})
+	if err != nil {
+		return err
+	}
+	return c.printStart(output)
+}
+
+func add(ctx context.Context, c *cli, args []string) error {
+	var (
+		from, to, resolve, tags string
+		project                 int64
+	)
+
+	flags := c.flags("add", "-from <time> -to <time> [-resolve trim|split] [-tags t1,t2] [-project id] <category> <description>")
+	flags.StringVar(&from, "from", "", "activity start, as RFC 3339, \"2006-01-02 15:04\" or \"15:04\" today")
+	flags.StringVar(&to, "to", "", "activity finish, same formats as -from")
+	flags.StringVar(&resolve, "resolve", "", "trim or split overlapping activities instead of failing")
+	flags.StringVar(&tags, "tags", "", "comma separated activity tags")
+	flags.Int64Var(&project, "project", 0, "id of the activity project")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 2 || from == "" || to == "" {
+		flags.Usage()
+		return errors.New("from, to, category and description are required")
+	}
+
+	startedAt, err := parseLocalTime(from, time.Now())
+	if err != nil {
+		return err
+	}
+	finishedAt, err := parseLocalTime(to, time.Now())
+	if err != nil {
+		return err
+	}
+
+	output, err := c.activities.CreateManualActivity(ctx, &types.StartActivityInput{
+		Category:    flags.Arg(0),
+		Description: strings.Join(flags.Args()[1:], " "),
+		StartedAt:   startedAt.Format(time.RFC3339),
+		FinishedAt:  finishedAt.Format(time.RFC3339),
+		Resolve:     resolve,
+		Tags:        splitTags(tags),
+		ProjectID:   projectOf(project),
+	})
+	if err != nil {
+		return err
+	}
+	return c.printActivity(output)
+}
+
+// parseLocalTime parses times given on the command line, clock times are taken as today in the local time zone.
+func parseLocalTime(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04", s, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("15:04", s, time.Local); err == nil {
+		year, month, day := now.In(time.Local).Date()
+		return time.Date(year, month, day, t.Hour(), t.Minute(), 0, 0, time.Local), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time: %s", s)
+}
+
+func stop(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("stop", "[id]")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var ids []int64
+
+	if flags.NArg() > 0 {
+		id, err := parseID(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	} else {
+		running, err := c.withStatus(ctx, statusStarted, statusPaused)
+		if err != nil {
+			return err
+		}
+		if len(running) == 0 {
+			return errors.New("no running activity")
+		}
+		for _, activity := range running {
+			ids = append(ids, activity.ID)
+		}
+	}
+
+	stopped := make([]*types.ActivityOutput, 0, len(ids))
+	for _, id := range ids {
+		output, err := c.activities.StopActivity(ctx, id)
+		if err != nil {
+			return err
+		}
+		stopped = append(stopped, output)
+	}
+	return c.printActivities(stopped...)
+}
+
+func pause(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("pause", "[id]")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	id, err := c.targetID(ctx, flags, statusStarted)
+	if err != nil {
+		return err
+	}
+	output, err := c.activities.PauseActivity(ctx, id)
+	if err != nil {
+		return err
+	}
+	return c.printActivity(output)
+}
+
+func resume(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("resume", "[id]")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	id, err := c.targetID(ctx, flags, statusPaused)
+	if err != nil {
+		return err
+	}
+	output, err := c.activities.ResumeActivity(ctx, id)
+	if err != nil {
+		return err
+	}
+	return c.printActivity(output)
+}
+
+func status(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("status", "")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	running, err := c.withStatus(ctx, statusStarted, statusPaused)
+	if err != nil {
+		return err
+	}
+	if len(running) == 0 && !c.json {
+		_, err = fmt.Fprintln(c.out, "No running activity")
+		return err
+	}
+	return c.printActivities(running...)
+}
+
+func list(ctx context.Context, c *cli, args []string) error {
+	var (
+		input = new(types.ListActivitiesInput)
+		tags  string
+		all   bool
+	)
+
+	flags := c.flags("ls", "[flags]")
+	flags.StringVar(&input.From, "from", "", "list activities running after this date or RFC 3339 time")
+	flags.StringVar(&input.To, "to", "", "list activities running before this date or RFC 3339 time")
+	flags.StringVar(&input.Category, "category", "", "filter by category")
+	flags.StringVar(&tags, "tag", "", "filter by comma separated tags, activities must have all of them")
+	flags.StringVar(&input.Status, "status", "", "filter by status: started, paused or finished")
+	flags.StringVar(&input.Sort, "sort", "", "sort by id, started_at, updated_at or category")
+	flags.StringVar(&input.Order, "order", "", "sort order: asc or desc")
+	flags.IntVar(&input.Limit, "limit", 0, "set page size")
+	flags.StringVar(&input.Cursor, "cursor", "", "continue from a previous page")
+	flags.BoolVar(&all, "all", false, "fetch every page")
+	flags.Int64Var(&input.UserID, "user-id", 0, "list activities of a teammate, admins list anyone's")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	input.Tags = splitTags(tags)
+
+	activities := make([]*types.ActivityOutput, 0)
+	for {
+		output, err := c.activities.ListActivities(ctx, input)
+		if err != nil {
+			return err
+		}
+		activities = append(activities, output.Activities...)
+		if output.NextCursor == "" {
+			break
+		}
+		if !all {
+			fmt.Fprintf(os.Stderr, "More activities available, use -cursor %s or -all\n", output.NextCursor)
+			break
+		}
+		input.Cursor = output.NextCursor
+	}
+	return c.printActivities(activities...)
+}
+
+func search(ctx context.Context, c *cli, args []string) error {
+	var tags string
+
+	flags := c.flags("search", "[-tag t1,t2] <term>")
+	flags.StringVar(&tags, "tag", "", "filter by comma separated tags, activities must have all of them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("search term is required")
+	}
+	activities, err := c.activities.SearchActivities(ctx, &types.SearchActivitiesInput{
+		Term: strings.Join(flags.Args(), " "),
+		Tags: splitTags(tags),
+	})
+	if err != nil {
+		return err
+	}
+	return c.printActivities(activities...)
+}
+
+func edit(ctx context.Context, c *cli, args []string) error {
+	var category, description string
+
+	flags := c.flags("edit", "<id> [-category category] [-description description]")
+	flags.StringVar(&category, "category", "", "set activity category")
+	flags.StringVar(&description, "description", "", "set activity description")
+
+	if len(args) == 0 {
+		flags.Usage()
+		return errors.New("activity id is required")
+	}
+
+	id, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+	if err = flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if category == "" && description == "" {
+		flags.Usage()
+		return errors.New("nothing to edit")
+	}
+
+	var output *types.ActivityOutput
+
+	if category != "" {
+		if output, err = c.activities.UpdateActivityCategory(ctx, id, category); err != nil {
+			return err
+		}
+	}
+	if description != "" {
+		if output, err = c.activities.UpdateActivityDescription(ctx, id, description); err != nil {
+			return err
+		}
+	}
+	return c.printActivity(output)
+}
+
+func tag(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("tag", "<id> <tag>...")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 2 {
+		flags.Usage()
+		return errors.New("activity id and tags are required")
+	}
+	id, err := parseID(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	output, err := c.activities.TagActivity(ctx, id, flags.Args()[1:]...)
+	if err != nil {
+		return err
+	}
+	return c.printActivity(output)
+}
+
+func untag(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("untag", "<id> <tag>...")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 2 {
+		flags.Usage()
+		return errors.New("activity id and tags are required")
+	}
+	id, err := parseID(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	var output *types.ActivityOutput
+	for _, tag := range flags.Args()[1:] {
+		if output, err = c.activities.UntagActivity(ctx, id, tag); err != nil {
+			return err
+		}
+	}
+	return c.printActivity(output)
+}
+
+func remove(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("rm", "<id>")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("activity id is required")
+	}
+	id, err := parseID(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err = c.activities.DeleteActivity(ctx, id); err != nil {
+		return err
+	}
+	if c.json {
+		return c.printJson(map[string]int64{"id": id})
+	}
+	_, err = fmt.Fprintf(c.out, "Activity %d moved to the trash\n", id)
+	return err
+}
+
+func restore(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("restore", "<id>")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("activity id is required")
+	}
+	id, err := parseID(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	activity, err := c.activities.RestoreActivity(ctx, id)
+	if err != nil {
+		return err
+	}
+	return c.printActivity(activity)
+}
+
+func trash(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("trash", "")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	activities, err := c.activities.ListTrash(ctx)
+	if err != nil {
+		return err
+	}
+	if len(activities) == 0 && !c.json {
+		_, err = fmt.Fprintln(c.out, "Trash is empty")
+		return err
+	}
+	return c.printActivities(activities...)
+}
+
+func history(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("history", "<id>")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("activity id is required")
+	}
+	id, err := parseID(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	events, err := c.activities.GetActivityHistory(ctx, id)
+	if err != nil {
+		return err
+	}
+	return c.printHistory(events)
+}
+
+func importCsv(ctx context.Context, c *cli, args []string) error {
+	input := new(types.ImportActivitiesInput)
+
+	flags := c.flags("import", "[-tz zone] [-date-format format] [-dry-run] <file.csv|->")
+	flags.StringVar(&input.TimeZone, "tz", "", "time zone of the exported times, defaults to the server time zone")
+	flags.BoolVar(&input.DryRun, "dry-run", false, "report what would be imported without creating activities")
+	flags.StringVar(&input.Resolve, "resolve", "", "trim or split overlapping activities instead of rejecting rows")
+	flags.StringVar(&input.DateFormat, "date-format", "", "mm/dd/yyyy or dd/mm/yyyy, order of the dates with slashes, detected from the file by default")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("csv file is required")
+	}
+
+	var file io.Reader = os.Stdin
+	if name := flags.Arg(0); name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		file = f
+	}
+
+	output, err := c.activities.ImportActivities(ctx, input, file)
+	if err != nil {
+		return err
+	}
+	return c.printImport(output)
+}
+
+func tokens(ctx context.Context, c *cli, args []string) error {
+	flags := c.flags("token", "create <name> | ls | revoke <id>")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	switch {
+	case flags.Arg(0) == "create" && flags.NArg() == 2:
+		output, err := c.tokens.CreateToken(ctx, flags.Arg(1))
+		if err != nil {
+			return err
+		}
+		return c.printNewToken(output)
+	case flags.Arg(0) == "ls" && flags.NArg() == 1:
+		output, err := c.tokens.ListTokens(ctx)
+		if err != nil {
+			return err
+		}
+		return c.printTokens(output)
+	case flags.Arg(0) == "revoke" && flags.NArg() == 2:
+		id, err := strconv.ParseInt(flags.Arg(1), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid token id: %s", flags.Arg(1))
+		}
+		if err = c.tokens.RevokeToken(ctx, id); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(c.out, "Token %d revoked\n", id)
+		return err
+	default:
+		flags.Usage()
+		return errors.New("unknown token command")
+	}
+}
+
+func (c *cli) withStatus(ctx context.Context, status ...string) ([]*types.ActivityOutput, error) {
+	matches := make([]*types.ActivityOutput, 0, 1)
+	for _, s := range status {
+		output, err := c.activities.ListActivities(ctx, &types.ListActivitiesInput{Status: s, Sort: "started_at"})
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, output.Activities...)
+	}
+	return matches, nil
+}
+
+// targetID returns the id given as argument or the latest activity with the given status.
+func (c *cli) targetID(ctx context.Context, flags *flag.FlagSet, status string) (int64, error) {
+	if flags.NArg() > 0 {
+		return parseID(flags.Arg(0))
+	}
+	activities, err := c.withStatus(ctx, status)
+	if err != nil {
+		return 0, err
+	}
+	if len(activities) == 0 {
+		return 0, fmt.Errorf("no %s activity", strings.ToLower(status))
+	}
+	return activities[len(activities)-1].ID, nil
+}
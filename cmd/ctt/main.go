@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/ungame/command-time-track/app/client"
+	"os"
+	"os/signal"
+)
+
+const usage = `ctt - command time track
+
+Usage:
+  ctt [flags] <command> [arguments]
+
+Commands:
+  start [-tags t1,t2] [-project id] <category> <description>
+                                    start a new activity, stopping the running one
+  add -from <time> -to <time> [-tags t1,t2] [-project id] <category> <description>
+                                    record a past activity
+  stop [id]                         stop an activity, the running one by default
+  pause [id]                        pause an activity, the running one by default
+  resume [id]                       resume an activity, the last paused one by default
+  status                            show running and paused activities
+  ls [-tag t1,t2]                   list activities
+  search [-tag t1,t2] <term>        search activities by category or description
+  edit <id> [-category c] [-description d]
+                                    change category or description of an activity
+  tag <id> <tag>...                 add tags to an activity
+  untag <id> <tag>...               remove tags from an activity
+  rm <id>                           move an activity to the trash
+  restore <id>                      take an activity out of the trash
+  trash                             list the activities in the trash
+  history <id>                      show the changes made to an activity
+  import [-tz zone] [-date-format format] [-dry-run] <file.csv>
+                                    import a Toggl or Clockify csv export
+  token create <name> | ls | revoke <id>
+                                    manage personal api tokens
+
+Flags:
+`
+
+func main() {
+	var (
+		addr   string
+		token  string
+		asJson bool
+	)
+
+	flags := flag.NewFlagSet("ctt", flag.ExitOnError)
+	flags.StringVar(&addr, "addr", env("CTT_ADDR", client.DefaultAddr), "set server address, defaults to $CTT_ADDR")
+	flags.StringVar(&token, "token", env("CTT_TOKEN", ""), "set personal api token, defaults to $CTT_TOKEN")
+	flags.BoolVar(&asJson, "json", false, "print output as json")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(os.Args[1:])
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[flags.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "ctt: unknown command %q\n\n", flags.Arg(0))
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	options := []client.Option{client.WithToken(token)}
+
+	c := &cli{
+		activities: client.NewActivitiesClient(addr, options...),
+		tokens:     client.NewTokensClient(addr, options...),
+		out:        os.Stdout,
+		json:       asJson,
+	}
+
+	if err := cmd(ctx, c, flags.Args()[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "ctt:", err.Error())
+		os.Exit(1)
+	}
+}
+
+func env(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
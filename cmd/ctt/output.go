@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ungame/command-time-track/app/types"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	displayTimeLayout = "2006-01-02 15:04"
+	maxDescription    = 40
+)
+
+func (c *cli) printJson(data any) error {
+	encoder := json.NewEncoder(c.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+func (c *cli) printActivity(activity *types.ActivityOutput) error {
+	if c.json {
+		return c.printJson(activity)
+	}
+	return c.printTable([]*types.ActivityOutput{activity})
+}
+
+func (c *cli) printActivities(activities ...*types.ActivityOutput) error {
+	if c.json {
+		return c.printJson(activities)
+	}
+	return c.printTable(activities)
+}
+
+func (c *cli) printTable(activities []*types.ActivityOutput) error {
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tCATEGORY\tDESCRIPTION\tSTATUS\tSTARTED\tDURATION\tTAGS")
+	for _, activity := range activities {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			activity.ID,
+			activity.Category,
+			truncate(activity.Description, maxDescription),
+			activity.Status,
+			displayTime(activity.StartedAt),
+			duration(activity),
+			strings.Join(activity.Tags, ","),
+		)
+	}
+	return w.Flush()
+}
+
+func (c *cli) printStart(output *types.StartActivityOutput) error {
+	if c.json {
+		return c.printJson(output)
+	}
+	if err := c.printTable([]*types.ActivityOutput{output.ActivityOutput}); err != nil {
+		return err
+	}
+	if len(output.Stopped) == 0 {
+		return nil
+	}
+	fmt.Fprintln(c.out, "\nStopped:")
+	return c.printTable(output.Stopped)
+}
+
+func (c *cli) printImport(output *types.ImportActivitiesOutput) error {
+	if c.json {
+		return c.printJson(output)
+	}
+	verb := "Imported"
+	if output.DryRun {
+		verb = "Would import"
+	}
+	fmt.Fprintf(c.out, "%s %d activities from %s, %d duplicates, %d rejected\n",
+		verb, output.Imported, output.Source, len(output.Duplicates), len(output.Rejected))
+	if len(output.Duplicates)+len(output.Rejected) == 0 {
+		return nil
+	}
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ROW\tRESULT\tREASON")
+	for _, row := range output.Duplicates {
+		fmt.Fprintf(w, "%d\tduplicate\t%s\n", row.Row, row.Reason)
+	}
+	for _, row := range output.Rejected {
+		fmt.Fprintf(w, "%d\trejected\t%s\n", row.Row, row.Reason)
+	}
+	return w.Flush()
+}
+
+func (c *cli) printNewToken(output *types.CreateTokenOutput) error {
+	if c.json {
+		return c.printJson(output)
+	}
+	_, err := fmt.Fprintf(c.out, "%s\n\nKeep the token safe, it is not shown again. Use it with -token or CTT_TOKEN.\n", output.Token)
+	return err
+}
+
+func (c *cli) printTokens(tokens []*types.TokenOutput) error {
+	if c.json {
+		return c.printJson(tokens)
+	}
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tPREFIX\tCREATED\tREVOKED")
+	for _, token := range tokens {
+		revoked := ""
+		if token.RevokedAt != nil {
+			revoked = displayTime(*token.RevokedAt)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", token.ID, token.Name, token.Prefix, displayTime(token.CreatedAt), revoked)
+	}
+	return w.Flush()
+}
+
+func (c *cli) printHistory(events []*types.ActivityEventOutput) error {
+	if c.json {
+		return c.printJson(events)
+	}
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tWHEN\tUSER\tACTION")
+	for _, event := range events {
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\n", event.ID, displayTime(event.CreatedAt), event.ActorID, event.Action)
+	}
+	return w.Flush()
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-3]) + "..."
+}
+
+func parseTime(s string) (time.Time, bool) {
+	t, err := time.Parse(types.TimeLayout, s)
+	return t, err == nil
+}
+
+func displayTime(s string) string {
+	t, ok := parseTime(s)
+	if !ok {
+		return s
+	}
+	return t.Local().Format(displayTimeLayout)
+}
+
+func duration(activity *types.ActivityOutput) string {
+	return (time.Duration(activity.Duration) * time.Second).String()
+}
@@ -8,7 +8,7 @@ const (
 	defaultHost           = "localhost"
 	defaultPort           = 3306
 	defaultDatabase       = "command_time_track"
-	mysqlStringConnection = "%s:%s@tcp(%s:%d)/%s?parseTime=true"
+	mysqlStringConnection = "%s:%s@tcp(%s:%d)/%s?parseTime=true&clientFoundRows=true"
 )
 
 type config struct {
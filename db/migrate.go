@@ -0,0 +1,248 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DialectMySQL  = "mysql"
+	DialectSQLite = "sqlite"
+)
+
+const (
+	createMigrationsTableQuery = `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP)`
+	selectMigrationsQuery      = `select version, applied_at from schema_migrations order by version`
+	insertMigrationQuery       = `insert into schema_migrations (version, name, applied_at) values (?, ?, ?)`
+	deleteMigrationQuery       = `delete from schema_migrations where version = ?`
+)
+
+//go:embed migrations
+var migrationsFS embed.FS
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+func (s *MigrationStatus) Applied() bool {
+	return s.AppliedAt != nil
+}
+
+type Migrator struct {
+	conn       *sql.DB
+	migrations []*Migration
+}
+
+func NewMigrator(conn *sql.DB, dialect string) (*Migrator, error) {
+	migrations, err := loadMigrations(dialect)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{conn: conn, migrations: migrations}, nil
+}
+
+func loadMigrations(dialect string) ([]*Migration, error) {
+	dir := path.Join("migrations", dialect)
+
+	entries, err := fs.ReadDir(migrationsFS, dir)
+	if err != nil {
+		return nil, fmt.Errorf("unknown migrations dialect %q: %w", dialect, err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+
+	for _, entry := range entries {
+		var (
+			file      = entry.Name()
+			direction string
+		)
+
+		switch {
+		case strings.HasSuffix(file, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(file, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("invalid migration file name: %s", file)
+		}
+
+		name := strings.TrimSuffix(file, "."+direction+".sql")
+
+		prefix, _, _ := strings.Cut(name, "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version: %s", file)
+		}
+
+		content, err := migrationsFS.ReadFile(path.Join(dir, file))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+
+		if migration.Name != name {
+			return nil, fmt.Errorf("duplicated migration version: %d", version)
+		}
+
+		if direction == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %s must have both up and down files", migration.Name)
+		}
+		migrations = append(migrations, migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]*MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status := make([]*MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status = append(status, &MigrationStatus{Migration: *migration, AppliedAt: applied[migration.Version]})
+	}
+	return status, nil
+}
+
+func (m *Migrator) Up(ctx context.Context) ([]*Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	done := make([]*Migration, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		err = m.run(ctx, migration.Up, func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, insertMigrationQuery, migration.Version, migration.Name, time.Now().UTC())
+			return err
+		})
+		if err != nil {
+			return done, fmt.Errorf("unable to apply migration %s: %w", migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		err = m.run(ctx, migration.Down, func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, deleteMigrationQuery, migration.Version)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to revert migration %s: %w", migration.Name, err)
+		}
+		return migration, nil
+	}
+	return nil, nil
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int64]*time.Time, error) {
+	if _, err := m.conn.ExecContext(ctx, createMigrationsTableQuery); err != nil {
+		return nil, err
+	}
+	rows, err := m.conn.QueryContext(ctx, selectMigrationsQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := make(map[int64]*time.Time)
+	for rows.Next() {
+		var (
+			version   int64
+			appliedAt time.Time
+		)
+		if err = rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = &appliedAt
+	}
+	return applied, rows.Err()
+}
+
+func (m *Migrator) run(ctx context.Context, script string, record func(tx *sql.Tx) error) error {
+	tx, err := m.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, statement := range statements(script) {
+		if _, err = tx.ExecContext(ctx, statement); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	if err = record(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func statements(script string) []string {
+	parts := strings.Split(script, ";\n")
+	out := make([]string, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(part), ";"))
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func MustMigrate(conn *sql.DB, dialect string) {
+	migrator, err := NewMigrator(conn, dialect)
+	if err != nil {
+		log.Panicln("unable to load migrations:", err)
+	}
+	if _, err = migrator.Up(context.Background()); err != nil {
+		log.Panicln("unable to migrate database:", err)
+	}
+}
@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrationsDialects(t *testing.T) {
+
+	mysql, err := loadMigrations(DialectMySQL)
+	if err != nil {
+		t.Fatalf("unable to load mysql migrations: %s", err.Error())
+	}
+
+	sqlite, err := loadMigrations(DialectSQLite)
+	if err != nil {
+		t.Fatalf("unable to load sqlite migrations: %s", err.Error())
+	}
+
+	if len(mysql) != len(sqlite) {
+		t.Fatalf("unexpected migrations length: mysql=%d, sqlite=%d", len(mysql), len(sqlite))
+	}
+
+	for i := range mysql {
+		if mysql[i].Version != sqlite[i].Version || mysql[i].Name != sqlite[i].Name {
+			t.Errorf("unexpected migration: mysql=%s, sqlite=%s", mysql[i].Name, sqlite[i].Name)
+		}
+	}
+
+	if _, err = loadMigrations("postgres"); err == nil {
+		t.Errorf("expected error on load unknown dialect migrations")
+	}
+}
+
+func TestMigrator(t *testing.T) {
+
+	var (
+		ctx  = context.Background()
+		conn = NewSQLite(filepath.Join(t.TempDir(), "migrations.db"))
+	)
+	defer conn.Close()
+
+	migrator, err := NewMigrator(conn, DialectSQLite)
+	if err != nil {
+		t.Fatalf("unable to create migrator: %s", err.Error())
+	}
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error on migrate up: %s", err.Error())
+	}
+	if len(applied) != len(migrator.migrations) {
+		t.Errorf("unexpected applied migrations: expected=%d, got=%d", len(migrator.migrations), len(applied))
+	}
+
+	applied, err = migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error on migrate up twice: %s", err.Error())
+	}
+	if len(applied) != 0 {
+		t.Errorf("unexpected applied migrations on migrate up twice: %d", len(applied))
+	}
+
+	status, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error on migrate status: %s", err.Error())
+	}
+	for _, s := range status {
+		if !s.Applied() {
+			t.Errorf("unexpected pending migration: %s", s.Name)
+		}
+	}
+
+	for range migrator.migrations {
+		reverted, err := migrator.Down(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error on migrate down: %s", err.Error())
+		}
+		if reverted == nil {
+			t.Fatalf("expected reverted migration on migrate down")
+		}
+	}
+
+	reverted, err := migrator.Down(ctx)
+	if err != nil || reverted != nil {
+		t.Errorf("unexpected migrate down without applied migrations: migration=%v, err=%v", reverted, err)
+	}
+
+	var count int
+	err = conn.QueryRowContext(ctx, `select count(*) from sqlite_master where type = 'table' and name = 'activities'`).Scan(&count)
+	if err != nil {
+		t.Fatalf("unable to query sqlite schema: %s", err.Error())
+	}
+	if count != 0 {
+		t.Errorf("unexpected activities table after migrate down")
+	}
+
+	if _, err = migrator.Up(ctx); err != nil {
+		t.Errorf("unexpected error on migrate up after down: %s", err.Error())
+	}
+}
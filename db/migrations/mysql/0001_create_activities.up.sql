@@ -9,4 +9,4 @@ CREATE TABLE IF NOT EXISTS activities (
     CONSTRAINT activities_id_pk PRIMARY KEY(id)
 )
 ENGINE = INNODB
-DEFAULT CHARSET = UTF8;
\ No newline at end of file
+DEFAULT CHARSET = UTF8;
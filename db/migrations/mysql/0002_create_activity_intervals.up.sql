@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS activity_intervals (
+    id BIGINT AUTO_INCREMENT,
+    activity_id BIGINT NOT NULL,
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP NULL,
+    CONSTRAINT activity_intervals_id_pk PRIMARY KEY(id),
+    CONSTRAINT activity_intervals_activity_id_fk FOREIGN KEY(activity_id) REFERENCES activities(id) ON DELETE CASCADE
+)
+ENGINE = INNODB
+DEFAULT CHARSET = UTF8;
+
+INSERT INTO activity_intervals (activity_id, started_at, finished_at)
+SELECT id, started_at, finished_at FROM activities;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id BIGINT AUTO_INCREMENT,
+    name VARCHAR(50) NOT NULL,
+    CONSTRAINT tags_id_pk PRIMARY KEY(id),
+    CONSTRAINT tags_name_uk UNIQUE(name)
+)
+ENGINE = INNODB
+DEFAULT CHARSET = UTF8;
+
+CREATE TABLE IF NOT EXISTS activity_tags (
+    activity_id BIGINT NOT NULL,
+    tag_id BIGINT NOT NULL,
+    CONSTRAINT activity_tags_pk PRIMARY KEY(activity_id, tag_id),
+    CONSTRAINT activity_tags_activity_id_fk FOREIGN KEY(activity_id) REFERENCES activities(id) ON DELETE CASCADE,
+    CONSTRAINT activity_tags_tag_id_fk FOREIGN KEY(tag_id) REFERENCES tags(id) ON DELETE CASCADE
+)
+ENGINE = INNODB
+DEFAULT CHARSET = UTF8;
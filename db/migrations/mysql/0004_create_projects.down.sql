@@ -0,0 +1,10 @@
+ALTER TABLE activities
+    DROP FOREIGN KEY activities_project_id_fk;
+
+ALTER TABLE activities
+    DROP COLUMN project_id,
+    DROP COLUMN hourly_rate;
+
+DROP TABLE IF EXISTS projects;
+
+DROP TABLE IF EXISTS clients;
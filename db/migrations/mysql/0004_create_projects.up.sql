@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS clients (
+    id BIGINT AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL,
+    hourly_rate BIGINT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT clients_id_pk PRIMARY KEY(id),
+    CONSTRAINT clients_name_uk UNIQUE(name)
+)
+ENGINE = INNODB
+DEFAULT CHARSET = UTF8;
+
+CREATE TABLE IF NOT EXISTS projects (
+    id BIGINT AUTO_INCREMENT,
+    client_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    hourly_rate BIGINT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT projects_id_pk PRIMARY KEY(id),
+    CONSTRAINT projects_client_id_name_uk UNIQUE(client_id, name),
+    CONSTRAINT projects_client_id_fk FOREIGN KEY(client_id) REFERENCES clients(id)
+)
+ENGINE = INNODB
+DEFAULT CHARSET = UTF8;
+
+ALTER TABLE activities
+    ADD COLUMN project_id BIGINT NULL,
+    ADD COLUMN hourly_rate BIGINT NULL,
+    ADD CONSTRAINT activities_project_id_fk FOREIGN KEY(project_id) REFERENCES projects(id);
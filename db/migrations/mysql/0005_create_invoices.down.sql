@@ -0,0 +1,9 @@
+ALTER TABLE activities
+    DROP FOREIGN KEY activities_invoice_id_fk;
+
+ALTER TABLE activities
+    DROP COLUMN invoice_id;
+
+DROP TABLE IF EXISTS invoice_items;
+
+DROP TABLE IF EXISTS invoices;
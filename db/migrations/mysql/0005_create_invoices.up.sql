@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS invoices (
+    id BIGINT AUTO_INCREMENT,
+    client VARCHAR(100) NOT NULL DEFAULT '',
+    period_from TIMESTAMP NOT NULL,
+    period_to TIMESTAMP NOT NULL,
+    hourly_rate BIGINT NOT NULL,
+    rounding_minutes INT NOT NULL DEFAULT 0,
+    total BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT invoices_id_pk PRIMARY KEY(id)
+)
+ENGINE = INNODB
+DEFAULT CHARSET = UTF8;
+
+CREATE TABLE IF NOT EXISTS invoice_items (
+    id BIGINT AUTO_INCREMENT,
+    invoice_id BIGINT NOT NULL,
+    category VARCHAR(50) NOT NULL,
+    activities INT NOT NULL,
+    duration_seconds BIGINT NOT NULL,
+    amount BIGINT NOT NULL,
+    CONSTRAINT invoice_items_id_pk PRIMARY KEY(id),
+    CONSTRAINT invoice_items_invoice_id_fk FOREIGN KEY(invoice_id) REFERENCES invoices(id) ON DELETE CASCADE
+)
+ENGINE = INNODB
+DEFAULT CHARSET = UTF8;
+
+ALTER TABLE activities
+    ADD COLUMN invoice_id BIGINT NULL,
+    ADD CONSTRAINT activities_invoice_id_fk FOREIGN KEY(invoice_id) REFERENCES invoices(id);
@@ -0,0 +1,7 @@
+ALTER TABLE activities
+    DROP FOREIGN KEY activities_owner_id_fk;
+
+ALTER TABLE activities
+    DROP COLUMN owner_id;
+
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS users (
+    id BIGINT AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT users_id_pk PRIMARY KEY(id),
+    CONSTRAINT users_name_uk UNIQUE(name)
+)
+ENGINE = INNODB
+DEFAULT CHARSET = UTF8;
+
+-- the default user owns the activities recorded before users existed
+INSERT INTO users (id, name) VALUES (1, 'default');
+
+ALTER TABLE activities
+    ADD COLUMN owner_id BIGINT NOT NULL DEFAULT 1,
+    ADD CONSTRAINT activities_owner_id_fk FOREIGN KEY(owner_id) REFERENCES users(id);
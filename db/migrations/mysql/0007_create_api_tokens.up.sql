@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id BIGINT AUTO_INCREMENT,
+    user_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(12) NOT NULL,
+    hash CHAR(64) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP NULL,
+    CONSTRAINT api_tokens_id_pk PRIMARY KEY(id),
+    CONSTRAINT api_tokens_hash_uk UNIQUE(hash),
+    CONSTRAINT api_tokens_user_id_fk FOREIGN KEY(user_id) REFERENCES users(id)
+)
+ENGINE = INNODB
+DEFAULT CHARSET = UTF8;
@@ -0,0 +1,25 @@
+ALTER TABLE users
+    ADD COLUMN role VARCHAR(10) NOT NULL DEFAULT 'member';
+
+-- the default user keeps managing everything, like before roles existed
+UPDATE users SET role = 'admin' WHERE id = 1;
+
+CREATE TABLE IF NOT EXISTS teams (
+    id BIGINT AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT teams_id_pk PRIMARY KEY(id),
+    CONSTRAINT teams_name_uk UNIQUE(name)
+)
+ENGINE = INNODB
+DEFAULT CHARSET = UTF8;
+
+CREATE TABLE IF NOT EXISTS team_members (
+    team_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    CONSTRAINT team_members_pk PRIMARY KEY(team_id, user_id),
+    CONSTRAINT team_members_team_id_fk FOREIGN KEY(team_id) REFERENCES teams(id) ON DELETE CASCADE,
+    CONSTRAINT team_members_user_id_fk FOREIGN KEY(user_id) REFERENCES users(id)
+)
+ENGINE = INNODB
+DEFAULT CHARSET = UTF8;
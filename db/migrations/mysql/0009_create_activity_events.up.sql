@@ -0,0 +1,15 @@
+-- events are never updated nor deleted, the history of an activity outlives it
+CREATE TABLE IF NOT EXISTS activity_events (
+    id BIGINT AUTO_INCREMENT,
+    activity_id BIGINT NOT NULL,
+    owner_id BIGINT NOT NULL,
+    actor_id BIGINT NOT NULL,
+    action VARCHAR(30) NOT NULL,
+    before_value TEXT NULL,
+    after_value TEXT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT activity_events_id_pk PRIMARY KEY(id),
+    INDEX activity_events_activity_id_idx (activity_id)
+)
+ENGINE = INNODB
+DEFAULT CHARSET = UTF8;
@@ -0,0 +1,5 @@
+DELETE FROM activities WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE activities
+    DROP INDEX activities_deleted_at_idx,
+    DROP COLUMN deleted_at;
@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+    id BIGINT AUTO_INCREMENT,
+    owner_id BIGINT NOT NULL,
+    url VARCHAR(2048) NOT NULL,
+    secret VARCHAR(100) NOT NULL,
+    -- comma separated types of the events delivered, empty delivers every event
+    events VARCHAR(255) NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT webhooks_id_pk PRIMARY KEY(id),
+    CONSTRAINT webhooks_owner_id_fk FOREIGN KEY(owner_id) REFERENCES users(id)
+)
+ENGINE = INNODB
+DEFAULT CHARSET = UTF8;
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGINT AUTO_INCREMENT,
+    webhook_id BIGINT NOT NULL,
+    event_id BIGINT NOT NULL,
+    event_type VARCHAR(20) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(10) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    response_status INT NULL,
+    last_error TEXT NULL,
+    next_attempt_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT webhook_deliveries_id_pk PRIMARY KEY(id),
+    CONSTRAINT webhook_deliveries_webhook_id_fk FOREIGN KEY(webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE,
+    INDEX webhook_deliveries_status_idx (status, next_attempt_at)
+)
+ENGINE = INNODB
+DEFAULT CHARSET = UTF8;
@@ -0,0 +1,11 @@
+-- activity events to dispatch to the webhooks, queued in the transaction recording them and removed once dispatched
+CREATE TABLE IF NOT EXISTS webhook_outbox (
+    event_id BIGINT NOT NULL,
+    event_type VARCHAR(20) NOT NULL,
+    owner_id BIGINT NOT NULL,
+    data TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT webhook_outbox_event_id_pk PRIMARY KEY(event_id)
+)
+ENGINE = INNODB
+DEFAULT CHARSET = UTF8;
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS activities (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    category VARCHAR(50) DEFAULT 'undefined',
+    description TEXT NOT NULL,
+    status CHAR(1) DEFAULT '1',
+    started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP NULL
+);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS activity_intervals (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    activity_id INTEGER NOT NULL REFERENCES activities(id) ON DELETE CASCADE,
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP NULL
+);
+
+CREATE INDEX activity_intervals_activity_id_idx ON activity_intervals(activity_id);
+
+INSERT INTO activity_intervals (activity_id, started_at, finished_at)
+SELECT id, started_at, finished_at FROM activities;
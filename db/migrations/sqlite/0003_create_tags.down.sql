@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS activity_tags;
+DROP TABLE IF EXISTS tags;
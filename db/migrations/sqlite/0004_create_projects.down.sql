@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS activities_project_id_idx;
+
+ALTER TABLE activities DROP COLUMN hourly_rate;
+
+ALTER TABLE activities DROP COLUMN project_id;
+
+DROP TABLE IF EXISTS projects;
+
+DROP TABLE IF EXISTS clients;
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS clients (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    hourly_rate INTEGER NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS projects (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    client_id INTEGER NOT NULL REFERENCES clients(id),
+    name VARCHAR(100) NOT NULL,
+    hourly_rate INTEGER NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(client_id, name)
+);
+
+-- no foreign key on activities.project_id, sqlite can't drop columns used by foreign keys on migrate down
+ALTER TABLE activities ADD COLUMN project_id INTEGER NULL;
+
+ALTER TABLE activities ADD COLUMN hourly_rate INTEGER NULL;
+
+CREATE INDEX activities_project_id_idx ON activities(project_id);
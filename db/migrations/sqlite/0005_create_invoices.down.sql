@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS activities_invoice_id_idx;
+
+ALTER TABLE activities DROP COLUMN invoice_id;
+
+DROP TABLE IF EXISTS invoice_items;
+
+DROP TABLE IF EXISTS invoices;
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS invoices (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    client VARCHAR(100) NOT NULL DEFAULT '',
+    period_from TIMESTAMP NOT NULL,
+    period_to TIMESTAMP NOT NULL,
+    hourly_rate INTEGER NOT NULL,
+    rounding_minutes INTEGER NOT NULL DEFAULT 0,
+    total INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS invoice_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    invoice_id INTEGER NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    category VARCHAR(50) NOT NULL,
+    activities INTEGER NOT NULL,
+    duration_seconds INTEGER NOT NULL,
+    amount INTEGER NOT NULL
+);
+
+-- no foreign key on activities.invoice_id, sqlite can't drop columns used by foreign keys on migrate down
+ALTER TABLE activities ADD COLUMN invoice_id INTEGER NULL;
+
+CREATE INDEX activities_invoice_id_idx ON activities(invoice_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS activities_owner_id_status_idx;
+
+ALTER TABLE activities DROP COLUMN owner_id;
+
+DROP TABLE IF EXISTS users;
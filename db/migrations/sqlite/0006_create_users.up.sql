@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- the default user owns the activities recorded before users existed
+INSERT INTO users (id, name) VALUES (1, 'default');
+
+-- no foreign key on activities.owner_id, sqlite can't add a column referencing another table with a non null default
+ALTER TABLE activities ADD COLUMN owner_id INTEGER NOT NULL DEFAULT 1;
+
+CREATE INDEX activities_owner_id_status_idx ON activities(owner_id, status);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS team_members_user_id_idx;
+
+DROP TABLE IF EXISTS team_members;
+
+DROP TABLE IF EXISTS teams;
+
+ALTER TABLE users DROP COLUMN role;
@@ -0,0 +1,18 @@
+ALTER TABLE users ADD COLUMN role VARCHAR(10) NOT NULL DEFAULT 'member';
+
+-- the default user keeps managing everything, like before roles existed
+UPDATE users SET role = 'admin' WHERE id = 1;
+
+CREATE TABLE IF NOT EXISTS teams (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS team_members (
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    PRIMARY KEY (team_id, user_id)
+);
+
+CREATE INDEX team_members_user_id_idx ON team_members(user_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS activity_events_activity_id_idx;
+
+DROP TABLE IF EXISTS activity_events;
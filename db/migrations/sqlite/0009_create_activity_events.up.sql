@@ -0,0 +1,13 @@
+-- events are never updated nor deleted, the history of an activity outlives it
+CREATE TABLE IF NOT EXISTS activity_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    activity_id INTEGER NOT NULL,
+    owner_id INTEGER NOT NULL,
+    actor_id INTEGER NOT NULL,
+    action VARCHAR(30) NOT NULL,
+    before_value TEXT NULL,
+    after_value TEXT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX activity_events_activity_id_idx ON activity_events(activity_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS activities_deleted_at_idx;
+
+DELETE FROM activities WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE activities DROP COLUMN deleted_at;
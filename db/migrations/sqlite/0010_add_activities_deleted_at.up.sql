@@ -0,0 +1,4 @@
+-- deleted activities stay in the trash until purged
+ALTER TABLE activities ADD COLUMN deleted_at TIMESTAMP NULL;
+
+CREATE INDEX activities_deleted_at_idx ON activities(deleted_at);
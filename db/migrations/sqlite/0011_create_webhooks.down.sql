@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS webhook_deliveries_status_idx;
+
+DROP INDEX IF EXISTS webhook_deliveries_webhook_id_idx;
+
+DROP TABLE IF EXISTS webhook_deliveries;
+
+DROP TABLE IF EXISTS webhooks;
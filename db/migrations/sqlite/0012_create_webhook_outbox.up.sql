@@ -0,0 +1,8 @@
+-- activity events to dispatch to the webhooks, queued in the transaction recording them and removed once dispatched
+CREATE TABLE IF NOT EXISTS webhook_outbox (
+    event_id INTEGER PRIMARY KEY,
+    event_type VARCHAR(20) NOT NULL,
+    owner_id INTEGER NOT NULL,
+    data TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,22 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	_ "github.com/mattn/go-sqlite3"
+	"log"
+)
+
+const (
+	DefaultSQLiteFile      = "command_time_track.db"
+	sqliteStringConnection = "file:%s?_busy_timeout=5000&_foreign_keys=on&_journal_mode=WAL&_txlock=immediate"
+)
+
+func NewSQLite(file string) *sql.DB {
+	conn, err := sql.Open("sqlite3", fmt.Sprintf(sqliteStringConnection, file))
+	if err != nil {
+		log.Panicln("unable to open sqlite connection:", err)
+	}
+
+	return conn
+}
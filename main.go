@@ -1,7 +1,19 @@
 package main
 
-import "github.com/ungame/command-time-track/app"
+import (
+	"flag"
+	"github.com/ungame/command-time-track/app"
+	_ "time/tzdata"
+)
 
 func main() {
+	if flag.Arg(0) == "migrate" {
+		app.Migrate(flag.Args()[1:])
+		return
+	}
+	if flag.Arg(0) == "token" {
+		app.Token(flag.Args()[1:])
+		return
+	}
 	app.Run()
 }